@@ -0,0 +1,71 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package apisrv
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	backend "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/playerq"
+	log "github.com/sirupsen/logrus"
+	"go.opencensus.io/tag"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetActivePlayerCount is this service's implementation of the
+// GetActivePlayerCount gRPC method defined in ../proto/backend.proto
+func (s *backendAPI) GetActivePlayerCount(ctx context.Context, req *backend.ActivePlayerCountRequest) (*backend.ActivePlayerCount, error) {
+	// Create context for tagging OpenCensus metrics.
+	funcName := "GetActivePlayerCount"
+	fnCtx, _ := tag.New(ctx, tag.Insert(KeyMethod, funcName))
+
+	key := playerq.ActivePlayersKey(s.cfg)
+	if key == "" {
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return &backend.ActivePlayerCount{}, status.Error(codes.FailedPrecondition, "active player counting is disabled, set activePlayers.enabled")
+	}
+
+	redisConn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		beLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage error")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return &backend.ActivePlayerCount{}, err
+	}
+	defer redisConn.Close()
+
+	count, err := playerq.CountActive(redisConn, key)
+	if err != nil {
+		beLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+			"func":      funcName,
+		}).Error("State storage error")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return &backend.ActivePlayerCount{}, err
+	}
+
+	metrics.Record(fnCtx, BeGrpcRequests.M(1))
+	return &backend.ActivePlayerCount{Count: count}, nil
+}
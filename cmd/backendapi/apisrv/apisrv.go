@@ -23,20 +23,28 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"net"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/GoogleCloudPlatform/open-match/internal/grpcutil"
 	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	"github.com/GoogleCloudPlatform/open-match/internal/mmfallow"
 	backend "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/GoogleCloudPlatform/open-match/internal/playerid"
 	redisHelpers "github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/assignment"
 	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/ignorelist"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/playerq"
 	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/redispb"
 	"github.com/gogo/protobuf/jsonpb"
 	"github.com/gogo/protobuf/proto"
 	log "github.com/sirupsen/logrus"
 	"go.opencensus.io/plugin/ocgrpc"
-	"go.opencensus.io/stats"
 	"go.opencensus.io/tag"
 
 	"github.com/tidwall/gjson"
@@ -46,6 +54,9 @@ import (
 	"github.com/spf13/viper"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // Logrus structured logging setup
@@ -58,21 +69,101 @@ var (
 	beLog = log.WithFields(beLogFields)
 )
 
+// defaultMaxConcurrentStreams is the number of concurrent gRPC streams
+// (e.g. ListMatches calls) a single client connection may open when
+// 'api.backend.maxConcurrentStreams' is not set in the config.
+const defaultMaxConcurrentStreams = 100
+
+// Page sizes for ListAssignments when the request doesn't set a limit, and the
+// most it will return in one call.  A page can hold slightly more than the
+// limit, as every assignment found by the final SCAN is returned.
+const (
+	defaultListAssignmentsLimit = 100
+	maxListAssignmentsLimit     = 1000
+)
+
+// Values for 'assignments.deindexAt', which controls when matched players
+// are deindexed: when their match is returned by CreateMatch/ListMatches, or
+// (the default) when they are assigned by CreateAssignments.
+const (
+	deindexAtMatch      = "match"
+	deindexAtAssignment = "assignment"
+)
+
+// defaultReplayMaxAge is how old, in seconds, a CreateAssignments call's
+// timestamp may be when 'assignments.maxAge' is not set in the config.
+const defaultReplayMaxAge = 60
+
+// errResultsTimeout is returned by createMatch when no MMF results appear
+// within 'interval.resultsTimeout'.
+var errResultsTimeout = errors.New("Error retrieving matchmaking results from state storage: timeout exceeded")
+
+// errNothingToCancel is returned by CancelMatch when the profile has no
+// in-flight calls.
+var errNothingToCancel = errors.New("no in-flight CreateMatch or ListMatches calls for this profile")
+
+func init() {
+	grpcutil.RegisterErrorCode("resultsTimeout", errResultsTimeout, codes.DeadlineExceeded)
+	grpcutil.RegisterErrorCode("nothingToCancel", errNothingToCancel, codes.NotFound)
+}
+
+// matchAttemptError is returned by createMatch when the MMF ran but its
+// result isn't a usable match, e.g. it set the error field because it found
+// no players.  The returned MatchObject's error field holds the reason.
+// Unlike other errors, it doesn't mean the Backend API itself failed.
+type matchAttemptError struct {
+	reason string
+}
+
+func (e *matchAttemptError) Error() string { return e.reason }
+
 // BackendAPI implements backend API Server, the server generated by compiling
 // the protobuf, by fulfilling the API Client interface.
 type BackendAPI struct {
 	grpc *grpc.Server
 	cfg  *viper.Viper
 	pool *redis.Pool
+
+	// Cancel functions for in-flight CreateMatch and ListMatches calls, keyed
+	// by profile ID and then by request key, so CancelMatch can stop them.
+	inflight   map[string]map[string]context.CancelFunc
+	inflightMu sync.Mutex
+
+	// Stops the orphaned assignment reconciler, if it was started.
+	stopReconciler context.CancelFunc
+	// Stops the assignment expiry watcher, if it was started.
+	stopExpirations context.CancelFunc
+
+	// Set to 1 by Stop, and reported by GetHealth.  Accessed atomically.
+	draining int32
+
+	// Number of open ListMatches and ListMatchesMulti streams, recorded to
+	// BeOpenStreams.  Accessed atomically.
+	listMatchesStreams      int64
+	listMatchesMultiStreams int64
 }
 type backendAPI BackendAPI
 
 // New returns an instantiated srvice
 func New(cfg *viper.Viper, pool *redis.Pool) *BackendAPI {
+	// Limit the number of streams a single connection can hold open, so one
+	// client can't monopolize MMF capacity with ListMatches calls.
+	maxStreams := uint32(defaultMaxConcurrentStreams)
+	if cfg.IsSet("api.backend.maxConcurrentStreams") && cfg.GetInt("api.backend.maxConcurrentStreams") > 0 {
+		maxStreams = uint32(cfg.GetInt("api.backend.maxConcurrentStreams"))
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.StatsHandler(&ocgrpc.ServerHandler{}),
+		grpc.MaxConcurrentStreams(maxStreams),
+	}
+	opts = append(opts, grpcutil.ServerOptions(cfg, "backend")...)
+
 	s := BackendAPI{
-		pool: pool,
-		grpc: grpc.NewServer(grpc.StatsHandler(&ocgrpc.ServerHandler{})),
-		cfg:  cfg,
+		pool:     pool,
+		grpc:     grpc.NewServer(opts...),
+		cfg:      cfg,
+		inflight: make(map[string]map[string]context.CancelFunc),
 	}
 
 	// Add a hook to the logger to auto-count log lines for metrics output thru OpenCensus
@@ -104,12 +195,60 @@ func (s *BackendAPI) Open() error {
 		beLog.Info("serving gRPC endpoints")
 	}()
 
+	// Publish assignments whose player records expire.
+	if s.cfg.GetBool("assignments.expiry.enabled") {
+		var ctx context.Context
+		ctx, s.stopExpirations = context.WithCancel(context.Background())
+		go s.watchAssignmentExpirations(ctx)
+	}
+
+	// Periodically clean up assignments that outlived their players.
+	if s.cfg.GetBool("reconciler.enabled") {
+		var ctx context.Context
+		ctx, s.stopReconciler = context.WithCancel(context.Background())
+		go s.reconcileAssignments(ctx)
+	}
+
 	return nil
 }
 
+// Stop gracefully shuts down the gRPC server and background tasks.
+func (s *BackendAPI) Stop() {
+	atomic.StoreInt32(&s.draining, 1)
+	if s.stopReconciler != nil {
+		s.stopReconciler()
+	}
+	if s.stopExpirations != nil {
+		s.stopExpirations()
+	}
+	s.grpc.Stop()
+}
+
 // CreateMatch is this service's implementation of the CreateMatch gRPC method
 // defined in ../proto/backend.proto
 func (s *backendAPI) CreateMatch(c context.Context, profile *backend.MatchObject) (*backend.MatchObject, error) {
+	s.warnDeprecatedProfile(c, profile)
+	var mo *backend.MatchObject
+	var err error
+	if profile.DryRun {
+		mo, err = s.dryRunMatch(c, profile)
+	} else if mmfs := s.raceMMFs(profile); mmfs != nil {
+		mo, err = s.raceMatch(c, profile, mmfs)
+	} else {
+		mo, err = s.createMatch(c, profile)
+	}
+	if err == nil && !profile.DryRun && s.cfg.GetString("assignments.deindexAt") == deindexAtMatch {
+		err = s.deindexMatch(c, mo)
+	}
+	if mo != nil {
+		mo.ProfileId = profile.Id
+	}
+	return mo, err
+}
+
+// createMatch runs the MMF for the profile once and returns its results,
+// without deindexing the matched players.
+func (s *backendAPI) createMatch(c context.Context, profile *backend.MatchObject) (*backend.MatchObject, error) {
 
 	// Get a cancel-able context
 	ctx, cancel := context.WithCancel(c)
@@ -123,6 +262,10 @@ func (s *backendAPI) CreateMatch(c context.Context, profile *backend.MatchObject
 	moID := strings.Replace(uuid.New().String(), "-", "", -1)
 	requestKey := moID + "." + profile.Id
 
+	// Register this call so CancelMatch can stop it.
+	s.trackInflight(profile.Id, requestKey, cancel)
+	defer s.untrackInflight(profile.Id, requestKey)
+
 	/*
 		// Debugging logs
 		beLog.Info("Pools nil? ", (profile.Pools == nil))
@@ -172,83 +315,238 @@ func (s *backendAPI) CreateMatch(c context.Context, profile *backend.MatchObject
 	}
 
 	// Add fields for all subsequent logging
-	beLog = beLog.WithFields(log.Fields{
+	cLog := beLog.WithFields(log.Fields{
 		"profileID":     profile.Id,
 		"func":          funcName,
 		"matchObjectID": moID,
 		"requestKey":    requestKey,
 	})
-	beLog.Info("gRPC call executing")
-	beLog.Info("profile is")
-	beLog.Info(profile)
+	cLog.Info("gRPC call executing")
+	cLog.Info("profile is")
+	cLog.Info(profile)
+
+	// Make sure the requested MMF is one the mmforc knows how to run.
+	if profile.Mmf != "" && !s.cfg.IsSet("mmfs."+profile.Mmf) {
+		err := fmt.Errorf("%w: profile requested unregistered MMF '%v'", grpcutil.ErrInvalidArgument, profile.Mmf)
+		cLog.WithFields(log.Fields{
+			"error": err.Error(),
+			"mmf":   profile.Mmf,
+		}).Error("Invalid match profile")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return &backend.MatchObject{}, err
+	}
+
+	// Make sure the MMF the profile selects is at an allowed address, before
+	// the mmforc is asked to invoke it.
+	protocol, target := mmfallow.Target(s.cfg, profile.Mmf, profile.Properties)
+	if err := mmfallow.Check(s.cfg, protocol, target); err != nil {
+		cLog.WithFields(log.Fields{
+			"error":  err.Error(),
+			"mmf":    profile.Mmf,
+			"target": target,
+		}).Error("Profile selected a disallowed MMF")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return &backend.MatchObject{}, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	// If configured, wait until no other Backend API replica is running an MMF
+	// for this profile. The lock is held until the results come back.  When
+	// 'mmfConcurrency.perProfile' allows more than one MMF at a time, a
+	// semaphore with that many slots is used instead of the lock.
+	if s.cfg.GetBool("profileLock.enabled") && s.mmfConcurrency() > 1 {
+		semKey := "profilesem." + profile.Id
+		token, err := redisHelpers.Acquire(ctx, s.pool, semKey, s.mmfConcurrency(), s.profileLockTTL())
+		if err != nil {
+			cLog.WithFields(log.Fields{
+				"error":     err.Error(),
+				"component": "statestorage",
+			}).Error("State storage failure to acquire match profile semaphore")
+
+			metrics.Record(fnCtx, BeGrpcErrors.M(1))
+			return &backend.MatchObject{}, err
+		}
+		defer redisHelpers.Release(s.pool, semKey, token)
+	} else if s.cfg.GetBool("profileLock.enabled") {
+		lockKey := "profilelock." + profile.Id
+		token, err := redisHelpers.Lock(ctx, s.pool, lockKey, s.profileLockTTL())
+		if err != nil {
+			cLog.WithFields(log.Fields{
+				"error":     err.Error(),
+				"component": "statestorage",
+			}).Error("State storage failure to lock match profile")
+
+			metrics.Record(fnCtx, BeGrpcErrors.M(1))
+			return &backend.MatchObject{}, err
+		}
+		defer redisHelpers.Unlock(s.pool, lockKey, token)
+	}
 
 	// Write profile to state storage
 	//_, err := redisHelpers.Create(ctx, s.pool, profile.Id, profile.Properties)
-	err := redispb.MarshalToRedis(ctx, profile, s.pool)
+	err := redispb.MarshalToRedis(ctx, profile, s.pool, s.cfg)
 	if err != nil {
-		beLog.WithFields(log.Fields{
+		cLog.WithFields(log.Fields{
 			"error":     err.Error(),
 			"component": "statestorage",
 		}).Error("State storage failure to create match profile")
 
 		// Failure! Return empty match object and the error
-		stats.Record(fnCtx, BeGrpcErrors.M(1))
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return &backend.MatchObject{}, err
+	}
+	cLog.Info("Profile written to state storage")
+
+	// Pass allowlisted request metadata on to the MMF.
+	err = s.storeMmfMetadata(ctx, requestKey)
+	if err != nil {
+		cLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage failure to store MMF metadata")
+
+		// Failure! Return empty match object and the error
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
 		return &backend.MatchObject{}, err
 	}
-	beLog.Info("Profile written to state storage")
+
+	// Forward the MMF's progress markers, if they were asked for.
+	s.forwardProgress(ctx, requestKey)
 
 	// Queue the request ID to be sent to an MMF
 	_, err = redisHelpers.Update(ctx, s.pool, s.cfg.GetString("queues.profiles.name"), requestKey)
 	if err != nil {
-		beLog.WithFields(log.Fields{
+		cLog.WithFields(log.Fields{
 			"error":     err.Error(),
 			"component": "statestorage",
 		}).Error("State storage failure to queue profile")
 
 		// Failure! Return empty match object and the error
-		stats.Record(fnCtx, BeGrpcErrors.M(1))
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
 		return &backend.MatchObject{}, err
 	}
-	beLog.Info("Profile added to processing queue")
+	cLog.Info("Profile added to processing queue")
 
 	// get and return matchobject, it will be written to the requestKey when the MMF has finished.
 	var ok bool
 	newMO := backend.MatchObject{Id: requestKey}
 	watchChan := redispb.Watcher(ctx, s.pool, newMO) // Watcher() runs the appropriate Redis commands.
-	errString := ("Error retrieving matchmaking results from state storage")
 	timeout := time.Duration(s.cfg.GetInt("interval.resultsTimeout")) * time.Second
 
 	select {
 	case <-time.After(timeout):
 		// TODO:Timeout: deal with the fallout.  There are some edge cases here.
 		// When there is a timeout, need to send a stop to the watch channel.
-		stats.Record(fnCtx, BeGrpcRequests.M(1))
-		return profile, errors.New(errString + ": timeout exceeded")
+		metrics.Record(fnCtx, BeGrpcRequests.M(1))
+		s.fillPoolStats(ctx, profile, profile)
+		return profile, errResultsTimeout
 
 	case newMO, ok = <-watchChan:
 		if !ok {
 			// ok is false if watchChan has been closed by redispb.Watcher()
 			newMO.Error = newMO.Error + "; channel closed - was the context cancelled?"
+
+			// If the request hasn't been picked up by the mmforc yet, take it
+			// out of the profile queue so an MMF isn't run for nothing.
+			if ctx.Err() != nil {
+				s.dequeueRequest(requestKey)
+			}
 		} else {
 			// 'ok' was true, so properties should contain the results from redis.
-			// Do basic error checking on the returned JSON
-			if !gjson.Valid(profile.Properties) {
+			// Do basic error checking on the returned JSON, which profiles
+			// with typed properties may leave out.
+			if (profile.Properties != "" || profile.TypedProperties == nil) && !gjson.Valid(profile.Properties) {
 				newMO.Error = "retreived properties json was malformed"
 			}
 		}
 
 		// TODO test that this is the correct condition for an empty error.
 		if newMO.Error != "" {
-			stats.Record(fnCtx, BeGrpcErrors.M(1))
-			return &newMO, errors.New(newMO.Error)
+			s.fillPoolStats(ctx, &newMO, profile)
+			metrics.Record(fnCtx, BeGrpcErrors.M(1))
+			return &newMO, &matchAttemptError{reason: newMO.Error}
 		}
 
 		// Got results; close the channel so the Watcher() function stops querying redis.
 	}
 
-	beLog.Info("Matchmaking results received, returning to backend client")
+	cLog.Info("Matchmaking results received, returning to backend client")
+
+	// Record how big this match is, in players and bytes.
+	numPlayers := 0
+	for _, roster := range newMO.Rosters {
+		numPlayers += len(roster.Players)
+	}
+	metrics.Record(fnCtx, BeMatchPlayers.M(int64(numPlayers)), BeMatchBytes.M(int64(proto.Size(&newMO))))
+	if newMO.QualityScore != 0 {
+		metrics.Record(fnCtx, BeMatchQuality.M(newMO.QualityScore))
+	}
+
+	// Guard against an MMF returning an absurdly large match, which would
+	// overload CreateAssignments and state storage.
+	if limit := s.maxPlayers(profile); limit > 0 && numPlayers > limit {
+		cLog.WithFields(log.Fields{
+			"maxPlayers": limit,
+			"numPlayers": numPlayers,
+		}).Warn("MMF returned a match with too many players")
+
+		// Dry runs never proposed their players, so there's nothing to
+		// release.
+		if s.cfg.GetString("matchValidation.maxPlayersMode") == maxPlayersTruncate {
+			dropped := truncateRosters(newMO.Rosters, limit)
+			if !profile.DryRun {
+				s.releasePlayers(dropped)
+			}
+			numPlayers = limit
+		} else {
+			playerIDs := make([]string, 0)
+			for _, roster := range newMO.Rosters {
+				playerIDs = append(playerIDs, getPlayerIdsFromRoster(roster)...)
+			}
+			if !profile.DryRun {
+				s.releasePlayers(playerIDs)
+			}
+			newMO.Error = fmt.Sprintf("match has %v players, the most allowed is %v", numPlayers, limit)
+			metrics.Record(fnCtx, BeGrpcErrors.M(1))
+			return &newMO, &matchAttemptError{reason: newMO.Error}
+		}
+	}
+
+	// Matches the MMF didn't prioritize inherit the profile's priority.
+	if newMO.Priority == 0 {
+		newMO.Priority = profile.Priority
+	}
+
+	// Make sure the MMF filled the match to the size the profile asked for.
+	if newMO.RequestedPlayers == 0 {
+		newMO.RequestedPlayers = profile.RequestedPlayers
+	}
+	if newMO.RequestedPlayers > 0 && int64(numPlayers) != newMO.RequestedPlayers {
+		cLog.WithFields(log.Fields{
+			"requestedPlayers": newMO.RequestedPlayers,
+			"numPlayers":       numPlayers,
+		}).Warn("MMF returned a match with the wrong number of players")
+
+		if s.cfg.GetBool("matchValidation.strictPlayerCount") {
+			newMO.Error = fmt.Sprintf("match has %v players, profile requested %v", numPlayers, newMO.RequestedPlayers)
+			metrics.Record(fnCtx, BeGrpcErrors.M(1))
+			return &newMO, &matchAttemptError{reason: newMO.Error}
+		}
+	}
+
+	// Make sure each roster was filled to its target size.
+	if reason := checkRosterSizes(newMO.Rosters, profile.Rosters); reason != "" {
+		cLog.WithFields(log.Fields{"reason": reason}).Warn("MMF returned a roster with the wrong number of players")
+
+		if s.cfg.GetBool("matchValidation.strictPlayerCount") {
+			newMO.Error = reason
+			metrics.Record(fnCtx, BeGrpcErrors.M(1))
+			return &newMO, &matchAttemptError{reason: newMO.Error}
+		}
+	}
 
-	stats.Record(fnCtx, BeGrpcRequests.M(1))
+	metrics.Record(fnCtx, BeGrpcRequests.M(1))
 	return &newMO, err
 }
 
@@ -259,48 +557,163 @@ func (s *backendAPI) CreateMatch(c context.Context, profile *backend.MatchObject
 func (s *backendAPI) ListMatches(p *backend.MatchObject, matchStream backend.Backend_ListMatchesServer) error {
 
 	// call creatematch in infinite loop as long as the stream is open
-	ctx := matchStream.Context() // https://talks.golang.org/2015/gotham-grpc.slide#30
+	// https://talks.golang.org/2015/gotham-grpc.slide#30
+	ctx, cancel := context.WithCancel(matchStream.Context())
+	defer cancel()
 
 	// Create context for tagging OpenCensus metrics.
 	funcName := "ListMatches"
 	fnCtx, _ := tag.New(ctx, tag.Insert(KeyMethod, funcName))
 
-	beLog = beLog.WithFields(log.Fields{"func": funcName})
-	beLog.WithFields(log.Fields{
+	// Register this stream so CancelMatch can stop it. The key is prefixed so
+	// it can't collide with the request keys registered by CreateMatch.
+	streamKey := funcName + "." + strings.Replace(uuid.New().String(), "-", "", -1)
+	s.trackInflight(p.Id, streamKey, cancel)
+	defer s.untrackInflight(p.Id, streamKey)
+
+	defer openStream(fnCtx, &s.listMatchesStreams)()
+
+	lmLog := beLog.WithFields(log.Fields{"func": funcName})
+	lmLog.WithFields(log.Fields{
 		"profileID": p.Id,
 	}).Info("gRPC call executing. Calling CreateMatch. Looping until cancelled.")
 
+	return s.listMatches(ctx, fnCtx, funcName, p, matchStream.Send)
+}
+
+// openStream records one more open stream of the method fnCtx is tagged with
+// to BeOpenStreams, counting it in open, and returns the func to call when
+// the stream closes.
+func openStream(fnCtx context.Context, open *int64) (closeStream func()) {
+	metrics.Record(fnCtx, BeOpenStreams.M(atomic.AddInt64(open, 1)))
+	return func() {
+		metrics.Record(fnCtx, BeOpenStreams.M(atomic.AddInt64(open, -1)))
+	}
+}
+
+// listMatches runs the MMF for the profile in a loop until ctx is cancelled,
+// passing every match attempt to send.  Matches are tagged with the
+// profile's ID, so streams carrying several profiles can tell them apart.
+func (s *backendAPI) listMatches(ctx context.Context, fnCtx context.Context, funcName string, p *backend.MatchObject, send func(*backend.MatchObject) error) (err error) {
+	lLog := beLog.WithFields(log.Fields{"func": funcName})
+
+	if p.DryRun {
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return status.Errorf(codes.InvalidArgument, "profile %v: dry_run is only supported by CreateMatch", p.Id)
+	}
+
+	// Optionally let the MMF loop run ahead of a slow client, up to the
+	// buffer size.  Queued matches are still sent once the loop ends.
+	if size := s.streamBufferSize(); size > 0 {
+		buffered := newBufferedSender(size, send)
+		send = buffered.Send
+		defer func() {
+			if closeErr := buffered.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+	}
+
+	sendMatch := func(mo *backend.MatchObject) error {
+		mo.ProfileId = p.Id
+		return send(mo)
+	}
+
+	// Warn once per stream, rather than on every loop.
+	s.warnDeprecatedProfile(ctx, p)
+
+	// If match selection is configured, each loop runs the MMF batchSize times
+	// and streams back only the matches picked by weightedSelect.
+	batchSize := 1
+	if s.cfg.GetInt("matchSelection.batchSize") > 1 {
+		batchSize = s.cfg.GetInt("matchSelection.batchSize")
+	}
+
+	// If MMF concurrency is configured, each loop runs at least that many
+	// MMFs, that many at a time.
+	parallel := s.mmfConcurrency()
+	attempts := batchSize
+	if parallel > attempts {
+		attempts = parallel
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			// Context cancelled, probably because the client cancelled their request, time to exit.
-			beLog.WithFields(log.Fields{
+			lLog.WithFields(log.Fields{
 				"profileID": p.Id,
 			}).Info("gRPC Context cancelled; client is probably finished receiving matches")
 
 			// TODO: need to make sure that in-flight matches don't get leaked here.
-			stats.Record(fnCtx, BeGrpcRequests.M(1))
+			metrics.Record(fnCtx, BeGrpcRequests.M(1))
 			return nil
 
 		default:
-			// Retreive results from Redis
-			requestProfile := proto.Clone(p).(*backend.MatchObject)
-			/*
-				beLog.Debug("new profile requested!")
-				beLog.Debug(requestProfile)
-				beLog.Debug(&requestProfile)
-			*/
-			mo, err := s.CreateMatch(ctx, requestProfile)
+			// Collect a batch of candidate matches. Unless match selection or
+			// MMF concurrency is configured, the batch is a single match.
+			candidates := make([]*backend.MatchObject, 0, attempts)
+			for _, result := range s.runAttempts(ctx, p, attempts, parallel) {
+				mo, err := result.mo, result.err
+
+				if err != nil && ctx.Err() != nil {
+					// The client went away or CancelMatch was called while
+					// CreateMatch was in flight; this isn't a failure.
+					lLog.WithFields(log.Fields{
+						"profileID": p.Id,
+					}).Info("gRPC Context cancelled during CreateMatch; ending stream")
+					metrics.Record(fnCtx, BeGrpcRequests.M(1))
+					return nil
+				}
+				var attemptErr *matchAttemptError
+				if errors.As(err, &attemptErr) {
+					// Only this attempt failed; report it and keep streaming.
+					lLog.WithFields(log.Fields{"error": err.Error()}).Info("MMF found no match")
+					if err := sendMatch(mo); err != nil {
+						return err
+					}
+					continue
+				}
+				if err != nil {
+					lLog.WithFields(log.Fields{"error": err.Error()}).Error("Failure calling CreateMatch")
+					metrics.Record(fnCtx, BeGrpcErrors.M(1))
+					return streamError(err)
+				}
+				candidates = append(candidates, mo)
+			}
 
-			beLog = beLog.WithFields(log.Fields{"func": funcName})
+			// MMFs that ran side by side may have picked the same players.
+			if parallel > 1 {
+				candidates = s.dropOverlapping(candidates)
+			}
 
-			if err != nil {
-				beLog.WithFields(log.Fields{"error": err.Error()}).Error("Failure calling CreateMatch")
-				stats.Record(fnCtx, BeGrpcErrors.M(1))
-				return err
+			matches := candidates
+			if batchSize > 1 {
+				matches = s.selectMatches(candidates)
+				lLog.WithFields(log.Fields{
+					"candidates": len(candidates),
+					"selected":   len(matches),
+				}).Debug("Selected matches from candidate batch")
+			}
+
+			// Stream the highest priority matches first.
+			sort.SliceStable(matches, func(i, j int) bool {
+				return matches[i].Priority > matches[j].Priority
+			})
+
+			for _, mo := range matches {
+				// Only matches actually returned to the client are deindexed.
+				if s.cfg.GetString("assignments.deindexAt") == deindexAtMatch {
+					if err := s.deindexMatch(ctx, mo); err != nil {
+						metrics.Record(fnCtx, BeGrpcErrors.M(1))
+						return streamError(err)
+					}
+				}
+				lLog.WithFields(log.Fields{"matchProperties": fmt.Sprintf("%v", mo)}).Debug("Streaming back match object")
+				if err := sendMatch(mo); err != nil {
+					return err
+				}
 			}
-			beLog.WithFields(log.Fields{"matchProperties": fmt.Sprintf("%v", mo)}).Debug("Streaming back match object")
-			matchStream.Send(mo)
 
 			// TODO: This should be tunable, but there should be SOME sleep here, to give a requestor a window
 			// to cleanly close the connection after receiving a match object when they know they don't want to
@@ -310,6 +723,42 @@ func (s *backendAPI) ListMatches(p *backend.MatchObject, matchStream backend.Bac
 	}
 }
 
+// checkRosterSizes returns a description of every roster that doesn't hold
+// its target_size in players, or "" if they all do.  A roster the MMF
+// returned without a target size takes it from the profile's roster of the
+// same name, and rosters with neither aren't checked.
+func checkRosterSizes(rosters []*backend.Roster, profileRosters []*backend.Roster) string {
+	targets := make(map[string]int64)
+	for _, r := range profileRosters {
+		targets[r.Name] = r.TargetSize
+	}
+
+	problems := make([]string, 0)
+	for _, r := range rosters {
+		target := r.TargetSize
+		if target == 0 {
+			target = targets[r.Name]
+		}
+		if target > 0 && int64(len(r.Players)) != target {
+			problems = append(problems, fmt.Sprintf("roster '%v' has %v players, target size is %v", r.Name, len(r.Players), target))
+		}
+	}
+	return strings.Join(problems, "; ")
+}
+
+// streamError converts an error that ends a ListMatches stream to a gRPC
+// status, so clients can tell the stream failed from a match attempt that
+// found nothing, which is streamed with its error field set instead.
+func streamError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if err == errResultsTimeout {
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
+
 // DeleteMatch is this service's implementation of the DeleteMatch gRPC method
 // defined in ../proto/backend.proto
 func (s *backendAPI) DeleteMatch(ctx context.Context, mo *backend.MatchObject) (*backend.Result, error) {
@@ -318,30 +767,129 @@ func (s *backendAPI) DeleteMatch(ctx context.Context, mo *backend.MatchObject) (
 	funcName := "DeleteMatch"
 	fnCtx, _ := tag.New(ctx, tag.Insert(KeyMethod, funcName))
 
-	beLog = beLog.WithFields(log.Fields{"func": funcName})
-	beLog.WithFields(log.Fields{
+	dmLog := beLog.WithFields(log.Fields{"func": funcName})
+	dmLog.WithFields(log.Fields{
 		"matchObjectID": mo.Id,
 	}).Info("gRPC call executing")
 
 	_, err := redisHelpers.Delete(ctx, s.pool, mo.Id)
 	if err != nil {
-		beLog.WithFields(log.Fields{
+		dmLog.WithFields(log.Fields{
 			"error":     err.Error(),
 			"component": "statestorage",
 		}).Error("State storage error")
 
-		stats.Record(fnCtx, BeGrpcErrors.M(1))
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
 		return &backend.Result{Success: false, Error: err.Error()}, err
 	}
 
-	beLog.WithFields(log.Fields{
+	dmLog.WithFields(log.Fields{
 		"matchObjectID": mo.Id,
 	}).Info("Match Object deleted.")
 
-	stats.Record(fnCtx, BeGrpcRequests.M(1))
+	metrics.Record(fnCtx, BeGrpcRequests.M(1))
 	return &backend.Result{Success: true, Error: ""}, err
 }
 
+// DeleteMatches is this service's implementation of the DeleteMatches gRPC
+// method defined in ../proto/backend.proto
+func (s *backendAPI) DeleteMatches(ctx context.Context, ids *backend.MatchIdList) (*backend.ResultList, error) {
+
+	// Create context for tagging OpenCensus metrics.
+	funcName := "DeleteMatches"
+	fnCtx, _ := tag.New(ctx, tag.Insert(KeyMethod, funcName))
+
+	dmLog := beLog.WithFields(log.Fields{"func": funcName})
+	dmLog.WithFields(log.Fields{
+		"numMatches": len(ids.Ids),
+	}).Info("gRPC call executing")
+
+	redisConn, err := grpcutil.GetConn(ctx, s.pool)
+	if err != nil {
+		dmLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage error")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return &backend.ResultList{}, err
+	}
+	defer redisConn.Close()
+
+	// Pipeline every DEL, so the whole batch costs one round trip.
+	for _, id := range ids.Ids {
+		redisConn.Send("DEL", id)
+	}
+	if err := redisConn.Flush(); err != nil {
+		dmLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage error")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return &backend.ResultList{}, err
+	}
+
+	list := &backend.ResultList{Results: make([]*backend.Result, len(ids.Ids))}
+	deleted := 0
+	for i, id := range ids.Ids {
+		result := &backend.Result{Id: id}
+		n, err := redis.Int(redisConn.Receive())
+		switch {
+		case err != nil:
+			result.Error = err.Error()
+		case n == 0:
+			result.Error = "match not found"
+		default:
+			result.Success, result.RemovedCount = true, int64(n)
+			deleted++
+		}
+		list.Results[i] = result
+	}
+
+	dmLog.WithFields(log.Fields{
+		"numMatches": len(ids.Ids),
+		"deleted":    deleted,
+	}).Info("Match Objects deleted.")
+
+	metrics.Record(fnCtx, BeGrpcRequests.M(1))
+	return list, nil
+}
+
+// CancelMatch is this service's implementation of the CancelMatch gRPC method
+// defined in ../proto/backend.proto
+func (s *backendAPI) CancelMatch(ctx context.Context, profile *backend.MatchObject) (*backend.Result, error) {
+
+	// Create context for tagging OpenCensus metrics.
+	funcName := "CancelMatch"
+	fnCtx, _ := tag.New(ctx, tag.Insert(KeyMethod, funcName))
+
+	cmLog := beLog.WithFields(log.Fields{"func": funcName})
+	cmLog.WithFields(log.Fields{
+		"profileID": profile.Id,
+	}).Info("gRPC call executing")
+
+	count := s.cancelInflight(profile.Id)
+	if count == 0 {
+		err := errNothingToCancel
+		cmLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"profileID": profile.Id,
+		}).Warn("Nothing to cancel")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return &backend.Result{Success: false, Error: err.Error()}, err
+	}
+
+	cmLog.WithFields(log.Fields{
+		"profileID": profile.Id,
+		"count":     count,
+	}).Info("In-flight matchmaking requests cancelled")
+
+	metrics.Record(fnCtx, BeGrpcRequests.M(1))
+	return &backend.Result{Success: true, Error: ""}, nil
+}
+
 // CreateAssignments is this service's implementation of the CreateAssignments gRPC method
 // defined in ../proto/backend.proto
 func (s *backendAPI) CreateAssignments(ctx context.Context, a *backend.Assignments) (*backend.Result, error) {
@@ -350,107 +898,560 @@ func (s *backendAPI) CreateAssignments(ctx context.Context, a *backend.Assignmen
 	for _, roster := range a.Rosters {
 		assignments = append(assignments, getPlayerIdsFromRoster(roster)...)
 	}
+	s.normalizePlayerIDs(assignments)
 
 	// Create context for tagging OpenCensus metrics.
 	funcName := "CreateAssignments"
 	fnCtx, _ := tag.New(ctx, tag.Insert(KeyMethod, funcName))
 
-	beLog = beLog.WithFields(log.Fields{"func": funcName})
-	beLog.WithFields(log.Fields{
+	caLog := beLog.WithFields(log.Fields{"func": funcName})
+	caLog.WithFields(log.Fields{
 		"numAssignments": len(assignments),
 	}).Info("gRPC call executing")
 
+	// Encode the connection info for storage with the configured codec.
+	codec, err := assignment.NewCodec(s.cfg)
+	if err != nil {
+		caLog.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Assignment codec error")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		metrics.Record(fnCtx, BeAssignmentFailures.M(int64(len(assignments))))
+		return &backend.Result{Success: false, Error: err.Error()}, err
+	}
+	fields, err := codec.Encode(a.ConnectionInfo)
+	if err != nil {
+		caLog.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Connection string encoding error")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		metrics.Record(fnCtx, BeAssignmentFailures.M(int64(len(assignments))))
+		return &backend.Result{Success: false, Error: err.Error()}, err
+	}
+
 	// TODO: relocate this redis functionality to a module
-	redisConn := s.pool.Get()
+	redisConn, err := grpcutil.GetConn(ctx, s.pool)
+	if err != nil {
+		caLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage connection error")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		metrics.Record(fnCtx, BeAssignmentFailures.M(int64(len(assignments))))
+		return &backend.Result{Success: false, Error: err.Error()}, err
+	}
 	defer redisConn.Close()
 
+	// Reject replayed assignment calls.
+	if s.cfg.GetBool("assignments.replayProtection") {
+		err = checkReplay(redisConn, a, s.cfg.GetInt64("assignments.maxAge"))
+		if err != nil {
+			caLog.WithFields(log.Fields{
+				"error": err.Error(),
+				"nonce": a.Nonce,
+			}).Error("Assignment replay check failed")
+
+			metrics.Record(fnCtx, BeGrpcErrors.M(1))
+			metrics.Record(fnCtx, BeAssignmentFailures.M(int64(len(assignments))))
+			return &backend.Result{Success: false, Error: err.Error()}, err
+		}
+	}
+
+	// Optionally route players to a connection string based on their
+	// properties, instead of the one in the call.
+	routed, err := s.routeAssignments(redisConn, codec, a.ConnectionInfo, assignments)
+	if err != nil {
+		caLog.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Assignment routing error")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		metrics.Record(fnCtx, BeAssignmentFailures.M(int64(len(assignments))))
+		return &backend.Result{Success: false, Error: err.Error()}, err
+	}
+
+	playerFields := func(playerID string) map[string]string {
+		if r, ok := routed[playerID]; ok {
+			return r
+		}
+		return fields
+	}
+	assignedAt := time.Now().UnixNano() / int64(time.Millisecond)
+
+	// Optionally write the assignments only to players that don't have one
+	// yet, before the transaction below, which then skips the players that
+	// were already assigned.
+	var conflicts []string
+	if a.OnlyUnassigned {
+		var assigned []string
+		assigned, conflicts, err = assignment.SetIfUnassigned(redisConn, assignments, playerFields, assignedAt)
+		if err != nil {
+			caLog.WithFields(log.Fields{
+				"error":     err.Error(),
+				"component": "statestorage",
+			}).Error("State storage error")
+
+			metrics.Record(fnCtx, BeGrpcErrors.M(1))
+			metrics.Record(fnCtx, BeAssignmentFailures.M(int64(len(assignments))))
+			return &backend.Result{Success: false, Error: err.Error()}, err
+		}
+		if len(conflicts) > 0 {
+			caLog.WithFields(log.Fields{
+				"numConflicts": len(conflicts),
+			}).Warn("Players already assigned, skipping")
+		}
+		assignments = assigned
+	}
+
 	// Create player assignments in a transaction.
+	expiry := s.cfg.GetBool("assignments.expiry.enabled")
 	redisConn.Send("MULTI")
 	for _, playerID := range assignments {
-		beLog.WithFields(log.Fields{
-			"query":                                "HSET",
-			"playerID":                             playerID,
-			s.cfg.GetString("jsonkeys.connstring"): a.ConnectionInfo.ConnectionString,
-		}).Debug("state storage operation")
-		redisConn.Send("HSET", playerID, s.cfg.GetString("jsonkeys.connstring"), a.ConnectionInfo.ConnectionString)
+		if !a.OnlyUnassigned {
+			caLog.WithFields(log.Fields{
+				"query":    "HMSET",
+				"playerID": playerID,
+				"fields":   playerFields(playerID),
+			}).Debug("state storage operation")
+			redisConn.Send("HMSET", redis.Args{}.Add(playerID).AddFlat(playerFields(playerID)).Add(assignment.AssignedAtField, assignedAt)...)
+		}
+		// Players that stay in matchmaking keep their record's expiry.
+		if expiry && !a.SkipDeindex {
+			sendTrackAssignment(redisConn, s.cfg, playerID, playerFields(playerID))
+		}
+	}
+	if !a.SkipDeindex {
+		// Unless they were already deindexed when their match was created,
+		// move these players from the proposed list to the deindexed list.
+		if s.cfg.GetString("assignments.deindexAt") != deindexAtMatch {
+			ignorelist.SendRemove(redisConn, "proposed", assignments)
+			ignorelist.SendAdd(redisConn, "deindexed", assignments)
+		}
+		// Assigned players have left matchmaking.
+		playerq.SendRemoveActive(redisConn, playerq.ActivePlayersKey(s.cfg), assignments)
 	}
-	// Remove these players from the proposed list.
-	ignorelist.SendRemove(redisConn, "proposed", assignments)
-	// Add these players from the deindexed list.
-	ignorelist.SendAdd(redisConn, "deindexed", assignments)
 
 	// Send the multi-command transaction to Redis.
-	_, err := redisConn.Do("EXEC")
+	_, err = redisConn.Do("EXEC")
 
 	// Issue encountered
 	if err != nil {
-		beLog.WithFields(log.Fields{
+		caLog.WithFields(log.Fields{
 			"error":     err.Error(),
 			"component": "statestorage",
 		}).Error("State storage error")
 
-		stats.Record(fnCtx, BeGrpcErrors.M(1))
-		stats.Record(fnCtx, BeAssignmentFailures.M(int64(len(assignments))))
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		metrics.Record(fnCtx, BeAssignmentFailures.M(int64(len(assignments))))
 		return &backend.Result{Success: false, Error: err.Error()}, err
 	}
 
 	// Success!
-	beLog.WithFields(log.Fields{
+	caLog.WithFields(log.Fields{
 		"numAssignments": len(assignments),
 	}).Info("Assignments complete")
 
-	stats.Record(fnCtx, BeGrpcRequests.M(1))
-	stats.Record(fnCtx, BeAssignments.M(int64(len(assignments))))
+	metrics.Record(fnCtx, BeGrpcRequests.M(1))
+	metrics.Record(fnCtx, BeAssignments.M(int64(len(assignments))))
+	if len(conflicts) > 0 {
+		metrics.Record(fnCtx, BeAssignmentFailures.M(int64(len(conflicts))))
+		return &backend.Result{
+			Success:   false,
+			Error:     fmt.Sprintf("%d players were already assigned", len(conflicts)),
+			Conflicts: conflicts,
+		}, nil
+	}
 	return &backend.Result{Success: true, Error: ""}, err
 }
 
+// routeAssignments applies the rules at 'assignments.routing.rules' to the
+// stored properties of each player, and returns the encoded fields to store
+// for every player a rule matched, keyed by player ID.  Routed players keep
+// the metadata in ci.  Players no rule matches are left out, and get the
+// connection string from the CreateAssignments call.
+func (s *backendAPI) routeAssignments(redisConn redis.Conn, codec assignment.AssignmentCodec, ci *backend.ConnectionInfo, playerIDs []string) (map[string]map[string]string, error) {
+	routed := make(map[string]map[string]string)
+	rules, err := assignment.RoutingRules(s.cfg)
+	if err != nil || len(rules) == 0 {
+		return routed, err
+	}
+
+	for _, playerID := range playerIDs {
+		redisConn.Send("HGETALL", playerID)
+	}
+	if err := redisConn.Flush(); err != nil {
+		return nil, err
+	}
+
+	// Many players share a connection string, so only encode each once.
+	encoded := make(map[string]map[string]string)
+	for _, playerID := range playerIDs {
+		record, err := redis.StringMap(redisConn.Receive())
+		if err != nil {
+			return nil, err
+		}
+		properties, _ := playerq.PropertiesJSON(record)
+		connstring, ok := assignment.Route(rules, properties)
+		if !ok {
+			continue
+		}
+		fields, ok := encoded[connstring]
+		if !ok {
+			fields, err = codec.Encode(&backend.ConnectionInfo{ConnectionString: connstring, Metadata: ci.GetMetadata()})
+			if err != nil {
+				return nil, err
+			}
+			encoded[connstring] = fields
+		}
+		routed[playerID] = fields
+	}
+	return routed, nil
+}
+
 // DeleteAssignments is this service's implementation of the DeleteAssignments gRPC method
 // defined in ../proto/backend.proto
 func (s *backendAPI) DeleteAssignments(ctx context.Context, r *backend.Roster) (*backend.Result, error) {
 	// TODO: make playerIDs a repeated protobuf message field and iterate over it
 	//assignments := strings.Split(a.PlayerIds, " ")
 	assignments := getPlayerIdsFromRoster(r)
+	s.normalizePlayerIDs(assignments)
 
 	// Create context for tagging OpenCensus metrics.
 	funcName := "DeleteAssignments"
 	fnCtx, _ := tag.New(ctx, tag.Insert(KeyMethod, funcName))
 
-	beLog = beLog.WithFields(log.Fields{"func": funcName})
-	beLog.WithFields(log.Fields{
+	daLog := beLog.WithFields(log.Fields{"func": funcName})
+	daLog.WithFields(log.Fields{
 		"numAssignments": len(assignments),
 	}).Info("gRPC call executing")
 
 	// TODO: relocate this redis functionality to a module
-	redisConn := s.pool.Get()
+	redisConn, err := grpcutil.GetConn(ctx, s.pool)
+	if err != nil {
+		daLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage connection error")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		metrics.Record(fnCtx, BeAssignmentDeletionFailures.M(int64(len(assignments))))
+		return &backend.Result{Success: false, Error: err.Error()}, err
+	}
 	defer redisConn.Close()
 
 	// Remove player assignments in a transaction
 	redisConn.Send("MULTI")
 	// TODO: make playerIDs a repeated protobuf message field and iterate over it
 	for _, playerID := range assignments {
-		beLog.WithFields(log.Fields{"query": "DEL", "key": playerID}).Debug("state storage operation")
+		daLog.WithFields(log.Fields{"query": "DEL", "key": playerID}).Debug("state storage operation")
 		redisConn.Send("DEL", playerID)
 	}
-	_, err := redisConn.Do("EXEC")
+	// Deleted assignments didn't expire, so stop tracking them.
+	if s.cfg.GetBool("assignments.expiry.enabled") && len(assignments) > 0 {
+		redisConn.Send("HDEL", redis.Args{}.Add(s.cfg.GetString("assignments.expiry.index")).AddFlat(assignments)...)
+	}
+	_, err = redisConn.Do("EXEC")
 
 	// Issue encountered
 	if err != nil {
-		beLog.WithFields(log.Fields{
+		daLog.WithFields(log.Fields{
 			"error":     err.Error(),
 			"component": "statestorage",
 		}).Error("State storage error")
 
-		stats.Record(fnCtx, BeGrpcErrors.M(1))
-		stats.Record(fnCtx, BeAssignmentDeletionFailures.M(int64(len(assignments))))
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		metrics.Record(fnCtx, BeAssignmentDeletionFailures.M(int64(len(assignments))))
 		return &backend.Result{Success: false, Error: err.Error()}, err
 	}
 
 	// Success!
-	stats.Record(fnCtx, BeGrpcRequests.M(1))
-	stats.Record(fnCtx, BeAssignmentDeletions.M(int64(len(assignments))))
+	metrics.Record(fnCtx, BeGrpcRequests.M(1))
+	metrics.Record(fnCtx, BeAssignmentDeletions.M(int64(len(assignments))))
 	return &backend.Result{Success: true, Error: ""}, err
 }
 
+// ListAssignments is this service's implementation of the ListAssignments gRPC method
+// defined in ../proto/backend.proto
+func (s *backendAPI) ListAssignments(ctx context.Context, req *backend.ListAssignmentsRequest) (*backend.AssignmentList, error) {
+	// Create context for tagging OpenCensus metrics.
+	funcName := "ListAssignments"
+	fnCtx, _ := tag.New(ctx, tag.Insert(KeyMethod, funcName))
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultListAssignmentsLimit
+	}
+	if limit > maxListAssignmentsLimit {
+		limit = maxListAssignmentsLimit
+	}
+
+	redisConn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		beLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage error")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return &backend.AssignmentList{}, err
+	}
+	defer redisConn.Close()
+
+	codec, err := assignment.NewCodec(s.cfg)
+	if err != nil {
+		beLog.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Assignment codec error")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return &backend.AssignmentList{}, err
+	}
+
+	// SCAN the keyspace, keeping any key that is a player with an assignment,
+	// until a page has been filled or the whole keyspace has been scanned.
+	list := &backend.AssignmentList{}
+	scanner := redisHelpers.NewScanner(redisConn, req.Cursor, "", limit)
+	for len(list.Assignments) < limit && scanner.Next(ctx) {
+		keys := scanner.Keys()
+		for _, key := range keys {
+			redisConn.Send("HGETALL", key)
+		}
+		if err := redisConn.Flush(); err != nil {
+			beLog.WithFields(log.Fields{
+				"error":     err.Error(),
+				"component": "statestorage",
+			}).Error("State storage error")
+
+			metrics.Record(fnCtx, BeGrpcErrors.M(1))
+			return &backend.AssignmentList{}, grpcutil.StatusError(s.cfg, err)
+		}
+		for _, key := range keys {
+			// Keys that aren't hashes (e.g. indices) reply with an error.
+			fields, err := redis.StringMap(redisConn.Receive())
+			if err != nil {
+				continue
+			}
+			ci, err := codec.Decode(fields)
+			if err == assignment.ErrNoAssignment {
+				continue
+			}
+			if err != nil {
+				beLog.WithFields(log.Fields{
+					"error":    err.Error(),
+					"playerid": key,
+				}).Warn("Connection string decoding error")
+				continue
+			}
+			list.Assignments = append(list.Assignments, &backend.PlayerAssignment{
+				PlayerId:       key,
+				ConnectionInfo: ci,
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		beLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage error")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return &backend.AssignmentList{}, err
+	}
+	list.NextCursor = scanner.Cursor()
+
+	metrics.Record(fnCtx, BeGrpcRequests.M(1))
+	return list, nil
+}
+
+// weightedSelect picks count matches from the candidates at random, without
+// replacement, weighting each candidate by its quality_score or, if the MMF
+// didn't set one, by the number found at weightKey in its properties JSON.
+// Candidates with a missing or non-positive weight are
+// only picked once every positively weighted candidate has been.
+func weightedSelect(candidates []*backend.MatchObject, weightKey string, count int) []*backend.MatchObject {
+	if count <= 0 {
+		count = 1
+	}
+	if count >= len(candidates) {
+		return candidates
+	}
+
+	remaining := make([]*backend.MatchObject, len(candidates))
+	copy(remaining, candidates)
+	selected := make([]*backend.MatchObject, 0, count)
+	for len(selected) < count {
+		weights := make([]float64, len(remaining))
+		total := 0.0
+		for i, mo := range remaining {
+			w := mo.QualityScore
+			if w == 0 {
+				w = gjson.Get(mo.Properties, weightKey).Float()
+			}
+			if w > 0 {
+				weights[i] = w
+				total += w
+			}
+		}
+
+		// Default to a uniform pick if nothing left has a weight.
+		pick := rand.Intn(len(remaining))
+		if total > 0 {
+			r := rand.Float64() * total
+			for i, w := range weights {
+				if w <= 0 {
+					continue
+				}
+				pick = i
+				if r < w {
+					break
+				}
+				r -= w
+			}
+		}
+
+		selected = append(selected, remaining[pick])
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+	}
+	return selected
+}
+
+// selectMatches picks 'matchSelection.count' of the candidates with
+// weightedSelect, weighted by 'jsonkeys.matchWeight'.  The candidates that
+// aren't picked are released, so their players can be matched again.
+func (s *backendAPI) selectMatches(candidates []*backend.MatchObject) []*backend.MatchObject {
+	selected := weightedSelect(candidates, s.cfg.GetString("jsonkeys.matchWeight"), s.cfg.GetInt("matchSelection.count"))
+	picked := make(map[*backend.MatchObject]bool, len(selected))
+	for _, mo := range selected {
+		picked[mo] = true
+	}
+	for _, mo := range candidates {
+		if !picked[mo] {
+			s.releaseMatch(mo)
+		}
+	}
+	return selected
+}
+
+// checkReplay returns an error if the assignments' timestamp is more than
+// maxAge seconds away from now, or if its nonce has already been used.  Used
+// nonces are remembered in state storage for twice maxAge, after which the
+// timestamp check alone rejects any replay.
+func checkReplay(redisConn redis.Conn, a *backend.Assignments, maxAge int64) error {
+	if a.Nonce == "" || a.Timestamp == 0 {
+		return fmt.Errorf("%w: assignments require a nonce and timestamp when replay protection is enabled", grpcutil.ErrInvalidArgument)
+	}
+	if maxAge <= 0 {
+		maxAge = defaultReplayMaxAge
+	}
+
+	age := time.Now().Unix() - a.Timestamp
+	if age > maxAge || age < -maxAge {
+		return fmt.Errorf("%w: assignments timestamp is %v seconds from now, the maximum is %v", grpcutil.ErrInvalidArgument, age, maxAge)
+	}
+
+	// SET NX only succeeds the first time this nonce is seen.
+	reply, err := redisConn.Do("SET", "nonce."+a.Nonce, a.Timestamp, "NX", "EX", 2*maxAge)
+	if err != nil {
+		return err
+	}
+	if reply == nil {
+		return fmt.Errorf("%w: assignments nonce has already been used", grpcutil.ErrInvalidArgument)
+	}
+	return nil
+}
+
+// warnDeprecatedProfile attaches a deprecation notice to the call's trailing
+// metadata if the profile relies on pools or rosters in its JSON properties,
+// rather than populating the protobuf fields.
+func (s *backendAPI) warnDeprecatedProfile(ctx context.Context, profile *backend.MatchObject) {
+	if profile.Pools == nil && s.cfg.IsSet("jsonkeys.pools") &&
+		gjson.Get(profile.Properties, s.cfg.GetString("jsonkeys.pools")).Exists() {
+		grpcutil.Deprecated(ctx, s.cfg.GetString("jsonkeys.pools"), "populate the pools field instead")
+	}
+	if profile.Rosters == nil && s.cfg.IsSet("jsonkeys.rosters") &&
+		gjson.Get(profile.Properties, s.cfg.GetString("jsonkeys.rosters")).Exists() {
+		grpcutil.Deprecated(ctx, s.cfg.GetString("jsonkeys.rosters"), "populate the rosters field instead")
+	}
+}
+
+// storeMmfMetadata copies the incoming gRPC metadata headers listed in
+// 'mmfMetadata.allowlist' (e.g. a tenant or trace id) to a hash at
+// 'metadata.<requestKey>', where the mmforc reads them and passes them on to
+// the MMF.  The hash expires along with the request.
+func (s *backendAPI) storeMmfMetadata(ctx context.Context, requestKey string) error {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+	args := redis.Args{"metadata." + requestKey}
+	for _, header := range s.cfg.GetStringSlice("mmfMetadata.allowlist") {
+		if values := md.Get(header); len(values) > 0 {
+			args = args.Add(strings.ToLower(header), strings.Join(values, ","))
+		}
+	}
+	if len(args) == 1 {
+		return nil
+	}
+
+	redisConn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer redisConn.Close()
+
+	redisConn.Send("MULTI")
+	redisConn.Send("HMSET", args...)
+	redisConn.Send("EXPIRE", args[0], 2*s.cfg.GetInt("interval.resultsTimeout"))
+	_, err = redisConn.Do("EXEC")
+	return err
+}
+
+// profileLockTTL returns how long a profile lock is held before it expires,
+// from 'profileLock.ttl'.  It defaults to 'interval.resultsTimeout', which is
+// the longest CreateMatch waits for MMF results.
+func (s *backendAPI) profileLockTTL() time.Duration {
+	ttl := s.cfg.GetInt("profileLock.ttl")
+	if ttl <= 0 {
+		ttl = s.cfg.GetInt("interval.resultsTimeout")
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+// deindexMatch moves every player in the match's rosters from the proposed
+// ignorelist to the deindexed ignorelist, so no other MMF can match them
+// while the match waits for an assignment.
+func (s *backendAPI) deindexMatch(ctx context.Context, mo *backend.MatchObject) error {
+	playerIDs := make([]string, 0)
+	for _, roster := range mo.Rosters {
+		playerIDs = append(playerIDs, getPlayerIdsFromRoster(roster)...)
+	}
+	if len(playerIDs) == 0 {
+		return nil
+	}
+
+	redisConn, err := grpcutil.GetConn(ctx, s.pool)
+	if err != nil {
+		return err
+	}
+	defer redisConn.Close()
+
+	redisConn.Send("MULTI")
+	ignorelist.SendRemove(redisConn, "proposed", playerIDs)
+	ignorelist.SendAdd(redisConn, "deindexed", playerIDs)
+	_, err = redisConn.Do("EXEC")
+	if err != nil {
+		beLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+			"matchID":   mo.Id,
+		}).Error("State storage error deindexing matched players")
+	}
+	return err
+}
+
 func getPlayerIdsFromRoster(r *backend.Roster) []string {
 	playerIDs := make([]string, 0)
 	for _, p := range r.Players {
@@ -459,3 +1460,63 @@ func getPlayerIdsFromRoster(r *backend.Roster) []string {
 	return playerIDs
 
 }
+
+// normalizePlayerIDs normalizes player IDs sent by the client in place, so
+// they match the keys the Frontend API stored the players under.
+func (s *backendAPI) normalizePlayerIDs(playerIDs []string) {
+	for i, id := range playerIDs {
+		playerIDs[i] = playerid.Normalize(s.cfg, id)
+	}
+}
+
+// trackInflight registers the cancel function of an in-flight request for a profile.
+func (s *backendAPI) trackInflight(profileID string, key string, cancel context.CancelFunc) {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+	if _, ok := s.inflight[profileID]; !ok {
+		s.inflight[profileID] = make(map[string]context.CancelFunc)
+	}
+	s.inflight[profileID][key] = cancel
+}
+
+// untrackInflight removes an in-flight request once it has returned.
+func (s *backendAPI) untrackInflight(profileID string, key string) {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+	delete(s.inflight[profileID], key)
+	if len(s.inflight[profileID]) == 0 {
+		delete(s.inflight, profileID)
+	}
+}
+
+// cancelInflight cancels every in-flight request for a profile and returns
+// how many were cancelled.
+func (s *backendAPI) cancelInflight(profileID string) int {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+	for _, cancel := range s.inflight[profileID] {
+		cancel()
+	}
+	return len(s.inflight[profileID])
+}
+
+// dequeueRequest removes a request key from the profile queue. Redis ignores
+// the removal if the mmforc has already popped it.
+func (s *backendAPI) dequeueRequest(requestKey string) {
+	// The request's context has already been cancelled, so this can't use
+	// it to bound the wait for a connection.
+	// TODO: relocate this redis functionality to a module
+	redisConn := s.pool.Get()
+	defer redisConn.Close()
+
+	queue := s.cfg.GetString("queues.profiles.name")
+	_, err := redisConn.Do("SREM", queue, requestKey)
+	if err != nil {
+		beLog.WithFields(log.Fields{
+			"error":      err.Error(),
+			"component":  "statestorage",
+			"queue":      queue,
+			"requestKey": requestKey,
+		}).Error("State storage failure to remove cancelled request from profile queue")
+	}
+}
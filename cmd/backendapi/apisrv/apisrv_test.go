@@ -0,0 +1,731 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apisrv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/grpcutil"
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	backend "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/alicebob/miniredis"
+	"github.com/gomodule/redigo/redis"
+	"github.com/spf13/viper"
+	"go.opencensus.io/stats"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWeightedSelect(t *testing.T) {
+	candidates := []*backend.MatchObject{
+		{Id: "a", Properties: `{"quality": 0}`},
+		{Id: "b", Properties: `{"quality": 10}`},
+		{Id: "c", Properties: `{}`},
+	}
+
+	// Only one candidate has a positive weight, so it must always be picked first.
+	for i := 0; i < 100; i++ {
+		got := weightedSelect(candidates, "quality", 1)
+		if len(got) != 1 || got[0].Id != "b" {
+			t.Fatalf("weightedSelect() = %v, want only match b", got)
+		}
+	}
+
+	// Picks are without replacement.
+	got := weightedSelect(candidates, "quality", 2)
+	if len(got) != 2 || got[0].Id == got[1].Id {
+		t.Fatalf("weightedSelect() = %v, want two distinct matches", got)
+	}
+
+	// Asking for more than there are returns them all.
+	if got := weightedSelect(candidates, "quality", 5); len(got) != len(candidates) {
+		t.Fatalf("weightedSelect() returned %v matches, want %v", len(got), len(candidates))
+	}
+}
+
+func TestWeightedSelectQualityScore(t *testing.T) {
+	candidates := []*backend.MatchObject{
+		{Id: "a", Properties: `{"quality": 0}`},
+		{Id: "b", Properties: `{}`, QualityScore: 0.8},
+	}
+
+	// The quality_score field is used when the properties have no weight.
+	for i := 0; i < 100; i++ {
+		got := weightedSelect(candidates, "quality", 1)
+		if len(got) != 1 || got[0].Id != "b" {
+			t.Fatalf("weightedSelect() = %v, want only match b", got)
+		}
+	}
+}
+
+func TestSelectMatchesReleasesLosers(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	cfg := viper.New()
+	cfg.Set("jsonkeys.matchWeight", "quality")
+	cfg.Set("matchSelection.count", 1)
+	s := &backendAPI{
+		cfg:  cfg,
+		pool: &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) }},
+	}
+	candidates := make([]*backend.MatchObject, 0)
+	for i, id := range []string{"a", "b", "c"} {
+		mo := &backend.MatchObject{Id: "mo-" + id, Properties: `{"quality": 0}`, Rosters: []*backend.Roster{{Players: []*backend.Player{{Id: id}}}}}
+		if i == 1 {
+			mo.Properties = `{"quality": 10}`
+		}
+		mr.HSet(mo.Id, "properties", mo.Properties)
+		mr.ZAdd("proposed", 1, id)
+		candidates = append(candidates, mo)
+	}
+
+	selected := s.selectMatches(candidates)
+	if len(selected) != 1 || selected[0].Id != "mo-b" {
+		t.Fatalf("selectMatches() = %v, want only mo-b", selected)
+	}
+	if mr.Exists("mo-a") || mr.Exists("mo-c") {
+		t.Error("losing candidates' results weren't deleted")
+	}
+	if !mr.Exists("mo-b") {
+		t.Error("selected match's results were deleted")
+	}
+	// Only the selected match's players stay proposed; the rest are
+	// matchable again.
+	members, _ := mr.ZMembers("proposed")
+	if len(members) != 1 || members[0] != "b" {
+		t.Errorf("proposed ignorelist = %v, want [b]", members)
+	}
+}
+
+func TestReconcileOnce(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	cfg := viper.New()
+	cfg.Set("jsonkeys.connstring", "connstring")
+	s := &BackendAPI{
+		cfg:  cfg,
+		pool: &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) }},
+	}
+
+	mr.HSet("assigned", "properties", `{"mmr.rating": 1200}`)
+	mr.HSet("assigned", "connstring", "127.0.0.1:7777")
+	mr.HSet("orphan", "connstring", "127.0.0.1:7777")
+	mr.ZAdd("mmr.rating", 1200, "assigned")
+
+	// Without repair, orphans are only reported.
+	if err = s.reconcileOnce(context.Background()); err != nil {
+		t.Fatalf("reconcileOnce failed: %v", err)
+	}
+	if !mr.Exists("orphan") {
+		t.Error("orphaned assignment was deleted with repair disabled")
+	}
+
+	cfg.Set("reconciler.repair", true)
+	if err = s.reconcileOnce(context.Background()); err != nil {
+		t.Fatalf("reconcileOnce failed: %v", err)
+	}
+	if mr.Exists("orphan") {
+		t.Error("orphaned assignment wasn't deleted")
+	}
+	if mr.HGet("assigned", "connstring") == "" {
+		t.Error("assignment for an existing player was deleted")
+	}
+}
+
+func TestAssignmentExpired(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	cfg := viper.New()
+	cfg.Set("jsonkeys.connstring", "connstring")
+	cfg.Set("assignments.expiry.index", "assigned")
+	cfg.Set("assignments.expiry.channel", "assignments.expired")
+	cfg.Set("assignments.expiry.ttl", 60)
+	s := &BackendAPI{
+		cfg:  cfg,
+		pool: &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) }},
+	}
+
+	redisConn := s.pool.Get()
+	defer redisConn.Close()
+	redisConn.Send("MULTI")
+	redisConn.Send("HSET", "p1", "connstring", "127.0.0.1:7777")
+	sendTrackAssignment(redisConn, cfg, "p1", map[string]string{"connstring": "127.0.0.1:7777"})
+	if _, err = redisConn.Do("EXEC"); err != nil {
+		t.Fatalf("failed to create assignment: %v", err)
+	}
+	if got := mr.TTL("p1"); got != 60*time.Second {
+		t.Errorf("assigned player TTL = %v, want %v", got, 60*time.Second)
+	}
+
+	psc := redis.PubSubConn{Conn: s.pool.Get()}
+	defer psc.Close()
+	if err = psc.Subscribe("assignments.expired"); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	psc.Receive() // Subscription confirmation.
+
+	// Unassigned keys, and assignments already reported, publish nothing.
+	mr.Del("p1")
+	s.assignmentExpired(context.Background(), "unassigned")
+	s.assignmentExpired(context.Background(), "p1")
+	s.assignmentExpired(context.Background(), "p1")
+
+	msg, ok := psc.ReceiveWithTimeout(time.Second).(redis.Message)
+	if !ok {
+		t.Fatal("no assignment expiry published")
+	}
+	event := AssignmentExpiry{}
+	if err = json.Unmarshal(msg.Data, &event); err != nil {
+		t.Fatalf("failed to parse assignment expiry: %v", err)
+	}
+	if event.PlayerID != "p1" || event.ConnectionString != "127.0.0.1:7777" {
+		t.Errorf("assignment expiry = %+v, want p1 at 127.0.0.1:7777", event)
+	}
+	if _, ok := psc.ReceiveWithTimeout(100 * time.Millisecond).(redis.Message); ok {
+		t.Error("assignment expiry published more than once")
+	}
+}
+
+func TestGetHealth(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	cfg := viper.New()
+	cfg.Set("mmfs.up.protocol", "http")
+	cfg.Set("mmfs.up.url", "http://"+ln.Addr().String()+"/")
+	cfg.Set("mmfs.down.protocol", "http")
+	cfg.Set("mmfs.down.url", "http://127.0.0.1:1/")
+	cfg.Set("mmfs.job.name", "gcr.io/example/mmf")
+	s := &backendAPI{
+		cfg:  cfg,
+		pool: &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) }},
+	}
+
+	status, err := s.GetHealth(context.Background(), &backend.HealthRequest{})
+	if err != nil {
+		t.Fatalf("GetHealth failed: %v", err)
+	}
+	want := map[string]bool{"redis": true, "mmf.up": true, "mmf.down": false}
+	if len(status.Checks) != len(want) {
+		t.Fatalf("GetHealth returned checks %v, want %v", status.Checks, want)
+	}
+	for _, check := range status.Checks {
+		if healthy, ok := want[check.Name]; !ok || healthy != check.Healthy {
+			t.Errorf("check %v healthy = %v, want %v", check.Name, check.Healthy, healthy)
+		}
+	}
+	if status.Healthy || status.Draining {
+		t.Errorf("GetHealth healthy = %v, draining = %v, want false and false", status.Healthy, status.Draining)
+	}
+
+	s.draining = 1
+	status, _ = s.GetHealth(context.Background(), &backend.HealthRequest{})
+	if !status.Draining {
+		t.Error("GetHealth draining = false after Stop, want true")
+	}
+}
+
+func TestFailingMetricsRecorder(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+	mr.SAdd("activeplayers", "p1", "p2")
+
+	recorder := metrics.Recorder
+	defer func() { metrics.Recorder = recorder }()
+	metrics.Recorder = func(context.Context, ...stats.Measurement) {
+		panic("stats backend misconfigured")
+	}
+
+	cfg := viper.New()
+	cfg.Set("activePlayers.enabled", true)
+	cfg.Set("activePlayers.key", "activeplayers")
+	s := &backendAPI{
+		cfg:  cfg,
+		pool: &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) }},
+	}
+
+	// The request must succeed even though every metric fails to record.
+	for i := 0; i < 2; i++ {
+		count, err := s.GetActivePlayerCount(context.Background(), &backend.ActivePlayerCountRequest{})
+		if err != nil {
+			t.Fatalf("GetActivePlayerCount failed: %v", err)
+		}
+		if count.Count != 2 {
+			t.Errorf("GetActivePlayerCount = %v, want 2", count.Count)
+		}
+	}
+}
+
+func TestStreamError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{errResultsTimeout, codes.DeadlineExceeded},
+		{errors.New("connection refused"), codes.Unavailable},
+		{status.Error(codes.ResourceExhausted, "no connections"), codes.ResourceExhausted},
+	}
+	for _, tt := range tests {
+		if got := status.Code(streamError(tt.err)); got != tt.want {
+			t.Errorf("streamError(%v) code = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateRosters(t *testing.T) {
+	rosters := []*backend.Roster{
+		{Name: "red", Players: []*backend.Player{{Id: "a"}, {Id: "b"}}},
+		{Name: "blue", Players: []*backend.Player{{Id: "c"}, {Id: "d"}}},
+		{Name: "green", Players: []*backend.Player{{Id: "e"}}},
+	}
+
+	dropped := truncateRosters(rosters, 3)
+	if len(dropped) != 2 || dropped[0] != "d" || dropped[1] != "e" {
+		t.Errorf("truncateRosters() dropped %v, want [d e]", dropped)
+	}
+	for i, want := range []int{2, 1, 0} {
+		if got := len(rosters[i].Players); got != want {
+			t.Errorf("roster %v has %v players, want %v", rosters[i].Name, got, want)
+		}
+	}
+}
+
+func TestCheckRosterSizes(t *testing.T) {
+	profileRosters := []*backend.Roster{{Name: "red", TargetSize: 2}, {Name: "blue", TargetSize: 2}}
+	rosters := []*backend.Roster{
+		{Name: "red", Players: []*backend.Player{{Id: "a"}, {Id: "b"}}},
+		{Name: "blue", Players: []*backend.Player{{Id: "c"}}},
+		{Name: "green", Players: []*backend.Player{{Id: "d"}}},
+	}
+
+	want := "roster 'blue' has 1 players, target size is 2"
+	if got := checkRosterSizes(rosters, profileRosters); got != want {
+		t.Errorf("checkRosterSizes() = %q, want %q", got, want)
+	}
+
+	// A target size on the returned roster overrides the profile's.
+	rosters[1].TargetSize = 1
+	if got := checkRosterSizes(rosters, profileRosters); got != "" {
+		t.Errorf("checkRosterSizes() = %q, want no problems", got)
+	}
+}
+
+func TestPrioritySender(t *testing.T) {
+	release := make(chan struct{})
+	var sent []string
+	ps := &prioritySender{send: func(mo *backend.MatchObject) error {
+		if mo.Id == "first" {
+			<-release
+		}
+		sent = append(sent, mo.Id)
+		return nil
+	}}
+
+	// Hold the stream with the first match while the others queue up.
+	done := make(chan struct{})
+	go func() {
+		ps.Send(&backend.MatchObject{Id: "first"})
+		close(done)
+	}()
+	// waitFor polls the sender's state until cond holds.
+	waitFor := func(cond func() bool) {
+		for {
+			ps.mu.Lock()
+			ok := cond()
+			ps.mu.Unlock()
+			if ok {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitFor(func() bool { return ps.busy })
+	for i, mo := range []*backend.MatchObject{{Id: "low", Priority: 1}, {Id: "high", Priority: 5}, {Id: "high2", Priority: 5}} {
+		go ps.Send(mo)
+		waitFor(func() bool { return len(ps.waiting) == i+1 })
+	}
+
+	close(release)
+	<-done
+	waitFor(func() bool { return !ps.busy })
+
+	want := []string{"first", "high", "high2", "low"}
+	if len(sent) != len(want) {
+		t.Fatalf("sent %v, want %v", sent, want)
+	}
+	for i := range want {
+		if sent[i] != want[i] {
+			t.Errorf("sent %v, want %v", sent, want)
+			break
+		}
+	}
+}
+
+func TestBufferedSender(t *testing.T) {
+	release := make(chan struct{})
+	var sent []string
+	b := newBufferedSender(2, func(mo *backend.MatchObject) error {
+		<-release
+		sent = append(sent, mo.Id)
+		return nil
+	})
+
+	// The stalled client holds one match, and the buffer the next two.
+	for _, id := range []string{"m1", "m2", "m3"} {
+		if err := b.Send(&backend.MatchObject{Id: id}); err != nil {
+			t.Fatalf("Send(%v) = %v", id, err)
+		}
+	}
+	close(release)
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if len(sent) != 3 || sent[0] != "m1" || sent[1] != "m2" || sent[2] != "m3" {
+		t.Errorf("sent %v, want [m1 m2 m3]", sent)
+	}
+
+	// A failed send is reported by the next Send and by Close.
+	streamErr := errors.New("stream closed")
+	b = newBufferedSender(1, func(mo *backend.MatchObject) error { return streamErr })
+	b.Send(&backend.MatchObject{Id: "m1"})
+	<-b.done
+	if err := b.Send(&backend.MatchObject{Id: "m2"}); err != streamErr {
+		t.Errorf("Send after a failed send = %v, want %v", err, streamErr)
+	}
+	if err := b.Close(); err != streamErr {
+		t.Errorf("Close after a failed send = %v, want %v", err, streamErr)
+	}
+}
+
+func TestFillPoolStats(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	s := &backendAPI{
+		cfg:  viper.New(),
+		pool: &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) }},
+	}
+	mr.ZAdd("mmr.rating", 1200, "p1")
+	mr.ZAdd("mmr.rating", 1800, "p2")
+
+	profile := &backend.MatchObject{Pools: []*backend.PlayerPool{{
+		Name: "everyone",
+		Filters: []*backend.Filter{
+			{Name: "skilled", Attribute: "mmr.rating", Minv: 1500},
+			{Attribute: "region.eu"},
+		},
+	}}}
+	mo := &backend.MatchObject{Error: "no match"}
+	s.fillPoolStats(context.Background(), mo, profile)
+
+	if len(mo.Pools) != 1 || mo.Pools[0].Stats == nil {
+		t.Fatalf("pools = %v, want the profile's pool with stats", mo.Pools)
+	}
+	counts := mo.Pools[0].Stats.FilterCounts
+	if counts["skilled"] != 1 || counts["region.eu"] != 0 || len(counts) != 2 {
+		t.Errorf("filter counts = %v, want skilled 1 and region.eu 0", counts)
+	}
+	if got := mo.Pools[0].Filters[0].Stats.GetCount(); got != 1 {
+		t.Errorf("skilled filter count = %v, want 1", got)
+	}
+	if profile.Pools[0].Stats != nil {
+		t.Error("the profile's pools were modified")
+	}
+}
+
+func TestOpenStream(t *testing.T) {
+	recorder := metrics.Recorder
+	defer func() { metrics.Recorder = recorder }()
+	var recorded []int64
+	metrics.Recorder = func(_ context.Context, ms ...stats.Measurement) {
+		for _, m := range ms {
+			if m.Measure() == BeOpenStreams {
+				recorded = append(recorded, int64(m.Value()))
+			}
+		}
+	}
+
+	var open int64
+	closeFirst := openStream(context.Background(), &open)
+	closeSecond := openStream(context.Background(), &open)
+	closeSecond()
+	closeFirst()
+
+	want := []int64{1, 2, 1, 0}
+	if len(recorded) != len(want) {
+		t.Fatalf("recorded open streams %v, want %v", recorded, want)
+	}
+	for i := range want {
+		if recorded[i] != want[i] {
+			t.Errorf("recorded open streams %v, want %v", recorded, want)
+			break
+		}
+	}
+}
+
+func TestDropOverlapping(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	s := &backendAPI{
+		cfg:  viper.New(),
+		pool: &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) }},
+	}
+	roster := func(ids ...string) []*backend.Roster {
+		r := &backend.Roster{}
+		for _, id := range ids {
+			r.Players = append(r.Players, &backend.Player{Id: id})
+			mr.ZAdd("proposed", 1, id)
+		}
+		return []*backend.Roster{r}
+	}
+	first := &backend.MatchObject{Id: "mo1", Rosters: roster("a", "b")}
+	second := &backend.MatchObject{Id: "mo2", Rosters: roster("b", "c")}
+	mr.HSet("mo1", "properties", "{}")
+	mr.HSet("mo2", "properties", "{}")
+
+	kept := s.dropOverlapping([]*backend.MatchObject{first, second})
+	if len(kept) != 1 || kept[0] != first {
+		t.Fatalf("dropOverlapping kept %v, want only mo1", kept)
+	}
+	if mr.Exists("mo2") {
+		t.Error("dropped match's results weren't deleted")
+	}
+	if !mr.Exists("mo1") {
+		t.Error("kept match's results were deleted")
+	}
+	// Only the player no kept match holds is released.
+	members, _ := mr.ZMembers("proposed")
+	if len(members) != 2 || members[0] != "a" || members[1] != "b" {
+		t.Errorf("proposed ignorelist = %v, want [a b]", members)
+	}
+}
+
+// fakeMatchStream is a ListMatches stream whose client never cancels.
+type fakeMatchStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (f *fakeMatchStream) Context() context.Context           { return f.ctx }
+func (f *fakeMatchStream) Send(mo *backend.MatchObject) error { return nil }
+
+func TestCancelMatch(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	cfg := viper.New()
+	cfg.Set("queues.profiles.name", "profileq")
+	cfg.Set("interval.resultsTimeout", 30)
+	s := &backendAPI{
+		cfg:      cfg,
+		pool:     &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) }},
+		inflight: make(map[string]map[string]context.CancelFunc),
+	}
+	profile := &backend.MatchObject{Id: "profile"}
+
+	// Nothing is running for the profile yet.
+	if _, err = s.CancelMatch(context.Background(), profile); grpcutil.ErrorCode(cfg, err) != codes.NotFound {
+		t.Errorf("CancelMatch with nothing in flight = %v, want codes.NotFound", err)
+	}
+
+	// No MMF ever answers, so both calls wait until they're cancelled.
+	done := make(chan error, 2)
+	go func() {
+		_, err := s.CreateMatch(context.Background(), profile)
+		done <- err
+	}()
+	go func() {
+		done <- s.ListMatches(profile, &fakeMatchStream{ctx: context.Background()})
+	}()
+
+	// ListMatches registers its stream as well as each CreateMatch it runs.
+	inFlight := func() (streams int, matches int) {
+		s.inflightMu.Lock()
+		defer s.inflightMu.Unlock()
+		for key := range s.inflight[profile.Id] {
+			if strings.HasPrefix(key, "ListMatches.") {
+				streams++
+			} else {
+				matches++
+			}
+		}
+		return streams, matches
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for streams, matches := inFlight(); streams < 1 || matches < 2; streams, matches = inFlight() {
+		if time.Now().After(deadline) {
+			t.Fatalf("%v streams and %v matches in flight, want 1 and 2", streams, matches)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if res, err := s.CancelMatch(context.Background(), profile); err != nil || !res.Success {
+		t.Fatalf("CancelMatch = %v, %v, want success", res, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("cancelled call is still running")
+		}
+	}
+	if members, _ := mr.Members("profileq"); len(members) != 0 {
+		t.Errorf("profile queue = %v after cancelling, want empty", members)
+	}
+}
+
+// raceEntrant is how a fake MMF in a race behaves: it proposes its player
+// at once, and returns a match with the given quality after delay, or once
+// it's cancelled if late is set.
+type raceEntrant struct {
+	quality float64
+	delay   time.Duration
+	late    bool
+}
+
+func TestRaceMatch(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	cfg := viper.New()
+	s := &backendAPI{
+		cfg:      cfg,
+		pool:     &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) }},
+		inflight: make(map[string]map[string]context.CancelFunc),
+	}
+
+	// Each MMF matches its own player, and its results are stored under the
+	// entrant's profile ID.
+	race := func(entrants map[string]raceEntrant) (*backend.MatchObject, error) {
+		mmfs := make([]string, 0, len(entrants))
+		for mmf := range entrants {
+			mmfs = append(mmfs, mmf)
+		}
+		create := func(ctx context.Context, profile *backend.MatchObject) (*backend.MatchObject, error) {
+			e := entrants[profile.Mmf]
+			mr.HSet(profile.Id, "properties", "{}")
+			mr.ZAdd("proposed", 1, profile.Mmf)
+			select {
+			case <-time.After(e.delay):
+			case <-ctx.Done():
+				if !e.late {
+					return nil, ctx.Err()
+				}
+			}
+			return &backend.MatchObject{
+				Id:           profile.Id,
+				QualityScore: e.quality,
+				Rosters:      []*backend.Roster{{Players: []*backend.Player{{Id: profile.Mmf}}}},
+			}, nil
+		}
+		return s.race(context.Background(), &backend.MatchObject{Id: "profile"}, mmfs, create)
+	}
+	released := func(mmf string) bool {
+		_, err := mr.ZScore("proposed", mmf)
+		return !mr.Exists("profile-"+mmf) && err != nil
+	}
+
+	// The first match wins, and the slower MMF is cancelled.
+	mo, err := race(map[string]raceEntrant{
+		"fast": {quality: 1},
+		"slow": {quality: 5, delay: time.Minute},
+	})
+	if err != nil || mo.Id != "profile-fast" {
+		t.Fatalf("first strategy race = %v, %v, want the fast MMF's match", mo, err)
+	}
+	if released("fast") {
+		t.Error("winning match was released")
+	}
+
+	// A loser that finishes after it's cancelled is released in the
+	// background.
+	mo, err = race(map[string]raceEntrant{
+		"winner": {quality: 1},
+		"loser":  {quality: 5, delay: time.Minute, late: true},
+	})
+	if err != nil || mo.Id != "profile-winner" {
+		t.Fatalf("race = %v, %v, want the winner's match", mo, err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !released("loser") {
+		if time.Now().After(deadline) {
+			t.Fatal("late loser's match was never released")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// With the best strategy, the highest quality match wins once every MMF
+	// has finished.
+	cfg.Set("mmfRace.strategy", raceBest)
+	mo, err = race(map[string]raceEntrant{
+		"low":  {quality: 1},
+		"high": {quality: 5, delay: 50 * time.Millisecond},
+	})
+	if err != nil || mo.Id != "profile-high" {
+		t.Fatalf("best strategy race = %v, %v, want the high quality match", mo, err)
+	}
+	if !released("low") {
+		t.Error("lower quality match wasn't released")
+	}
+	if released("high") {
+		t.Error("winning match was released")
+	}
+}
@@ -16,6 +16,7 @@ limitations under the License.
 package apisrv
 
 import (
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
@@ -74,6 +75,17 @@ var (
 	BeAssignmentFailures         = stats.Int64("backendapi/assignment/failures_total", "Number of player match assigment failures", "1")
 	BeAssignmentDeletions        = stats.Int64("backendapi/assignment/deletions_total", "Number of player match assigment deletions", "1")
 	BeAssignmentDeletionFailures = stats.Int64("backendapi/assignment/deletions/failures_total", "Number of player match assigment deletion failures", "1")
+	BeOrphanedAssignments        = stats.Int64("backendapi/assignment/orphans_total", "Number of assignments found without a player record", "1")
+	BeOrphanedAssignmentRepairs  = stats.Int64("backendapi/assignment/orphans/repairs_total", "Number of assignments without a player record that were deleted", "1")
+	BeAssignmentExpirations      = stats.Int64("backendapi/assignment/expirations_total", "Number of assignments that expired before the player deleted them", "1")
+
+	// Match object size instrumentation
+	BeMatchPlayers = stats.Int64("backendapi/match/players", "Number of players in the rosters of matches returned by the Backend API", "1")
+	BeMatchBytes   = stats.Int64("backendapi/match/size_bytes", "Serialized size of matches returned by the Backend API", "By")
+	BeMatchQuality = stats.Float64("backendapi/match/quality_score", "Quality score set by the MMF on matches returned by the Backend API", "1")
+
+	// Stream instrumentation
+	BeOpenStreams = stats.Int64("backendapi/open_streams", "Number of ListMatches and ListMatchesMulti streams currently open", "1")
 )
 
 var (
@@ -87,6 +99,18 @@ var (
 	// Latency in buckets:
 	// [>=0ms, >=25ms, >=50ms, >=75ms, >=100ms, >=200ms, >=400ms, >=600ms, >=800ms, >=1s, >=2s, >=4s, >=6s]
 	latencyDistribution = view.Distribution(0, 25, 50, 75, 100, 200, 400, 600, 800, 1000, 2000, 4000, 6000)
+
+	// Players per match in buckets:
+	// [>=0, >=2, >=4, >=8, >=16, >=32, >=64, >=128, >=256, >=512]
+	matchPlayersDistribution = view.Distribution(0, 2, 4, 8, 16, 32, 64, 128, 256, 512)
+
+	// Match size in buckets:
+	// [>=0B, >=1KB, >=4KB, >=16KB, >=64KB, >=256KB, >=1MB, >=4MB]
+	matchBytesDistribution = view.Distribution(0, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304)
+
+	// Match quality in buckets, assuming MMFs normalize scores to [0, 1]:
+	// [>=0, >=0.1, >=0.2, ... >=0.9, >=1]
+	matchQualityDistribution = view.Distribution(0, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1)
 )
 
 // Package metrics provides some convience views.
@@ -162,6 +186,59 @@ var (
 		Description: "The number of player match assignment failures",
 		Aggregation: view.Count(),
 	}
+
+	BeOrphanedAssignmentCountView = &view.View{
+		Name:        "backend/assignments/orphans",
+		Measure:     BeOrphanedAssignments,
+		Description: "The number of assignments found without a player record",
+		Aggregation: view.Sum(),
+	}
+
+	BeOrphanedAssignmentRepairCountView = &view.View{
+		Name:        "backend/assignments/orphans/repairs",
+		Measure:     BeOrphanedAssignmentRepairs,
+		Description: "The number of assignments without a player record that were deleted",
+		Aggregation: view.Sum(),
+	}
+
+	BeAssignmentExpirationCountView = &view.View{
+		Name:        "backend/assignments/expirations",
+		Measure:     BeAssignmentExpirations,
+		Description: "The number of assignments that expired before the player deleted them",
+		Aggregation: view.Count(),
+	}
+
+	BeOpenStreamsView = &view.View{
+		Name:        "backend/grpc/open_streams",
+		Measure:     BeOpenStreams,
+		Description: "The number of ListMatches and ListMatchesMulti streams currently open",
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{KeyMethod},
+	}
+
+	BeMatchPlayersView = &view.View{
+		Name:        "backend/match/players",
+		Measure:     BeMatchPlayers,
+		Description: "The distribution of the number of players per match",
+		Aggregation: matchPlayersDistribution,
+		TagKeys:     []tag.Key{KeyMethod},
+	}
+
+	BeMatchBytesView = &view.View{
+		Name:        "backend/match/size_bytes",
+		Measure:     BeMatchBytes,
+		Description: "The distribution of serialized match sizes",
+		Aggregation: matchBytesDistribution,
+		TagKeys:     []tag.Key{KeyMethod},
+	}
+
+	BeMatchQualityView = &view.View{
+		Name:        "backend/match/quality_score",
+		Measure:     BeMatchQuality,
+		Description: "The distribution of match quality scores",
+		Aggregation: matchQualityDistribution,
+		TagKeys:     []tag.Key{KeyMethod},
+	}
 )
 
 // DefaultBackendAPIViews are the default backend API OpenCensus measure views.
@@ -175,4 +252,15 @@ var DefaultBackendAPIViews = []*view.View{
 	BeAssignmentFailureCountView,
 	BeAssignmentDeletionCountView,
 	BeAssignmentDeletionFailureCountView,
+	BeOrphanedAssignmentCountView,
+	BeOrphanedAssignmentRepairCountView,
+	BeAssignmentExpirationCountView,
+	BeMatchPlayersView,
+	BeMatchBytesView,
+	BeMatchQualityView,
+	BeOpenStreamsView,
+}
+
+func init() {
+	metrics.AddViews(DefaultBackendAPIViews...)
 }
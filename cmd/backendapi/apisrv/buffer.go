@@ -0,0 +1,88 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package apisrv
+
+import (
+	backend "github.com/GoogleCloudPlatform/open-match/internal/pb"
+)
+
+// streamBufferSize returns how many matches each profile's loop may queue
+// ahead of the client, from 'listMatches.bufferSize'.  0, the default, sends
+// every match before the loop continues.
+func (s *backendAPI) streamBufferSize() int {
+	if s.cfg.GetInt("listMatches.bufferSize") > 0 {
+		return s.cfg.GetInt("listMatches.bufferSize")
+	}
+	return 0
+}
+
+// bufferedSender queues matches on a buffered channel for a goroutine that
+// sends them, so a client that briefly stops reading doesn't hold up the MMF
+// loop until the buffer is full.
+type bufferedSender struct {
+	queue chan *backend.MatchObject
+	// Closed when the sending goroutine exits, after which err holds the
+	// error that stopped it, if any.
+	done chan struct{}
+	err  error
+}
+
+// newBufferedSender starts a goroutine passing up to size queued matches to
+// send, in the order they were queued.  Close must be called to stop it.
+func newBufferedSender(size int, send func(*backend.MatchObject) error) *bufferedSender {
+	b := &bufferedSender{
+		queue: make(chan *backend.MatchObject, size),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		defer close(b.done)
+		for mo := range b.queue {
+			if err := send(mo); err != nil {
+				b.err = err
+				return
+			}
+		}
+	}()
+	return b
+}
+
+// Send queues a match, blocking only while the buffer is full.  It returns
+// the error that stopped the sending goroutine, if a previous send failed.
+func (b *bufferedSender) Send(mo *backend.MatchObject) error {
+	// Check for a failure first, as select picks at random when the buffer
+	// also has room.
+	select {
+	case <-b.done:
+		return b.err
+	default:
+	}
+	select {
+	case b.queue <- mo:
+		return nil
+	case <-b.done:
+		return b.err
+	}
+}
+
+// Close waits for the queued matches to be sent, and returns the error that
+// stopped the sending goroutine, if any.  Send mustn't be called afterwards.
+func (b *bufferedSender) Close() error {
+	close(b.queue)
+	<-b.done
+	return b.err
+}
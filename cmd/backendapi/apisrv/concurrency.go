@@ -0,0 +1,123 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apisrv
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	backend "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/gogo/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+)
+
+// mmfConcurrency returns how many MMF invocations may run at once for one
+// profile, from 'mmfConcurrency.perProfile'.  It defaults to 1, which runs
+// them one after another.
+func (s *backendAPI) mmfConcurrency() int {
+	if s.cfg.IsSet("mmfConcurrency.perProfile") && s.cfg.GetInt("mmfConcurrency.perProfile") > 0 {
+		return s.cfg.GetInt("mmfConcurrency.perProfile")
+	}
+	return 1
+}
+
+// attemptResult holds what one call to createMatch returned.
+type attemptResult struct {
+	mo  *backend.MatchObject
+	err error
+}
+
+// runAttempts calls createMatch for the profile n times, with up to parallel
+// calls in flight at once, and returns the results in the order the calls
+// were started.  Once a call fails for a reason other than the MMF finding no
+// match, or the context is done, no more calls are started.
+func (s *backendAPI) runAttempts(ctx context.Context, p *backend.MatchObject, n int, parallel int) []attemptResult {
+	results := make([]attemptResult, n)
+	slots := make(chan struct{}, parallel)
+	var failed int32
+	var wg sync.WaitGroup
+
+	started := 0
+	for ; started < n; started++ {
+		slots <- struct{}{}
+		if atomic.LoadInt32(&failed) == 1 || ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			mo, err := s.createMatch(ctx, proto.Clone(p).(*backend.MatchObject))
+			var attemptErr *matchAttemptError
+			if err != nil && !errors.As(err, &attemptErr) {
+				atomic.StoreInt32(&failed, 1)
+			}
+			results[i] = attemptResult{mo: mo, err: err}
+		}(started)
+	}
+	wg.Wait()
+
+	return results[:started]
+}
+
+// dropOverlapping removes matches that share a player with an earlier match
+// in the list.  MMFs running at the same time for one profile can pick the
+// same players before either has proposed them; only the first match to
+// claim a player keeps it.  A dropped match's results are deleted, and its
+// other players are taken off the proposed ignorelist so they can be matched
+// again.
+func (s *backendAPI) dropOverlapping(matches []*backend.MatchObject) []*backend.MatchObject {
+	booked := make(map[string]bool)
+	kept := make([]*backend.MatchObject, 0, len(matches))
+	for _, mo := range matches {
+		playerIDs := make([]string, 0)
+		for _, roster := range mo.Rosters {
+			playerIDs = append(playerIDs, getPlayerIdsFromRoster(roster)...)
+		}
+
+		overlaps := false
+		for _, id := range playerIDs {
+			if booked[id] {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			for _, id := range playerIDs {
+				booked[id] = true
+			}
+			kept = append(kept, mo)
+			continue
+		}
+
+		released := make([]string, 0)
+		for _, id := range playerIDs {
+			if !booked[id] {
+				released = append(released, id)
+			}
+		}
+		beLog.WithFields(log.Fields{
+			"matchID":  mo.Id,
+			"released": len(released),
+		}).Warn("Dropping match that shares players with a concurrent match")
+		s.discardMatch(mo, released)
+	}
+	return kept
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apisrv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/grpcutil"
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	backend "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/GoogleCloudPlatform/open-match/internal/playerid"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/assignment"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/playerq"
+	"github.com/gomodule/redigo/redis"
+	log "github.com/sirupsen/logrus"
+	"go.opencensus.io/tag"
+)
+
+// DescribePlayer is this service's implementation of the DescribePlayer gRPC
+// method defined in ../proto/backend.proto
+func (s *backendAPI) DescribePlayer(ctx context.Context, p *backend.PlayerId) (*backend.PlayerRecord, error) {
+	// Create context for tagging OpenCensus metrics.
+	funcName := "DescribePlayer"
+	fnCtx, _ := tag.New(ctx, tag.Insert(KeyMethod, funcName))
+
+	p.Id = playerid.Normalize(s.cfg, p.Id)
+	if p.Id == "" {
+		err := fmt.Errorf("%w: DescribePlayer requires a player id", grpcutil.ErrInvalidArgument)
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return &backend.PlayerRecord{}, err
+	}
+
+	redisConn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		beLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage error")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return &backend.PlayerRecord{}, err
+	}
+	defer redisConn.Close()
+
+	record, err := describePlayer(redisConn, p.Id, s.ignorelistNames())
+	if err != nil {
+		beLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+			"playerid":  p.Id,
+		}).Error("State storage error describing player")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return &backend.PlayerRecord{}, err
+	}
+
+	codec, err := assignment.NewCodec(s.cfg)
+	if err != nil {
+		beLog.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Assignment codec error")
+
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return &backend.PlayerRecord{}, err
+	}
+	ci, err := codec.Decode(record.Fields)
+	switch {
+	case err == nil:
+		record.Assignment = ci
+	case err != assignment.ErrNoAssignment:
+		// The raw fields are still returned, which is what's needed to debug this.
+		beLog.WithFields(log.Fields{
+			"error":    err.Error(),
+			"playerid": p.Id,
+		}).Warn("Connection string decoding error")
+	}
+
+	metrics.Record(fnCtx, BeGrpcRequests.M(1))
+	return record, nil
+}
+
+// ignorelistNames returns the redis keys of the ignorelists in the
+// 'ignoreLists' config section.
+func (s *backendAPI) ignorelistNames() []string {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for il := range s.cfg.GetStringMap("ignoreLists") {
+		name := s.cfg.GetString("ignoreLists." + il + ".name")
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// describePlayer reads the player's record, its TTL, and the player's score
+// in every index and in each of the ignorelists, in one round trip.
+func describePlayer(redisConn redis.Conn, playerID string, ignorelists []string) (*backend.PlayerRecord, error) {
+	indices, err := redis.Strings(redisConn.Do("SMEMBERS", "indices"))
+	if err != nil {
+		return nil, err
+	}
+	indices = append(indices, playerq.ExpiryIndex)
+
+	redisConn.Send("MULTI")
+	redisConn.Send("HGETALL", playerID)
+	redisConn.Send("TTL", playerID)
+	for _, index := range indices {
+		redisConn.Send("ZSCORE", index, playerID)
+	}
+	for _, il := range ignorelists {
+		redisConn.Send("ZSCORE", il, playerID)
+	}
+	replies, err := redis.Values(redisConn.Do("EXEC"))
+	if err != nil {
+		return nil, err
+	}
+
+	record := &backend.PlayerRecord{
+		Id:          playerID,
+		Indices:     make(map[string]int64),
+		Ignorelists: make(map[string]int64),
+	}
+	if record.Fields, err = redis.StringMap(replies[0], nil); err != nil {
+		return nil, err
+	}
+	if record.TtlSeconds, err = redis.Int64(replies[1], nil); err != nil {
+		return nil, err
+	}
+	scores := replies[2:]
+	for i, index := range indices {
+		// Indices that don't hold the player reply with nil.
+		if score, err := redis.Float64(scores[i], nil); err == nil {
+			record.Indices[index] = int64(score)
+		}
+	}
+	scores = scores[len(indices):]
+	for i, il := range ignorelists {
+		if score, err := redis.Float64(scores[i], nil); err == nil {
+			record.Ignorelists[il] = int64(score)
+		}
+	}
+	return record, nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apisrv
+
+import (
+	"context"
+	"strings"
+
+	backend "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// dryRunMatch runs the MMF for the profile and returns the match it made,
+// leaving the players matchable.  The MMF Logic API sees the stored profile's
+// dry_run field and writes the MMF's match straight to the results key,
+// rather than proposing it.  The profile is stored under a key of its own
+// for each call, so a dry run can't clobber a real run of the same profile,
+// or another dry run of it, and it and the results are deleted once the
+// match is returned.
+func (s *backendAPI) dryRunMatch(c context.Context, profile *backend.MatchObject) (*backend.MatchObject, error) {
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+
+	// The copy runs under its own profile ID, so register the dry run under
+	// the caller's profile ID for CancelMatch.
+	runID := strings.Replace(uuid.New().String(), "-", "", -1)
+	dryRunKey := "dryrun." + runID
+	s.trackInflight(profile.Id, dryRunKey, cancel)
+	defer s.untrackInflight(profile.Id, dryRunKey)
+
+	// Keys can't contain '.', as the mmforc splits request keys on it.
+	trial := proto.Clone(profile).(*backend.MatchObject)
+	trial.Id = profile.Id + "-dryrun-" + runID
+
+	mo, err := s.createMatch(ctx, trial)
+
+	keys := []interface{}{trial.Id}
+	if mo != nil && mo.Id != "" && mo.Id != trial.Id {
+		keys = append(keys, mo.Id)
+	}
+	redisConn := s.pool.Get()
+	defer redisConn.Close()
+	if _, derr := redisConn.Do("DEL", keys...); derr != nil {
+		beLog.WithFields(log.Fields{
+			"error":     derr.Error(),
+			"component": "statestorage",
+			"profileID": profile.Id,
+		}).Error("State storage error deleting dry run results")
+	}
+
+	return mo, err
+}
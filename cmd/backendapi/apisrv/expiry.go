@@ -0,0 +1,216 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apisrv
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	redisHelpers "github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/assignment"
+	"github.com/gomodule/redigo/redis"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// expiredChannel is the redis keyevent notification channel pattern for
+// expired keys in any database.
+const expiredChannel = "__keyevent@*__:expired"
+
+// popAssignmentScript returns and removes a player's entry in the assignment
+// expiry index, so only one backend instance publishes each expiry.
+var popAssignmentScript = redis.NewScript(1, `
+local fields = redis.call("HGET", KEYS[1], ARGV[1])
+if fields then
+	redis.call("HDEL", KEYS[1], ARGV[1])
+end
+return fields
+`)
+
+// AssignmentExpiry is the JSON event published to 'assignments.expiry.channel'
+// when an assigned player's record expires before the assignment was
+// deleted, e.g. because the player never connected to the DGS.  Allocation
+// logic can subscribe to the channel to reclaim the player's DGS slot.
+type AssignmentExpiry struct {
+	PlayerID         string `json:"player_id"`
+	ConnectionString string `json:"connstring"`
+	ExpiredAt        int64  `json:"expired_at"`
+}
+
+// sendTrackAssignment pipelines the commands that make a player's assignment
+// eligible for an expiry event: the assignment fields are kept in the index
+// at 'assignments.expiry.index', since the player's record is gone by the
+// time it expires, and the record gets the TTL at 'assignments.expiry.ttl'
+// if set.  Without a TTL the record only expires if the player has a queue
+// TTL, from 'players.ttl' or 'players.ttlRules', and stops refreshing it.
+func sendTrackAssignment(redisConn redis.Conn, cfg *viper.Viper, playerID string, fields map[string]string) {
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		// A map[string]string always marshals.
+		return
+	}
+	redisConn.Send("HSET", cfg.GetString("assignments.expiry.index"), playerID, payload)
+	if ttl := cfg.GetInt("assignments.expiry.ttl"); ttl > 0 {
+		redisConn.Send("EXPIRE", playerID, ttl)
+	}
+}
+
+// watchAssignmentExpirations subscribes to redis keyspace expiry
+// notifications and publishes an AssignmentExpiry event for every expired
+// player record that held an assignment.  Runs until ctx is cancelled,
+// re-subscribing after connection errors.
+func (s *BackendAPI) watchAssignmentExpirations(ctx context.Context) {
+	// Ask redis to publish expiry events. Managed redis offerings often
+	// disallow CONFIG; in that case they must be enabled by the operator.
+	redisConn, err := s.pool.GetContext(ctx)
+	if err == nil {
+		err = redisHelpers.EnableKeyspaceEvents(redisConn, "Ex")
+		redisConn.Close()
+	}
+	if err != nil {
+		beLog.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Warn("Unable to enable keyspace expiry notifications, they must be enabled in the redis config")
+	}
+
+	for ctx.Err() == nil {
+		err := s.subscribeAssignmentExpirations(ctx)
+		if err != nil && ctx.Err() == nil {
+			beLog.WithFields(log.Fields{
+				"error": err.Error(),
+			}).Error("Keyspace expiry subscription error, resubscribing")
+			time.Sleep(5 * time.Second)
+		}
+	}
+}
+
+// subscribeAssignmentExpirations handles expiry notifications on a single
+// pubsub connection until it fails or ctx is cancelled.
+func (s *BackendAPI) subscribeAssignmentExpirations(ctx context.Context) error {
+	redisConn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	psc := redis.PubSubConn{Conn: redisConn}
+	defer psc.Close()
+
+	if err := psc.PSubscribe(expiredChannel); err != nil {
+		return err
+	}
+
+	// Unblock Receive() when the context is cancelled.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			psc.PUnsubscribe(expiredChannel)
+		case <-done:
+		}
+	}()
+
+	for {
+		// Messages can be far apart, so wait without the read timeout.
+		switch v := psc.ReceiveWithTimeout(0).(type) {
+		case redis.Message:
+			s.assignmentExpired(ctx, string(v.Data))
+		case redis.Subscription:
+			if v.Count == 0 {
+				return nil
+			}
+		case error:
+			return v
+		}
+	}
+}
+
+// assignmentExpired publishes an AssignmentExpiry event if the expired key
+// was an assigned player.
+func (s *BackendAPI) assignmentExpired(ctx context.Context, key string) {
+	index := s.cfg.GetString("assignments.expiry.index")
+	channel := s.cfg.GetString("assignments.expiry.channel")
+	if index == "" || channel == "" {
+		return
+	}
+
+	redisConn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		beLog.WithFields(log.Fields{
+			"error": err.Error(),
+			"key":   key,
+		}).Error("State storage connection error")
+		return
+	}
+	defer redisConn.Close()
+
+	payload, err := redis.Bytes(popAssignmentScript.Do(redisConn, index, key))
+	if err == redis.ErrNil {
+		// Not an assigned player.
+		return
+	}
+	if err != nil {
+		beLog.WithFields(log.Fields{
+			"error": err.Error(),
+			"key":   key,
+		}).Error("State storage error")
+		return
+	}
+
+	event := AssignmentExpiry{PlayerID: key, ExpiredAt: time.Now().Unix()}
+	event.ConnectionString, err = s.decodeExpiredAssignment(payload)
+	if err != nil {
+		// Still publish the expiry, the player ID alone may be enough to
+		// reclaim the slot.
+		beLog.WithFields(log.Fields{
+			"error": err.Error(),
+			"key":   key,
+		}).Warn("Unable to decode expired assignment")
+	}
+
+	msg, _ := json.Marshal(event)
+	_, err = redisConn.Do("PUBLISH", channel, msg)
+	if err != nil {
+		beLog.WithFields(log.Fields{
+			"error": err.Error(),
+			"key":   key,
+		}).Error("Unable to publish assignment expiry")
+		return
+	}
+
+	beLog.WithFields(log.Fields{"playerid": key}).Info("Assignment expired before it was deleted")
+	metrics.Record(ctx, BeAssignmentExpirations.M(1))
+}
+
+// decodeExpiredAssignment returns the connection string from the assignment
+// fields stored in the expiry index by sendTrackAssignment.
+func (s *BackendAPI) decodeExpiredAssignment(payload []byte) (string, error) {
+	fields := make(map[string]string)
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return "", err
+	}
+	codec, err := assignment.NewCodec(s.cfg)
+	if err != nil {
+		return "", err
+	}
+	ci, err := codec.Decode(fields)
+	if err != nil {
+		return "", err
+	}
+	return ci.ConnectionString, nil
+}
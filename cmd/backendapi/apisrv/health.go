@@ -0,0 +1,182 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apisrv
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	"github.com/GoogleCloudPlatform/open-match/internal/mmfallow"
+	backend "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/gomodule/redigo/redis"
+	log "github.com/sirupsen/logrus"
+	"go.opencensus.io/tag"
+)
+
+// defaultHealthCheckTimeout is how long, in milliseconds, each dependency
+// check may take when 'api.backend.healthCheckTimeout' is not set.
+const defaultHealthCheckTimeout = 2000
+
+// healthCheck checks a single dependency, returning an error if it's
+// unhealthy.
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// GetHealth is this service's implementation of the GetHealth gRPC method
+// defined in ../proto/backend.proto
+func (s *backendAPI) GetHealth(ctx context.Context, req *backend.HealthRequest) (*backend.HealthStatus, error) {
+	// Create context for tagging OpenCensus metrics.
+	funcName := "GetHealth"
+	fnCtx, _ := tag.New(ctx, tag.Insert(KeyMethod, funcName))
+
+	timeout := defaultHealthCheckTimeout
+	if s.cfg.IsSet("api.backend.healthCheckTimeout") && s.cfg.GetInt("api.backend.healthCheckTimeout") > 0 {
+		timeout = s.cfg.GetInt("api.backend.healthCheckTimeout")
+	}
+
+	// Run the checks concurrently, so one slow dependency doesn't delay the
+	// rest.
+	checks := s.healthChecks()
+	results := make([]*backend.HealthCheck, len(checks))
+	var wg sync.WaitGroup
+	for i, hc := range checks {
+		wg.Add(1)
+		go func(i int, hc healthCheck) {
+			defer wg.Done()
+			results[i] = runHealthCheck(ctx, hc, time.Duration(timeout)*time.Millisecond)
+		}(i, hc)
+	}
+	wg.Wait()
+
+	draining := s.isDraining()
+	status := &backend.HealthStatus{
+		Healthy:       !draining,
+		Checks:        results,
+		PoolActive:    int64(s.pool.ActiveCount()),
+		PoolIdle:      int64(s.pool.IdleCount()),
+		PoolMaxActive: int64(s.pool.MaxActive),
+		Draining:      draining,
+	}
+	for _, result := range results {
+		if !result.Healthy {
+			status.Healthy = false
+			beLog.WithFields(log.Fields{
+				"func":  funcName,
+				"check": result.Name,
+				"error": result.Error,
+			}).Warn("Health check failed")
+		}
+	}
+
+	metrics.Record(fnCtx, BeGrpcRequests.M(1))
+	return status, nil
+}
+
+// healthChecks returns a check for state storage, and one for each MMF
+// served over HTTP.  MMFs run as k8s jobs have no endpoint to check.
+func (s *backendAPI) healthChecks() []healthCheck {
+	checks := []healthCheck{{name: "redis", check: s.checkRedis}}
+
+	names := make([]string, 0)
+	for name := range s.cfg.GetStringMap("mmfs") {
+		if s.cfg.GetString("mmfs."+name+".protocol") == "http" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		endpoint := s.cfg.GetString("mmfs." + name + ".url")
+		checks = append(checks, healthCheck{
+			name: "mmf." + name,
+			check: func(ctx context.Context) error {
+				// Disallowed MMFs are never dialed, and can't serve matches.
+				if err := mmfallow.Check(s.cfg, mmfallow.ProtocolHTTP, endpoint); err != nil {
+					return err
+				}
+				return checkEndpoint(ctx, endpoint)
+			},
+		})
+	}
+	return checks
+}
+
+// runHealthCheck runs hc with the timeout and reports how it went.
+func runHealthCheck(ctx context.Context, hc healthCheck, timeout time.Duration) *backend.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := hc.check(ctx)
+	result := &backend.HealthCheck{
+		Name:      hc.name,
+		Healthy:   err == nil,
+		LatencyMs: time.Since(start).Nanoseconds() / int64(time.Millisecond),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// checkRedis pings state storage before ctx's deadline.
+func (s *backendAPI) checkRedis(ctx context.Context) error {
+	redisConn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer redisConn.Close()
+	deadline, _ := ctx.Deadline()
+	_, err = redis.DoWithTimeout(redisConn, time.Until(deadline), "PING")
+	return err
+}
+
+// checkEndpoint checks that a TCP connection can be made to the host of the
+// URL.  Whether the server behind it is working is left to the MMF.
+func checkEndpoint(ctx context.Context, endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// isDraining returns true once Stop has been called.
+func (s *backendAPI) isDraining() bool {
+	return atomic.LoadInt32(&s.draining) == 1
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apisrv
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	backend "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.opencensus.io/tag"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListMatchesMulti is this service's implementation of the ListMatchesMulti
+// gRPC method defined in api/protobuf-spec/backend.proto
+// Each profile is run in its own loop, as ListMatches would, and their
+// matches share the one stream.
+func (s *backendAPI) ListMatchesMulti(req *backend.ProfileList, matchStream backend.Backend_ListMatchesMultiServer) error {
+	ctx, cancel := context.WithCancel(matchStream.Context())
+	defer cancel()
+
+	// Create context for tagging OpenCensus metrics.
+	funcName := "ListMatchesMulti"
+	fnCtx, _ := tag.New(ctx, tag.Insert(KeyMethod, funcName))
+
+	// Profiles are told apart by ID, so they must have distinct ones.
+	seen := make(map[string]bool)
+	for _, p := range req.Profiles {
+		if p.Id == "" || seen[p.Id] {
+			metrics.Record(fnCtx, BeGrpcErrors.M(1))
+			return status.Errorf(codes.InvalidArgument, "every profile needs a unique id, got '%v'", p.Id)
+		}
+		seen[p.Id] = true
+	}
+	if len(req.Profiles) == 0 {
+		metrics.Record(fnCtx, BeGrpcErrors.M(1))
+		return status.Error(codes.InvalidArgument, "no profiles requested")
+	}
+
+	defer openStream(fnCtx, &s.listMatchesMultiStreams)()
+
+	beLog.WithFields(log.Fields{
+		"func":        funcName,
+		"numProfiles": len(req.Profiles),
+	}).Info("gRPC call executing. Calling CreateMatch for each profile. Looping until cancelled.")
+
+	// gRPC streams don't support concurrent sends.  While the stream is busy,
+	// the highest priority match waiting to be sent goes next.
+	sender := &prioritySender{send: matchStream.Send}
+	send := sender.Send
+
+	// The first profile to fail ends the stream, stopping the rest.
+	var errOnce sync.Once
+	var streamErr error
+	var wg sync.WaitGroup
+	for _, p := range req.Profiles {
+		// Each profile can be cancelled on its own with CancelMatch.
+		profileCtx, profileCancel := context.WithCancel(ctx)
+		streamKey := funcName + "." + strings.Replace(uuid.New().String(), "-", "", -1)
+		s.trackInflight(p.Id, streamKey, profileCancel)
+
+		wg.Add(1)
+		go func(p *backend.MatchObject, profileCtx context.Context, profileCancel context.CancelFunc, streamKey string) {
+			defer wg.Done()
+			defer profileCancel()
+			defer s.untrackInflight(p.Id, streamKey)
+
+			if err := s.listMatches(profileCtx, fnCtx, funcName, p, send); err != nil {
+				errOnce.Do(func() {
+					streamErr = err
+					cancel()
+				})
+			}
+		}(p, profileCtx, profileCancel, streamKey)
+	}
+	wg.Wait()
+
+	return streamErr
+}
+
+// prioritySender serializes sends on a stream shared by several profiles.
+// Each caller blocks until its match has been sent, so a slow client holds up
+// the profiles rather than matches piling up in memory, and whenever the
+// stream frees up the waiting match with the highest priority is sent next.
+type prioritySender struct {
+	send func(*backend.MatchObject) error
+
+	mu      sync.Mutex
+	busy    bool
+	waiting []pendingSend
+}
+
+// pendingSend is a match waiting for its turn on the stream.
+type pendingSend struct {
+	priority int64
+	turn     chan struct{}
+}
+
+// Send sends the match once it's the highest priority match waiting.
+func (ps *prioritySender) Send(mo *backend.MatchObject) error {
+	ps.mu.Lock()
+	if ps.busy {
+		turn := make(chan struct{})
+		ps.waiting = append(ps.waiting, pendingSend{priority: mo.Priority, turn: turn})
+		ps.mu.Unlock()
+		// The sender before this one hands over the stream.
+		<-turn
+	} else {
+		ps.busy = true
+		ps.mu.Unlock()
+	}
+
+	err := ps.send(mo)
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if len(ps.waiting) == 0 {
+		ps.busy = false
+		return err
+	}
+	// Among equal priorities, the match that has waited longest goes first.
+	next := 0
+	for i, p := range ps.waiting {
+		if p.priority > ps.waiting[next].priority {
+			next = i
+		}
+	}
+	close(ps.waiting[next].turn)
+	ps.waiting = append(ps.waiting[:next], ps.waiting[next+1:]...)
+	return err
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apisrv
+
+import (
+	backend "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/ignorelist"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+// Values for 'matchValidation.maxPlayersMode', which controls what happens to
+// a match with more players than its profile allows: it is returned as an
+// error (the default), or the extra players are dropped from its rosters.
+const (
+	maxPlayersReject   = "reject"
+	maxPlayersTruncate = "truncate"
+)
+
+// maxPlayers returns the most players a match for the profile may hold, or 0
+// for no limit.  The profile can set its own limit in its properties, at the
+// key in 'jsonkeys.maxPlayers'; otherwise 'matchValidation.maxPlayers' applies.
+func (s *backendAPI) maxPlayers(profile *backend.MatchObject) int {
+	if s.cfg.IsSet("jsonkeys.maxPlayers") {
+		if limit := gjson.Get(profile.Properties, s.cfg.GetString("jsonkeys.maxPlayers")); limit.Exists() && limit.Int() > 0 {
+			return int(limit.Int())
+		}
+	}
+	if s.cfg.GetInt("matchValidation.maxPlayers") > 0 {
+		return s.cfg.GetInt("matchValidation.maxPlayers")
+	}
+	return 0
+}
+
+// truncateRosters drops players from the end of the rosters until they hold
+// no more than limit players in total, and returns the IDs of the players it
+// dropped.
+func truncateRosters(rosters []*backend.Roster, limit int) []string {
+	dropped := make([]string, 0)
+	kept := 0
+	for _, roster := range rosters {
+		if kept+len(roster.Players) <= limit {
+			kept += len(roster.Players)
+			continue
+		}
+		keep := limit - kept
+		dropped = append(dropped, getPlayerIdsFromRoster(&backend.Roster{Players: roster.Players[keep:]})...)
+		roster.Players = roster.Players[:keep]
+		kept = limit
+	}
+	return dropped
+}
+
+// releasePlayers removes players dropped from a match from the proposed
+// ignorelist, so other MMFs can match them again.
+func (s *backendAPI) releasePlayers(playerIDs []string) {
+	if len(playerIDs) == 0 {
+		return
+	}
+	redisConn := s.pool.Get()
+	defer redisConn.Close()
+
+	err := ignorelist.Remove(redisConn, "proposed", playerIDs)
+	if err != nil {
+		beLog.WithFields(log.Fields{
+			"error":      err.Error(),
+			"component":  "statestorage",
+			"numPlayers": len(playerIDs),
+		}).Error("State storage error releasing players dropped from match")
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package apisrv
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	backend "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gomodule/redigo/redis"
+	log "github.com/sirupsen/logrus"
+)
+
+// fillPoolStats gives every pool of a match attempt that found nothing the
+// number of players each of its filters matches, so backends can decide
+// whether to wait or relax the profile even if the MMF didn't report the
+// stats.  If the MMF returned no pools, the profile's are used.  Pools that
+// already have stats are left alone, and state storage errors only leave the
+// stats unset.
+func (s *backendAPI) fillPoolStats(ctx context.Context, mo *backend.MatchObject, profile *backend.MatchObject) {
+	if len(mo.Pools) == 0 {
+		for _, p := range profile.Pools {
+			mo.Pools = append(mo.Pools, proto.Clone(p).(*backend.PlayerPool))
+		}
+	}
+	missing := make([]*backend.PlayerPool, 0)
+	for _, p := range mo.Pools {
+		if p.Stats == nil {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) == 0 || ctx.Err() != nil {
+		return
+	}
+
+	redisConn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		beLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Warn("Unable to fill in pool stats")
+		return
+	}
+	defer redisConn.Close()
+
+	// Count every filter's players in one round trip, the way the MMLogic
+	// API does before retrieving a pool.
+	start := time.Now()
+	for _, p := range missing {
+		for _, f := range p.Filters {
+			maxv := strconv.FormatInt(f.Maxv, 10)
+			if f.Maxv == 0 {
+				maxv = "+inf"
+			}
+			redisConn.Send("ZCOUNT", f.Attribute, f.Minv, maxv)
+		}
+	}
+	if err := redisConn.Flush(); err != nil {
+		beLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Warn("Unable to fill in pool stats")
+		return
+	}
+
+	for _, p := range missing {
+		counts := make(map[string]int64)
+		for _, f := range p.Filters {
+			count, err := redis.Int64(redisConn.Receive())
+			if err != nil {
+				beLog.WithFields(log.Fields{
+					"error":     err.Error(),
+					"component": "statestorage",
+					"pool":      p.Name,
+				}).Warn("Unable to fill in pool stats")
+				return
+			}
+			f.Stats = &backend.Stats{Count: count, Elapsed: time.Since(start).Seconds()}
+			if f.Name != "" {
+				counts[f.Name] = count
+			} else {
+				counts[f.Attribute] = count
+			}
+		}
+		p.Stats = &backend.Stats{Elapsed: time.Since(start).Seconds(), FilterCounts: counts}
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package apisrv
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	backend "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/redispb"
+	log "github.com/sirupsen/logrus"
+	"go.opencensus.io/tag"
+)
+
+// progressStageResults is the stage of the last message CreateMatchWithProgress
+// sends, which carries the match results.
+const progressStageResults = "results"
+
+// progressKey is the context key under which CreateMatchWithProgress passes
+// createMatch the channel to forward the MMF's progress markers to.
+type progressKey struct{}
+
+// CreateMatchWithProgress is this service's implementation of the
+// CreateMatchWithProgress gRPC method defined in ../proto/backend.proto
+func (s *backendAPI) CreateMatchWithProgress(profile *backend.MatchObject, stream backend.Backend_CreateMatchWithProgressServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	// Create context for tagging OpenCensus metrics.
+	funcName := "CreateMatchWithProgress"
+	fnCtx, _ := tag.New(ctx, tag.Insert(KeyMethod, funcName))
+
+	type result struct {
+		mo  *backend.MatchObject
+		err error
+	}
+	progress := make(chan *backend.MatchProgress, 16)
+	done := make(chan result, 1)
+	go func() {
+		mo, err := s.CreateMatch(context.WithValue(ctx, progressKey{}, progress), profile)
+		done <- result{mo, err}
+	}()
+
+	for {
+		select {
+		case p := <-progress:
+			if err := stream.Send(p); err != nil {
+				metrics.Record(fnCtx, BeGrpcErrors.M(1))
+				return err
+			}
+		case r := <-done:
+			// Send the markers that arrived before the results first.
+			for len(progress) > 0 {
+				if err := stream.Send(<-progress); err != nil {
+					metrics.Record(fnCtx, BeGrpcErrors.M(1))
+					return err
+				}
+			}
+			if r.err != nil {
+				metrics.Record(fnCtx, BeGrpcErrors.M(1))
+				return r.err
+			}
+			if err := stream.Send(&backend.MatchProgress{Id: r.mo.Id, Stage: progressStageResults, Match: r.mo}); err != nil {
+				metrics.Record(fnCtx, BeGrpcErrors.M(1))
+				return err
+			}
+			metrics.Record(fnCtx, BeGrpcRequests.M(1))
+			return nil
+		}
+	}
+}
+
+// forwardProgress subscribes to the progress markers of the MMF run for
+// requestKey, if ctx is from CreateMatchWithProgress, and forwards them to
+// it until ctx is cancelled.  It returns once the subscription is in place,
+// so the request can be queued without missing any markers.
+func (s *backendAPI) forwardProgress(ctx context.Context, requestKey string) {
+	sink, ok := ctx.Value(progressKey{}).(chan *backend.MatchProgress)
+	if !ok {
+		return
+	}
+	markers, err := redispb.SubscribeProgress(ctx, s.pool, requestKey)
+	if err != nil {
+		// The match is still worth making without progress markers.
+		beLog.WithFields(log.Fields{
+			"error":      err.Error(),
+			"component":  "statestorage",
+			"requestKey": requestKey,
+		}).Warn("State storage failure to subscribe to MMF progress")
+		return
+	}
+	go func() {
+		for p := range markers {
+			select {
+			case sink <- p:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
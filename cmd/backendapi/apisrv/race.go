@@ -0,0 +1,175 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apisrv
+
+import (
+	"context"
+	"strings"
+
+	backend "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/ignorelist"
+	"github.com/gogo/protobuf/proto"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// Values for 'mmfRace.strategy', which picks the match CreateMatch returns
+// when it races several MMFs on the same profile: the first successful
+// result (the default), or the one with the highest quality score once
+// every MMF has finished.
+const (
+	raceFirst = "first"
+	raceBest  = "best"
+)
+
+// raceResult is what one MMF in a race returned.
+type raceResult struct {
+	mmf string
+	mo  *backend.MatchObject
+	err error
+}
+
+// raceMMFs returns the MMFs CreateMatch should race for the profile, from
+// 'mmfRace.mmfs'.  It returns nil if fewer than two are configured, or if the
+// profile asks for a specific MMF.
+func (s *backendAPI) raceMMFs(profile *backend.MatchObject) []string {
+	if profile.Mmf != "" {
+		return nil
+	}
+	mmfs := s.cfg.GetStringSlice("mmfRace.mmfs")
+	if len(mmfs) < 2 {
+		return nil
+	}
+	return mmfs
+}
+
+// raceMatch runs a copy of the profile through each MMF concurrently and
+// returns the match chosen by 'mmfRace.strategy'.  The other MMFs are
+// cancelled, and any matches they still made are released so their players
+// can be matched again.  If every MMF fails, the last error is returned.
+func (s *backendAPI) raceMatch(c context.Context, profile *backend.MatchObject, mmfs []string) (*backend.MatchObject, error) {
+	return s.race(c, profile, mmfs, s.createMatch)
+}
+
+// race is raceMatch, with each MMF run by create.
+func (s *backendAPI) race(c context.Context, profile *backend.MatchObject, mmfs []string, create func(context.Context, *backend.MatchObject) (*backend.MatchObject, error)) (*backend.MatchObject, error) {
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+
+	// The copies run under their own profile IDs, so register the race
+	// under the caller's profile ID for CancelMatch.
+	raceKey := "race." + strings.Replace(uuid.New().String(), "-", "", -1)
+	s.trackInflight(profile.Id, raceKey, cancel)
+	defer s.untrackInflight(profile.Id, raceKey)
+
+	results := make(chan raceResult, len(mmfs))
+	for _, mmf := range mmfs {
+		// The mmforc reads the MMF to run from the stored profile, so each
+		// copy needs its own key.  Keys can't contain '.', as the mmforc
+		// splits request keys on it.
+		entrant := proto.Clone(profile).(*backend.MatchObject)
+		entrant.Id = profile.Id + "-" + mmf
+		entrant.Mmf = mmf
+		go func(mmf string, entrant *backend.MatchObject) {
+			mo, err := create(ctx, entrant)
+			results <- raceResult{mmf: mmf, mo: mo, err: err}
+		}(mmf, entrant)
+	}
+
+	bestOf := s.cfg.GetString("mmfRace.strategy") == raceBest
+	var winner, last raceResult
+	received := 0
+	for received < len(mmfs) {
+		r := <-results
+		received++
+		if r.err != nil {
+			beLog.WithFields(log.Fields{
+				"error":     r.err.Error(),
+				"profileID": profile.Id,
+				"mmf":       r.mmf,
+			}).Info("MMF failed to make a match in race")
+			last = r
+			continue
+		}
+
+		switch {
+		case winner.mo == nil:
+			winner = r
+		case r.mo.QualityScore > winner.mo.QualityScore:
+			s.releaseMatch(winner.mo)
+			winner = r
+		default:
+			s.releaseMatch(r.mo)
+		}
+		if !bestOf {
+			break
+		}
+	}
+
+	// Stop the rest, and release anything they matched before they noticed.
+	cancel()
+	go func(pending int) {
+		for ; pending > 0; pending-- {
+			if r := <-results; r.err == nil {
+				s.releaseMatch(r.mo)
+			}
+		}
+	}(len(mmfs) - received)
+
+	if winner.mo == nil {
+		return last.mo, last.err
+	}
+	beLog.WithFields(log.Fields{
+		"profileID":     profile.Id,
+		"mmf":           winner.mmf,
+		"matchObjectID": winner.mo.Id,
+	}).Info("MMF won race")
+	return winner.mo, nil
+}
+
+// releaseMatch discards a match that lost a race: its results are deleted
+// and its players are removed from the proposed ignorelist.
+func (s *backendAPI) releaseMatch(mo *backend.MatchObject) {
+	playerIDs := make([]string, 0)
+	for _, roster := range mo.Rosters {
+		playerIDs = append(playerIDs, getPlayerIdsFromRoster(roster)...)
+	}
+	s.discardMatch(mo, playerIDs)
+}
+
+// discardMatch deletes the results of a match that won't be returned, and
+// removes playerIDs, the players no other match holds, from the proposed
+// ignorelist.
+func (s *backendAPI) discardMatch(mo *backend.MatchObject, playerIDs []string) {
+	// The request's context may already be done, so don't bound this by it.
+	redisConn := s.pool.Get()
+	defer redisConn.Close()
+
+	redisConn.Send("MULTI")
+	redisConn.Send("DEL", mo.Id)
+	if len(playerIDs) > 0 {
+		ignorelist.SendRemove(redisConn, "proposed", playerIDs)
+	}
+	_, err := redisConn.Do("EXEC")
+	if err != nil {
+		beLog.WithFields(log.Fields{
+			"error":         err.Error(),
+			"component":     "statestorage",
+			"matchObjectID": mo.Id,
+		}).Error("State storage error discarding match")
+	}
+}
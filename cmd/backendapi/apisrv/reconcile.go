@@ -0,0 +1,143 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apisrv
+
+import (
+	"context"
+	"time"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/grpcutil"
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	redisHelpers "github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/assignment"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/playerq"
+	"github.com/gomodule/redigo/redis"
+	log "github.com/sirupsen/logrus"
+)
+
+// Reconciler defaults, used when 'reconciler.interval' or
+// 'reconciler.batchSize' are not set in the config.
+const (
+	defaultReconcileInterval  = 300 // seconds between passes
+	defaultReconcileBatchSize = 1000
+)
+
+// deleteOrphanScript removes a player's assignment only if the player still
+// has no properties, in either layout, so a player re-queued since the check
+// keeps it.  ARGV[1] is the JSON properties field and ARGV[2] the prefix of
+// the hash property fields.  Without properties the hash holds nothing but
+// the assignment, whatever fields the assignment codec stored it in, so the
+// whole hash is deleted.
+var deleteOrphanScript = redis.NewScript(1, `
+for _, field in ipairs(redis.call("HKEYS", KEYS[1])) do
+	if field == ARGV[1] or string.sub(field, 1, #ARGV[2]) == ARGV[2] then
+		return 0
+	end
+end
+return redis.call("DEL", KEYS[1])
+`)
+
+// reconcileAssignments periodically looks for orphaned assignments until ctx
+// is cancelled.
+func (s *BackendAPI) reconcileAssignments(ctx context.Context) {
+	interval := defaultReconcileInterval
+	if s.cfg.IsSet("reconciler.interval") && s.cfg.GetInt("reconciler.interval") > 0 {
+		interval = s.cfg.GetInt("reconciler.interval")
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.reconcileOnce(ctx)
+			if err != nil && ctx.Err() == nil {
+				beLog.WithFields(log.Fields{
+					"error":     err.Error(),
+					"component": "statestorage",
+				}).Error("Assignment reconciliation failed")
+			}
+		}
+	}
+}
+
+// reconcileOnce scans state storage for assignments whose player record no
+// longer exists (e.g. the player was deleted before CreateAssignments ran),
+// and deletes them if 'reconciler.repair' is set.  Otherwise they are only
+// logged and counted.
+func (s *BackendAPI) reconcileOnce(ctx context.Context) error {
+	redisConn, err := grpcutil.GetConn(ctx, s.pool)
+	if err != nil {
+		return err
+	}
+	defer redisConn.Close()
+
+	batchSize := defaultReconcileBatchSize
+	if s.cfg.IsSet("reconciler.batchSize") && s.cfg.GetInt("reconciler.batchSize") > 0 {
+		batchSize = s.cfg.GetInt("reconciler.batchSize")
+	}
+	repair := s.cfg.GetBool("reconciler.repair")
+	codec, err := assignment.NewCodec(s.cfg)
+	if err != nil {
+		return err
+	}
+
+	var orphans, repaired int64
+	scanner := redisHelpers.NewScanner(redisConn, "", "", batchSize)
+	for scanner.Next(ctx) {
+		keys := scanner.Keys()
+		for _, key := range keys {
+			redisConn.Send("HGETALL", key)
+		}
+		if err := redisConn.Flush(); err != nil {
+			return err
+		}
+
+		found := make([]string, 0)
+		for _, key := range keys {
+			// Keys that aren't hashes (e.g. indices) reply with an error.
+			fields, err := redis.StringMap(redisConn.Receive())
+			if err != nil || playerq.HasProperties(fields) {
+				continue
+			}
+			if _, err := codec.Decode(fields); err != assignment.ErrNoAssignment {
+				found = append(found, key)
+			}
+		}
+
+		for _, key := range found {
+			orphans++
+			rLog := beLog.WithFields(log.Fields{"playerid": key, "repair": repair})
+			if !repair {
+				rLog.Warn("Found assignment without a player record")
+				continue
+			}
+			deleted, err := redis.Int(deleteOrphanScript.Do(redisConn, key, playerq.PropertiesField, playerq.PropertyPrefix))
+			if err != nil {
+				return err
+			}
+			if deleted > 0 {
+				repaired++
+				rLog.Info("Deleted assignment without a player record")
+			}
+		}
+	}
+	metrics.Record(ctx, BeOrphanedAssignments.M(orphans), BeOrphanedAssignmentRepairs.M(repaired))
+	return scanner.Err()
+}
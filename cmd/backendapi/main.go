@@ -25,6 +25,7 @@ import (
 	"errors"
 	"os"
 	"os/signal"
+	"syscall"
 
 	"github.com/GoogleCloudPlatform/open-match/cmd/backendapi/apisrv"
 	"github.com/GoogleCloudPlatform/open-match/config"
@@ -69,16 +70,21 @@ func init() {
 	}
 
 	// Configure OpenCensus exporter to Prometheus
-	// metrics.ConfigureOpenCensusPrometheusExporter expects that every OpenCensus view you
-	// want to register is in an array, so append any views you want from other
-	// packages to a single array here.
-	ocServerViews := apisrv.DefaultBackendAPIViews                      // BackendAPI OpenCensus views.
-	ocServerViews = append(ocServerViews, ocgrpc.DefaultServerViews...) // gRPC OpenCensus views.
-	ocServerViews = append(ocServerViews, config.CfgVarCountView)       // config loader view.
+	// Packages that define OpenCensus measures add their views when they're
+	// initialized, so only views from libraries need to be added here.
+	metrics.AddViews(ocgrpc.DefaultServerViews...) // gRPC OpenCensus views.
 	// Waiting on https://github.com/opencensus-integrations/redigo/pull/1
-	// ocServerViews = append(ocServerViews, redis.ObservabilityMetricViews...) // redis OpenCensus views.
-	beLog.WithFields(log.Fields{"viewscount": len(ocServerViews)}).Info("Loaded OpenCensus views")
-	metrics.ConfigureOpenCensusPrometheusExporter(cfg, ocServerViews)
+	// metrics.AddViews(redis.ObservabilityMetricViews...) // redis OpenCensus views.
+	if err := metrics.RegisterAllViews(); err != nil {
+		beLog.WithFields(log.Fields{"error": err.Error()}).Fatal("Failed to register OpenCensus views for metrics gathering")
+	}
+	metrics.ConfigureOpenCensusPrometheusExporter(cfg)
+
+	// Configure how many gRPC requests are traced.
+	metrics.ConfigureOpenCensusTraceSampling(cfg)
+
+	// Optionally serve runtime profiles on the debug port.
+	metrics.ConfigurePprof(cfg)
 }
 
 func main() {
@@ -99,7 +105,10 @@ func main() {
 
 	// Exit when we see a signal
 	terminate := make(chan os.Signal, 1)
-	signal.Notify(terminate, os.Interrupt)
+	signal.Notify(terminate, os.Interrupt, syscall.SIGTERM)
 	<-terminate
 	beLog.Info("Shutting down gRPC server")
+
+	// Send any metrics the exporters are still holding before exiting.
+	metrics.Flush()
 }
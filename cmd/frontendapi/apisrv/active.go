@@ -0,0 +1,73 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package apisrv
+
+import (
+	"context"
+	"time"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	playerq "github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/playerq"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultActivePlayersInterval is how often, in seconds, the active player
+// gauge is recorded when 'activePlayers.interval' is not set.
+const defaultActivePlayersInterval = 10
+
+// recordActivePlayers periodically records the number of players in
+// matchmaking to FeActivePlayers, until ctx is cancelled.  Every Frontend API
+// instance records the same shared count.
+func (s *FrontendAPI) recordActivePlayers(ctx context.Context) {
+	interval := defaultActivePlayersInterval
+	if s.cfg.IsSet("activePlayers.interval") && s.cfg.GetInt("activePlayers.interval") > 0 {
+		interval = s.cfg.GetInt("activePlayers.interval")
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	key := playerq.ActivePlayersKey(s.cfg)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := s.countActivePlayers(ctx, key)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.log.WithFields(log.Fields{
+						"error":     err.Error(),
+						"component": "statestorage",
+					}).Error("Unable to count active players")
+				}
+				continue
+			}
+			metrics.Record(ctx, FeActivePlayers.M(count))
+		}
+	}
+}
+
+// countActivePlayers reads the number of players in the set at key.
+func (s *FrontendAPI) countActivePlayers(ctx context.Context, key string) (int64, error) {
+	redisConn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer redisConn.Close()
+	return playerq.CountActive(redisConn, key)
+}
@@ -21,15 +21,26 @@ package apisrv
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	frontend "github.com/GoogleCloudPlatform/open-match/cmd/frontendapi/proto"
+	"github.com/GoogleCloudPlatform/open-match/internal/grpcutil"
 	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	"github.com/GoogleCloudPlatform/open-match/internal/playerid"
+	"github.com/GoogleCloudPlatform/open-match/internal/retrybudget"
+	redisHelpers "github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/assignment"
 	playerq "github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/playerq"
 	log "github.com/sirupsen/logrus"
-	"go.opencensus.io/stats"
 	"go.opencensus.io/tag"
 
 	"github.com/gomodule/redigo/redis"
@@ -37,17 +48,48 @@ import (
 
 	"go.opencensus.io/plugin/ocgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
-// Logrus structured logging setup
-var (
-	feLogFields = log.Fields{
-		"app":       "openmatch",
-		"component": "frontend",
-		"caller":    "frontendapi/apisrv/apisrv.go",
-	}
-	feLog = log.WithFields(feLogFields)
-)
+// Logrus structured logging setup.  Each FrontendAPI logs through its own
+// entry with these fields, from which per-request loggers are derived.
+var feLogFields = log.Fields{
+	"app":       "openmatch",
+	"component": "frontend",
+	"caller":    "frontendapi/apisrv/apisrv.go",
+}
+
+// ErrPollLimitReached is returned by GetAssignment when the watcher gave up
+// after making 'assignments.maxPolls' queries to state storage without
+// finding an assignment.
+var ErrPollLimitReached = errors.New("poll limit reached before matchmaking results appeared in redis")
+
+func init() {
+	grpcutil.RegisterErrorCode("pollLimitReached", ErrPollLimitReached, codes.DeadlineExceeded)
+}
+
+// watchResult is what a watcher found: the fields of the player's record once
+// it holds an assignment, or why the watcher gave up.
+type watchResult struct {
+	fields map[string]string
+	err    error
+}
+
+// defaultAssignmentTimeout is how long, in seconds, GetAssignment waits for an
+// assignment when 'assignments.timeout' is not set.
+const defaultAssignmentTimeout = 30
+
+// defaultRetryAfter is the retry hint, in seconds, GetAssignment returns on
+// timeout when 'assignments.timeoutMode' is 'retry' and
+// 'assignments.retryAfter' is not set.
+const defaultRetryAfter = 5
+
+// watcherLifetimeGrace is how many seconds longer than its callers wait for
+// it a watcher goroutine may run, when 'assignments.watcherMaxLifetime' is
+// not set, before it's terminated as leaked.
+const watcherLifetimeGrace = 10
 
 // FrontendAPI implements frontend.ApiServer, the server generated by compiling
 // the protobuf, by fulfilling the frontend.APIClient interface.
@@ -55,105 +97,411 @@ type FrontendAPI struct {
 	grpc *grpc.Server
 	cfg  *viper.Viper
 	pool *redis.Pool
+
+	// Logger shared by every request; never reassigned after New, so derive
+	// per-request loggers from it with WithFields.
+	log *log.Entry
+
+	// Stops the keyspace expiry notification watcher, if it was started.
+	stopExpirations context.CancelFunc
+	// Stops the expired player index purge, if it was started.
+	stopPurge context.CancelFunc
+	// Stops recording the active player gauge, if it was started.
+	stopActivePlayers context.CancelFunc
+
+	// Wakes assignment watchers when a player's record is written, if
+	// 'assignments.notifications' is set; nil otherwise.
+	assignmentEvents     *redisHelpers.KeyEventHub
+	stopAssignmentEvents context.CancelFunc
+
+	// Bounded queue of state storage writes for the write workers.
+	writes chan writeJob
+
+	// Channels of in-flight assignment watchers, keyed by player ID and
+	// closed by DeleteAssignment so the watchers stop promptly.
+	watchers   map[string]map[chan struct{}]struct{}
+	watchersMu sync.Mutex
+
+	// Number of running watcher goroutines, reported with leaks.
+	liveWatchers int64
 }
 type frontendAPI FrontendAPI
 
 // New returns an instantiated srvice
 func New(cfg *viper.Viper, pool *redis.Pool) *FrontendAPI {
+	opts := []grpc.ServerOption{grpc.StatsHandler(&ocgrpc.ServerHandler{})}
+	opts = append(opts, grpcutil.ServerOptions(cfg, "frontend")...)
+
 	s := FrontendAPI{
 		pool: pool,
-		grpc: grpc.NewServer(grpc.StatsHandler(&ocgrpc.ServerHandler{})),
+		grpc: grpc.NewServer(opts...),
 		cfg:  cfg,
+		log:  log.WithFields(feLogFields),
+
+		watchers: make(map[string]map[chan struct{}]struct{}),
 	}
 
 	// Add a hook to the logger to auto-count log lines for metrics output thru OpenCensus
 	log.AddHook(metrics.NewHook(FeLogLines, KeySeverity))
 
+	s.startWriteWorkers()
+
+	// A watcher mustn't be terminated while a caller still waits for it.
+	if lifetime, wait := cfg.GetInt("assignments.watcherMaxLifetime"), (*frontendAPI)(&s).longestAssignmentWait(); lifetime > 0 && lifetime <= wait {
+		s.log.WithFields(log.Fields{
+			"watcherMaxLifetime": lifetime,
+			"longestWait":        wait,
+		}).Warn("assignments.watcherMaxLifetime is not longer than the longest assignment wait, using the default")
+	}
+
 	// Register gRPC server
 	frontend.RegisterAPIServer(s.grpc, (*frontendAPI)(&s))
-	feLog.Info("Successfully registered gRPC server")
+	s.log.Info("Successfully registered gRPC server")
 	return &s
 }
 
-// Open starts the api grpc service listening on the configured port.
+// Open starts the api grpc service listening on the configured address, or
+// on the configured port on all interfaces if no address is set.
 func (s *FrontendAPI) Open() error {
-	ln, err := net.Listen("tcp", ":"+s.cfg.GetString("api.frontend.port"))
+	network, address := listenAddress(s.cfg)
+	if network == "unix" {
+		// Clean up a socket left behind by a previous run.
+		if err := os.Remove(address); err != nil && !os.IsNotExist(err) {
+			s.log.WithFields(log.Fields{
+				"error":   err.Error(),
+				"address": address,
+			}).Error("Unable to remove stale unix socket")
+			return err
+		}
+	}
+
+	ln, err := net.Listen(network, address)
 	if err != nil {
-		feLog.WithFields(log.Fields{
-			"error": err.Error(),
-			"port":  s.cfg.GetInt("api.frontend.port"),
+		s.log.WithFields(log.Fields{
+			"error":   err.Error(),
+			"network": network,
+			"address": address,
 		}).Error("net.Listen() error")
 		return err
 	}
-	feLog.WithFields(log.Fields{"port": s.cfg.GetInt("api.frontend.port")}).Info("TCP net listener initialized")
+	s.log.WithFields(log.Fields{
+		"network": network,
+		"address": address,
+	}).Info("Net listener initialized")
 
+	// Count players that expire out of the queue without being matched, and
+	// stop counting them as active.
+	if s.cfg.GetBool("players.expiryEvents") || playerq.ActivePlayersKey(s.cfg) != "" {
+		var ctx context.Context
+		ctx, s.stopExpirations = context.WithCancel(context.Background())
+		go s.watchExpirations(ctx)
+	}
+
+	// Share one subscription to hash writes among all assignment watchers.
+	if s.cfg.GetBool("assignments.notifications") {
+		s.enableKeyspaceEvents("Eh")
+		var ctx context.Context
+		ctx, s.stopAssignmentEvents = context.WithCancel(context.Background())
+		s.assignmentEvents = redisHelpers.NewKeyEventHub(s.pool, "hset")
+		go s.assignmentEvents.Run(ctx)
+	}
+
+	// Report how many players are in matchmaking.
+	if playerq.ActivePlayersKey(s.cfg) != "" {
+		var ctx context.Context
+		ctx, s.stopActivePlayers = context.WithCancel(context.Background())
+		go s.recordActivePlayers(ctx)
+	}
+
+	// Remove expired players from the indices.
+	if ttl, err := playerq.TTLFromConfig(s.cfg); err == nil && ttl.Expires() {
+		var ctx context.Context
+		ctx, s.stopPurge = context.WithCancel(context.Background())
+		go s.purgeExpiredIndices(ctx)
+	}
+
+	s.Serve(ln)
+	return nil
+}
+
+// listenAddress returns the network and address to listen on from the
+// 'api.frontend.address' config, which can be either 'host:port' or
+// 'unix:///path/to/socket'.  If it isn't set, all interfaces are bound on
+// 'api.frontend.port'.
+func listenAddress(cfg *viper.Viper) (network string, address string) {
+	addr := cfg.GetString("api.frontend.address")
+	switch {
+	case addr == "":
+		return "tcp", ":" + cfg.GetString("api.frontend.port")
+	case strings.HasPrefix(addr, "unix://"):
+		return "unix", strings.TrimPrefix(addr, "unix://")
+	default:
+		return "tcp", addr
+	}
+}
+
+// Serve starts the api grpc service on the provided listener.  Open() calls
+// this with a TCP listener on the configured port; tests can pass an
+// in-memory listener instead.
+func (s *FrontendAPI) Serve(ln net.Listener) {
 	go func() {
 		err := s.grpc.Serve(ln)
 		if err != nil {
-			feLog.WithFields(log.Fields{"error": err.Error()}).Error("gRPC serve() error")
+			s.log.WithFields(log.Fields{"error": err.Error()}).Error("gRPC serve() error")
 		}
-		feLog.Info("serving gRPC endpoints")
+		s.log.Info("serving gRPC endpoints")
 	}()
+}
 
-	return nil
+// Stop immediately stops the api grpc service and closes all open connections.
+func (s *FrontendAPI) Stop() {
+	if s.stopExpirations != nil {
+		s.stopExpirations()
+	}
+	if s.stopPurge != nil {
+		s.stopPurge()
+	}
+	if s.stopAssignmentEvents != nil {
+		s.stopAssignmentEvents()
+	}
+	if s.stopActivePlayers != nil {
+		s.stopActivePlayers()
+	}
+	s.grpc.Stop()
 }
 
 // CreateRequest is this service's implementation of the CreateRequest gRPC method // defined in ../proto/frontend.proto
 func (s *frontendAPI) CreateRequest(c context.Context, g *frontend.Group) (*frontend.Result, error) {
 
-	// Get redis connection from pool
-	redisConn := s.pool.Get()
-	defer redisConn.Close()
-
 	// Create context for tagging OpenCensus metrics.
 	funcName := "CreateRequest"
 	fnCtx, _ := tag.New(c, tag.Insert(KeyMethod, funcName))
 
+	g.Id = playerid.Normalize(s.cfg, g.Id)
+
+	// Fill in any server-side default properties the client didn't send.
+	properties, err := defaultProperties(s.cfg, g.Properties)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error":    err.Error(),
+			"playerid": g.Id,
+		}).Error("Unable to apply default properties")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return &frontend.Result{Success: false, Error: err.Error()}, err
+	}
+	// Reject players missing an attribute profiles filter on, which would
+	// otherwise wait in the queue without ever being matched.
+	if err := requireAttributes(s.cfg, properties); err != nil {
+		s.log.WithFields(log.Fields{
+			"error":    err.Error(),
+			"playerid": g.Id,
+		}).Warn("Player is missing required attributes")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return &frontend.Result{Success: false, Error: err.Error()}, err
+	}
+
+	layout, err := playerq.LayoutFromConfig(s.cfg)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Invalid properties storage config")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return &frontend.Result{Success: false, Error: err.Error()}, err
+	}
+	scoring, err := playerq.ScoringFromConfig(s.cfg)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Invalid player scoring config")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return &frontend.Result{Success: false, Error: err.Error()}, err
+	}
+	ttl, err := playerq.TTLFromConfig(s.cfg)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Invalid player TTL config")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return &frontend.Result{Success: false, Error: err.Error()}, err
+	}
+
 	// Write group
 	// TODO: Remove playerq module and just use redishelper module once
 	// indexing has its own implementation
-	err := playerq.Create(redisConn, g.Id, g.Properties)
+	activeKey := playerq.ActivePlayersKey(s.cfg)
+	err = s.queueWrite(c, func(redisConn redis.Conn) error {
+		if err := playerq.CreateWithTTL(redisConn, g.Id, properties, ttl, layout, scoring); err != nil {
+			return err
+		}
+		return playerq.AddActive(redisConn, activeKey, []string{g.Id})
+	})
+
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage error")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return &frontend.Result{Success: false, Error: err.Error()}, err
+	}
+
+	// Let the client know when its queue entry will expire, so it can
+	// re-enqueue before that happens.
+	result := &frontend.Result{Success: true, Error: ""}
+	if seconds := ttl.TTL(properties); seconds > 0 {
+		result.TtlSeconds = int64(seconds)
+		result.ExpiresAt = time.Now().Unix() + int64(seconds)
+	}
+
+	metrics.Record(fnCtx, FeGrpcRequests.M(1))
+	return result, err
+
+}
+
+// UpdateRequest is this service's implementation of the UpdateRequest gRPC method defined in
+// frontendapi/proto/frontend.proto
+func (s *frontendAPI) UpdateRequest(c context.Context, g *frontend.Group) (*frontend.Result, error) {
+
+	// Create context for tagging OpenCensus metrics.
+	funcName := "UpdateRequest"
+	fnCtx, _ := tag.New(c, tag.Insert(KeyMethod, funcName))
+
+	g.Id = playerid.Normalize(s.cfg, g.Id)
+
+	scoring, err := playerq.ScoringFromConfig(s.cfg)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Invalid player scoring config")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return &frontend.Result{Success: false, Error: err.Error()}, err
+	}
 
+	// Update group
+	err = s.queueWrite(c, func(redisConn redis.Conn) error {
+		return playerq.Update(c, redisConn, g.Id, g.Properties, scoring)
+	})
 	if err != nil {
-		feLog.WithFields(log.Fields{
+		s.log.WithFields(log.Fields{
 			"error":     err.Error(),
 			"component": "statestorage",
+			"playerid":  g.Id,
 		}).Error("State storage error")
 
-		stats.Record(fnCtx, FeGrpcErrors.M(1))
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
 		return &frontend.Result{Success: false, Error: err.Error()}, err
 	}
 
-	stats.Record(fnCtx, FeGrpcRequests.M(1))
+	metrics.Record(fnCtx, FeGrpcRequests.M(1))
 	return &frontend.Result{Success: true, Error: ""}, err
+}
+
+// RefreshRequest is this service's implementation of the RefreshRequest gRPC method defined in
+// frontendapi/proto/frontend.proto
+func (s *frontendAPI) RefreshRequest(c context.Context, p *frontend.PlayerId) (*frontend.Result, error) {
+	// Create context for tagging OpenCensus metrics.
+	funcName := "RefreshRequest"
+	fnCtx, _ := tag.New(c, tag.Insert(KeyMethod, funcName))
 
+	p.Id = playerid.Normalize(s.cfg, p.Id)
+
+	ttl, err := playerq.TTLFromConfig(s.cfg)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Invalid player TTL config")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return &frontend.Result{Success: false, Error: err.Error()}, err
+	}
+
+	// Get redis connection from pool, waiting no longer than the client will.
+	redisConn, err := grpcutil.GetConn(c, s.pool)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage connection error")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return &frontend.Result{Success: false, Error: err.Error()}, err
+	}
+	defer redisConn.Close()
+
+	seconds, err := playerq.Refresh(redisConn, p.Id, ttl)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+			"playerid":  p.Id,
+		}).Error("State storage error")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return &frontend.Result{Success: false, Error: err.Error()}, err
+	}
+
+	result := &frontend.Result{Success: true, Error: ""}
+	if seconds > 0 {
+		result.TtlSeconds = int64(seconds)
+		result.ExpiresAt = time.Now().Unix() + int64(seconds)
+	}
+
+	metrics.Record(fnCtx, FeGrpcRequests.M(1))
+	return result, err
 }
 
 // DeleteRequest is this service's implementation of the DeleteRequest gRPC method defined in
 // frontendapi/proto/frontend.proto
 func (s *frontendAPI) DeleteRequest(c context.Context, g *frontend.Group) (*frontend.Result, error) {
-	// Get redis connection from pool
-	redisConn := s.pool.Get()
-	defer redisConn.Close()
-
 	// Create context for tagging OpenCensus metrics.
 	funcName := "DeleteRequest"
 	fnCtx, _ := tag.New(c, tag.Insert(KeyMethod, funcName))
 
-	// Write group
-	err := playerq.Delete(redisConn, g.Id)
+	g.Id = playerid.Normalize(s.cfg, g.Id)
+
+	// Get redis connection from pool, waiting no longer than the client will.
+	redisConn, err := grpcutil.GetConn(c, s.pool)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage connection error")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return &frontend.Result{Success: false, Error: err.Error()}, err
+	}
+	defer redisConn.Close()
+
+	// Write group. If soft deletes are configured, leave a tombstoned record
+	// behind for a while instead of deleting it outright.
+	var removed int64
+	if s.cfg.GetBool("players.softDelete") {
+		removed, err = playerq.SoftDelete(redisConn, g.Id, s.cfg.GetInt("players.tombstoneTTL"))
+	} else {
+		removed, err = playerq.Delete(redisConn, g.Id)
+	}
+	if err == nil {
+		err = playerq.RemoveActive(redisConn, playerq.ActivePlayersKey(s.cfg), []string{g.Id})
+	}
 	if err != nil {
-		feLog.WithFields(log.Fields{
+		s.log.WithFields(log.Fields{
 			"error":     err.Error(),
 			"component": "statestorage",
 		}).Error("State storage error")
 
-		stats.Record(fnCtx, FeGrpcErrors.M(1))
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
 		return &frontend.Result{Success: false, Error: err.Error()}, err
 	}
 
-	stats.Record(fnCtx, FeGrpcRequests.M(1))
-	return &frontend.Result{Success: true, Error: ""}, err
+	metrics.Record(fnCtx, FeGrpcRequests.M(1))
+	return &frontend.Result{Success: true, Error: "", RemovedCount: removed}, err
 
 }
 
@@ -168,18 +516,85 @@ func (s *frontendAPI) GetAssignment(c context.Context, p *frontend.PlayerId) (*f
 	funcName := "GetAssignment"
 	fnCtx, _ := tag.New(ctx, tag.Insert(KeyMethod, funcName))
 
+	p.Id = playerid.Normalize(s.cfg, p.Id)
+
+	// Assignments are read with the configured codec.
+	codec, err := assignment.NewCodec(s.cfg)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error":    err.Error(),
+			"playerid": p.Id,
+		}).Error("Assignment codec error")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return &frontend.ConnectionInfo{ConnectionString: ""}, err
+	}
+
+	// Stop waiting if the player's assignment is deleted.
+	deleted := make(chan struct{})
+	s.trackWatcher(p.Id, deleted)
+	defer s.untrackWatcher(p.Id, deleted)
+
 	// get and return connection string
-	var connString string
-	watchChan := s.watcher(ctx, s.pool, p.Id) // watcher() runs the appropriate Redis commands.
+	var fields map[string]string
+	watchChan := s.watcher(ctx, s.pool, codec, p.Id) // watcher() runs the appropriate Redis commands.
+
+	// Optionally tell the client the watch has begun, so it can show that
+	// matchmaking is under way before the assignment arrives.
+	if s.cfg.GetBool("assignments.watchHeader") {
+		s.sendWatchHeader(ctx, p.Id)
+	}
+
+	timeout := defaultAssignmentTimeout
+	if s.cfg.IsSet("assignments.timeout") && s.cfg.GetInt("assignments.timeout") > 0 {
+		timeout = s.cfg.GetInt("assignments.timeout")
+	}
 
 	select {
-	case <-time.After(30 * time.Second): // TODO: Make this configurable.
-		err := errors.New("did not see matchmaking results in redis before timeout")
+	case <-time.After(time.Duration(timeout) * time.Second):
+		// Optionally tell a player claimed for a match apart from one that
+		// isn't in matchmaking at all.
+		if mode := s.cfg.GetString("assignments.matchPending"); mode == matchPendingResponse || mode == matchPendingStatus {
+			if ci, err := s.matchPendingResult(ctx, p.Id, mode); ci != nil || err != nil {
+				metrics.Record(fnCtx, FeGrpcRequests.M(1))
+				return ci, err
+			}
+		}
+
+		// Optionally tell the client to keep polling rather than failing.
+		if s.cfg.GetString("assignments.timeoutMode") == "retry" {
+			retryAfter := s.cfg.GetInt64("assignments.retryAfter")
+			if retryAfter <= 0 {
+				retryAfter = defaultRetryAfter
+			}
+			s.log.WithFields(log.Fields{
+				"playerid":   p.Id,
+				"retryAfter": retryAfter,
+			}).Debug("No assignment before timeout, asking client to retry")
+
+			metrics.Record(fnCtx, FeGrpcRequests.M(1))
+			return &frontend.ConnectionInfo{ConnectionString: "", RetryAfterSeconds: retryAfter}, nil
+		}
+
+		// Optionally send the player somewhere useful, e.g. a lobby server,
+		// rather than failing.
+		if fallback := s.cfg.GetString("assignments.fallbackConnstring"); fallback != "" {
+			s.log.WithFields(log.Fields{
+				"playerid": p.Id,
+			}).Debug("No assignment before timeout, returning fallback connection string")
+
+			metrics.Record(fnCtx, FeGrpcRequests.M(1))
+			return &frontend.ConnectionInfo{ConnectionString: fallback, Fallback: true}, nil
+		}
+
+		// Return no ConnectionInfo at all, so a client that ignores the
+		// error can't mistake an empty connection string for an assignment.
+		err := status.Errorf(codes.DeadlineExceeded, "no assignment for player %v within %v seconds", p.Id, timeout)
 		// TODO:Timeout: deal with the fallout
 		// When there is a timeout, need to send a stop to the watch channel.
 		// cancelling ctx isn't doing it.
 		//cancel()
-		feLog.WithFields(log.Fields{
+		s.log.WithFields(log.Fields{
 			"error":     err.Error(),
 			"component": "statestorage",
 			"playerid":  p.Id,
@@ -187,43 +602,264 @@ func (s *frontendAPI) GetAssignment(c context.Context, p *frontend.PlayerId) (*f
 
 		errTag, _ := tag.NewKey("errtype")
 		fnCtx, _ := tag.New(ctx, tag.Insert(errTag, "watch_timeout"))
-		stats.Record(fnCtx, FeGrpcErrors.M(1))
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return nil, err
+
+	case <-c.Done():
+		// The client went away; don't count this as a failure to find an
+		// assignment.
+		return &frontend.ConnectionInfo{ConnectionString: ""}, s.clientCancelled(fnCtx, c, p.Id)
+
+	case <-deleted:
+		s.log.WithFields(log.Fields{
+			"playerid": p.Id,
+		}).Debug("Assignment deleted while waiting for it")
+
+		metrics.Record(fnCtx, FeGrpcRequests.M(1))
+		return nil, status.Errorf(codes.NotFound, "assignment for player %v was deleted", p.Id)
+
+	case r, ok := <-watchChan:
+		if !ok && c.Err() != nil {
+			// The watcher stopped without a result because the client went
+			// away.
+			return &frontend.ConnectionInfo{ConnectionString: ""}, s.clientCancelled(fnCtx, c, p.Id)
+		}
+		if !ok {
+			r.err = status.Error(codes.Internal, "assignment watcher stopped without a result")
+		}
+		if r.err != nil {
+			// The watcher hit its poll limit, gave up on state storage, or
+			// was terminated.
+			err := r.err
+			s.log.WithFields(log.Fields{
+				"error":     err.Error(),
+				"component": "statestorage",
+				"playerid":  p.Id,
+			}).Error("State storage error")
+
+			metrics.Record(fnCtx, FeGrpcErrors.M(1))
+			return &frontend.ConnectionInfo{ConnectionString: ""}, err
+		}
+		fields = r.fields
+	}
+
+	// Decode the connection info as it was stored by the Backend API.
+	ci, err := codec.Decode(fields)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error":    err.Error(),
+			"playerid": p.Id,
+		}).Error("Connection string decoding error")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
 		return &frontend.ConnectionInfo{ConnectionString: ""}, err
+	}
 
-	case connString = <-watchChan:
-		feLog.Debug(p.Id, "connString:", connString)
+	recordAssignmentLatency(fnCtx, fields)
+	metrics.Record(fnCtx, FeGrpcRequests.M(1))
+	return &frontend.ConnectionInfo{ConnectionString: ci.ConnectionString, Metadata: ci.Metadata}, nil
+}
+
+// Values for 'assignments.matchPending', which controls how GetAssignment
+// responds when it times out for a player who has been claimed for a match,
+// by an MMF proposal or by being deindexed, but not yet assigned.  When it's
+// set, players who aren't in matchmaking at all get a NotFound error.
+const (
+	// matchPendingResponse returns ConnectionInfo with match_pending set.
+	matchPendingResponse = "response"
+	// matchPendingStatus returns an Unavailable error, which clients retry.
+	matchPendingStatus = "status"
+)
+
+// matchPendingResult returns GetAssignment's result for a player it found no
+// assignment for in time: a match pending response or error if they've been
+// claimed for a match, a NotFound error if they have no record, and neither
+// if they're still waiting to be matched, or state storage couldn't say.
+func (s *frontendAPI) matchPendingResult(ctx context.Context, playerID string, mode string) (*frontend.ConnectionInfo, error) {
+	redisConn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+			"playerid":  playerID,
+		}).Error("State storage error")
+		return nil, nil
 	}
+	defer redisConn.Close()
 
-	stats.Record(fnCtx, FeGrpcRequests.M(1))
-	return &frontend.ConnectionInfo{ConnectionString: connString}, nil
+	found, pending, err := matchPending(redisConn, playerID)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+			"playerid":  playerID,
+		}).Error("State storage error checking for a pending match")
+		return nil, nil
+	}
+	switch {
+	case !found:
+		return nil, status.Errorf(codes.NotFound, "player %v is not in matchmaking", playerID)
+	case !pending:
+		return nil, nil
+	case mode == matchPendingStatus:
+		return nil, status.Errorf(codes.Unavailable, "match in progress for player %v, assignment pending", playerID)
+	}
+
+	retryAfter := s.cfg.GetInt64("assignments.retryAfter")
+	if retryAfter <= 0 {
+		retryAfter = defaultRetryAfter
+	}
+	return &frontend.ConnectionInfo{ConnectionString: "", RetryAfterSeconds: retryAfter, MatchPending: true}, nil
+}
+
+// matchPending reports whether the player's record exists, and whether
+// they've been claimed for a match: they're on the proposed or deindexed
+// ignorelist.  It's only meaningful for a player without an assignment.
+func matchPending(redisConn redis.Conn, playerID string) (found bool, pending bool, err error) {
+	redisConn.Send("MULTI")
+	redisConn.Send("EXISTS", playerID)
+	redisConn.Send("ZSCORE", "proposed", playerID)
+	redisConn.Send("ZSCORE", "deindexed", playerID)
+	replies, err := redis.Values(redisConn.Do("EXEC"))
+	if err != nil {
+		return false, false, err
+	}
+	if found, err = redis.Bool(replies[0], nil); err != nil {
+		return false, false, err
+	}
+	// Ignorelists that don't hold the player reply with nil.
+	pending = found && (replies[1] != nil || replies[2] != nil)
+	return found, pending, nil
+}
+
+// Header metadata keys GetAssignment sends when 'assignments.watchHeader' is
+// set, as soon as it starts watching for the player's assignment.
+const (
+	// WatchHeaderKey is always "started".
+	WatchHeaderKey = "x-openmatch-watch"
+	// PlayerFoundHeaderKey is "true" if the player's record exists when the
+	// watch starts, and "false" if they aren't queued or assigned, in which
+	// case the client may want to enqueue them again.
+	PlayerFoundHeaderKey = "x-openmatch-player-found"
+)
+
+// sendWatchHeader sends the GetAssignment response header for a watch on
+// playerID.  Failures are logged, as the header is only informational.
+func (s *frontendAPI) sendWatchHeader(ctx context.Context, playerID string) {
+	found := false
+	redisConn, err := s.pool.GetContext(ctx)
+	if err == nil {
+		found, err = redis.Bool(redisConn.Do("EXISTS", playerID))
+		redisConn.Close()
+	}
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+			"playerid":  playerID,
+		}).Warn("Failed to check player record for watch header")
+	}
+
+	md := metadata.Pairs(WatchHeaderKey, "started", PlayerFoundHeaderKey, strconv.FormatBool(found))
+	if err := grpc.SendHeader(ctx, md); err != nil {
+		s.log.WithFields(log.Fields{
+			"error":    err.Error(),
+			"playerid": playerID,
+		}).Warn("Failed to send watch header")
+	}
+}
+
+// recordAssignmentLatency records how long ago the Backend API wrote the
+// assignment in fields, which includes any lag from the watcher's polling.
+// Assignments written before the Backend API recorded the time are skipped.
+func recordAssignmentLatency(ctx context.Context, fields map[string]string) {
+	assignedAt, err := strconv.ParseInt(fields[assignment.AssignedAtField], 10, 64)
+	if err != nil {
+		return
+	}
+	latency := time.Now().UnixNano()/int64(time.Millisecond) - assignedAt
+	if latency < 0 {
+		// The two hosts' clocks disagree.
+		latency = 0
+	}
+	metrics.Record(ctx, FeAssignmentLatencyMs.M(latency))
+}
+
+// clientCancelled records that the client abandoned a call before it
+// finished, and returns the matching gRPC status error: codes.Canceled if the
+// client cancelled, or codes.DeadlineExceeded if its deadline passed.
+func (s *frontendAPI) clientCancelled(fnCtx context.Context, c context.Context, playerID string) error {
+	code := codes.Canceled
+	if c.Err() == context.DeadlineExceeded {
+		code = codes.DeadlineExceeded
+	}
+	s.log.WithFields(log.Fields{
+		"error":    c.Err().Error(),
+		"playerid": playerID,
+	}).Debug("Client ended call before a result was ready")
+
+	metrics.Record(fnCtx, FeClientCancellations.M(1))
+	return status.Error(code, c.Err().Error())
 }
 
 // DeleteAssignment is this service's implementation of the DeleteAssignment gRPC method defined in
 // frontendapi/proto/frontend.proto
 func (s *frontendAPI) DeleteAssignment(c context.Context, p *frontend.PlayerId) (*frontend.Result, error) {
 
-	// Get redis connection from pool
-	redisConn := s.pool.Get()
-	defer redisConn.Close()
-
 	// Create context for tagging OpenCensus metrics.
 	funcName := "DeleteAssignment"
 	fnCtx, _ := tag.New(c, tag.Insert(KeyMethod, funcName))
 
+	p.Id = playerid.Normalize(s.cfg, p.Id)
+
+	// Get redis connection from pool, waiting no longer than the client will.
+	redisConn, err := grpcutil.GetConn(c, s.pool)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage connection error")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return &frontend.Result{Success: false, Error: err.Error()}, err
+	}
+	defer redisConn.Close()
+
 	// Write group
-	err := playerq.Delete(redisConn, p.Id)
+	removed, err := playerq.Delete(redisConn, p.Id)
 	if err != nil {
-		feLog.WithFields(log.Fields{
+		s.log.WithFields(log.Fields{
 			"error":     err.Error(),
 			"component": "statestorage",
 		}).Error("State storage error")
 
-		stats.Record(fnCtx, FeGrpcErrors.M(1))
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
 		return &frontend.Result{Success: false, Error: err.Error()}, err
 	}
 
-	stats.Record(fnCtx, FeGrpcRequests.M(1))
-	return &frontend.Result{Success: true, Error: ""}, err
+	// Stop any GetAssignment calls still waiting on this player.
+	if n := s.notifyWatchers(p.Id); n > 0 {
+		s.log.WithFields(log.Fields{
+			"playerid": p.Id,
+			"watchers": n,
+		}).Debug("Stopped assignment watchers for deleted player")
+	}
+
+	// The player collected their assignment, so the backend mustn't report
+	// it as expired.
+	if s.cfg.GetBool("assignments.expiry.enabled") {
+		_, err = redisConn.Do("HDEL", s.cfg.GetString("assignments.expiry.index"), p.Id)
+		if err != nil {
+			s.log.WithFields(log.Fields{
+				"error":     err.Error(),
+				"component": "statestorage",
+			}).Warn("Unable to remove assignment from the expiry index")
+			err = nil
+		}
+	}
+
+	metrics.Record(fnCtx, FeGrpcRequests.M(1))
+	return &frontend.Result{Success: true, Error: "", RemovedCount: removed}, err
 
 }
 
@@ -232,56 +868,189 @@ func (s *frontendAPI) DeleteAssignment(c context.Context, p *frontend.PlayerId)
 // ================================================
 
 // watcher makes a channel and returns it immediately.  It also launches an
-// asynchronous goroutine that watches a redis key and returns the value of
-// the 'connstring' field of that key once it exists on the channel.
+// asynchronous goroutine that watches a redis key and returns all its fields
+// on the channel once codec finds an assignment in them.  If
+// 'assignments.maxPolls' is set, the goroutine gives up after querying redis
+// that many times and sends ErrPollLimitReached instead.  If
+// 'assignments.maxConsecutiveFailures' is set, it gives up after that many
+// queries in a row fail to reach redis, and sends a codes.Unavailable error,
+// rather than keep polling a state storage that is down; each poll after a
+// failure also spends the retry budget in ctx, if any.  When ctx is
+// cancelled the channel is closed without sending a value.  If
+// 'assignments.notifications' is set, the goroutine also polls as soon as the
+// player's record is written, rather than only every few seconds.  A
+// goroutine still running after watcherMaxLifetime is terminated as leaked,
+// sending a codes.Aborted error before closing the channel.
 //
 // The pattern for this function is from 'Go Concurrency Patterns', it is a function
 // that wraps a closure goroutine, and returns a channel.
 // reference: https://talks.golang.org/2012/concurrency.slide#25
-func (s *frontendAPI) watcher(ctx context.Context, pool *redis.Pool, key string) <-chan string {
+func (s *frontendAPI) watcher(ctx context.Context, pool *redis.Pool, codec assignment.AssignmentCodec, key string) <-chan watchResult {
 	// Add the key as a field to all logs for the execution of this function.
-	feLog = feLog.WithFields(log.Fields{"key": key})
-	feLog.Debug("Watching key in statestorage for changes")
+	wLog := s.log.WithFields(log.Fields{"key": key})
+	wLog.Debug("Watching key in statestorage for changes")
 
-	watchChan := make(chan string)
+	watchChan := make(chan watchResult, 1)
+
+	// Bound the goroutine's lifetime, so a bug that keeps it polling after
+	// every caller has stopped waiting can't leak it forever.
+	ctx, cancel := context.WithCancel(ctx)
+	lifetime := s.watcherMaxLifetime()
+	var terminated int32
+	leaked := time.AfterFunc(lifetime, func() {
+		wLog.WithFields(log.Fields{
+			"lifetime": lifetime.String(),
+			"running":  atomic.LoadInt64(&s.liveWatchers),
+		}).Error("Watcher outlived its maximum lifetime, terminating it as leaked")
+		metrics.Record(context.Background(), FeWatcherTerminations.M(1))
+		atomic.StoreInt32(&terminated, 1)
+		cancel()
+	})
+	atomic.AddInt64(&s.liveWatchers, 1)
+
+	// stop closes the channel once ctx is done, first telling the caller if
+	// the watcher was terminated rather than its caller having gone away.
+	stop := func() {
+		if atomic.LoadInt32(&terminated) == 1 {
+			watchChan <- watchResult{err: status.Error(codes.Aborted, "assignment watcher terminated")}
+		}
+		close(watchChan)
+	}
 
 	go func() {
+		defer atomic.AddInt64(&s.liveWatchers, -1)
+		defer cancel()
+		defer leaked.Stop()
+
 		// var declaration
-		var results string
+		var results map[string]string
 		var err = errors.New("haven't queried Redis yet")
+		maxPolls := s.cfg.GetInt("assignments.maxPolls")
+		polls := 0
+		maxFailures := s.cfg.GetInt("assignments.maxConsecutiveFailures")
+		failures := 0
+
+		// Registered before the first poll, so a write between that poll
+		// and waiting for the next one isn't missed.  Without notifications
+		// events stays nil and never fires.
+		var events <-chan struct{}
+		if s.assignmentEvents != nil {
+			var stop func()
+			events, stop = s.assignmentEvents.Wait(key)
+			defer stop()
+		}
+
+		// Assignments are rarely written right after the player is queued,
+		// so optionally wait before the first poll rather than spend it on
+		// a guaranteed miss.
+		if delay := s.cfg.GetInt("assignments.initialPollDelay"); delay > 0 {
+			select {
+			case <-ctx.Done():
+				stop()
+				return
+			case <-events:
+			case <-time.After(time.Duration(delay) * time.Millisecond):
+			}
+		}
 
 		// Loop, querying redis until this key has a value
 		for err != nil {
 			select {
 			case <-ctx.Done():
 				// Cleanup
-				close(watchChan)
+				stop()
 				return
 			default:
-				results, err = s.retrieveConnstring(ctx, pool, key, s.cfg.GetString("jsonkeys.connstring"))
+				results, err = s.retrieveAssignment(ctx, pool, codec, key)
+				polls++
+				if errors.Is(err, playerq.ErrStateStorageUnavailable) {
+					failures++
+				} else {
+					failures = 0
+				}
+				if maxFailures > 0 && failures >= maxFailures {
+					wLog.WithFields(log.Fields{
+						"error":    err.Error(),
+						"failures": failures,
+					}).Warn("Watcher giving up, state storage unreachable")
+					watchChan <- watchResult{err: status.Errorf(codes.Unavailable, "state storage unreachable: %v", err)}
+					close(watchChan)
+					return
+				}
+				// Polling again after a failure is a retry, so it's paid for
+				// from the request's retry budget.
+				if failures > 0 && retrybudget.Spend(ctx) != nil {
+					wLog.WithFields(log.Fields{
+						"error":    err.Error(),
+						"failures": failures,
+					}).Warn("Watcher giving up, retry budget exhausted")
+					watchChan <- watchResult{err: fmt.Errorf("state storage unreachable: %w", retrybudget.ErrExhausted)}
+					close(watchChan)
+					return
+				}
+				if err != nil && maxPolls > 0 && polls >= maxPolls {
+					wLog.WithFields(log.Fields{"polls": polls}).Debug("Watcher poll limit reached")
+					watchChan <- watchResult{err: ErrPollLimitReached}
+					close(watchChan)
+					return
+				}
 				if err != nil {
-					time.Sleep(5 * time.Second) // TODO: exp bo + jitter
+					select {
+					case <-ctx.Done():
+					case <-events:
+					case <-time.After(5 * time.Second): // TODO: exp bo + jitter
+					}
 				}
 			}
 		}
 		// Return value retreived from Redis asynchonously and tell calling function we're done
-		feLog.Debug("Statestorage watched record update detected")
-		watchChan <- results
+		wLog.Debug("Statestorage watched record update detected")
+		watchChan <- watchResult{fields: results}
 		close(watchChan)
 	}()
 
 	return watchChan
 }
 
-// retrieveConnstring is a concurrent-safe, context-aware redis HGET of the 'connstring' fieldin the input key
+// watcherMaxLifetime returns how long a watcher goroutine may run before it's
+// terminated as leaked: 'assignments.watcherMaxLifetime' seconds if that's
+// longer than the longest GetAssignment or an assignment webhook waits for
+// it, or a little longer than that wait otherwise.
+func (s *frontendAPI) watcherMaxLifetime() time.Duration {
+	wait := s.longestAssignmentWait()
+	if lifetime := s.cfg.GetInt("assignments.watcherMaxLifetime"); lifetime > wait {
+		return time.Duration(lifetime) * time.Second
+	}
+	return time.Duration(wait+watcherLifetimeGrace) * time.Second
+}
+
+// longestAssignmentWait returns how many seconds the longest GetAssignment or
+// assignment webhook waits for a watcher.
+func (s *frontendAPI) longestAssignmentWait() int {
+	wait := defaultAssignmentTimeout
+	if s.cfg.IsSet("assignments.timeout") && s.cfg.GetInt("assignments.timeout") > 0 {
+		wait = s.cfg.GetInt("assignments.timeout")
+	}
+	webhookWait := defaultWebhookWait
+	if s.cfg.IsSet("webhooks.assignmentWait") && s.cfg.GetInt("webhooks.assignmentWait") > 0 {
+		webhookWait = s.cfg.GetInt("webhooks.assignmentWait")
+	}
+	if webhookWait > wait {
+		wait = webhookWait
+	}
+	return wait
+}
+
+// retrieveAssignment is a concurrent-safe, context-aware redis HGETALL of the
+// input key, which returns an error until codec finds an assignment in it.
 // TODO: This will be moved to the redis statestorage module.
-func (s *frontendAPI) retrieveConnstring(ctx context.Context, pool *redis.Pool, key string, field string) (string, error) {
+func (s *frontendAPI) retrieveAssignment(ctx context.Context, pool *redis.Pool, codec assignment.AssignmentCodec, key string) (map[string]string, error) {
 
 	// Add the key as a field to all logs for the execution of this function.
-	feLog = feLog.WithFields(log.Fields{"key": key})
+	rLog := s.log.WithFields(log.Fields{"key": key})
 
-	cmd := "HGET"
-	feLog.WithFields(log.Fields{"query": cmd}).Debug("Statestorage operation")
+	cmd := "HGETALL"
+	rLog.WithFields(log.Fields{"query": cmd}).Debug("Statestorage operation")
 
 	// Get a connection to redis
 	redisConn, err := pool.GetContext(ctx)
@@ -289,12 +1058,116 @@ func (s *frontendAPI) retrieveConnstring(ctx context.Context, pool *redis.Pool,
 
 	// Encountered an issue getting a connection from the pool.
 	if err != nil {
-		feLog.WithFields(log.Fields{
+		rLog.WithFields(log.Fields{
 			"error": err.Error(),
 			"query": cmd}).Error("Statestorage connection error")
-		return "", err
+		return nil, playerq.WrapError("retrieve assignment", key, err)
 	}
 
 	// Run redis query and return
-	return redis.String(redisConn.Do("HGET", key, field))
+	results, err := redis.StringMap(redisConn.Do(cmd, key))
+	if err != nil {
+		return nil, playerq.WrapError("retrieve assignment", key, err)
+	}
+	if _, err := codec.Decode(results); err == assignment.ErrNoAssignment {
+		return nil, err
+	}
+	return results, nil
+}
+
+// defaultProperties merges the JSON object in the 'players.defaultProperties'
+// config value under the provided properties JSON; keys the client sent always
+// take precedence.  The template is configured as a JSON string rather than a
+// nested config object, as viper would otherwise lowercase its keys and split
+// keys containing dots (like 'ping.us-east') into nested objects.
+func defaultProperties(cfg *viper.Viper, properties string) (string, error) {
+	template := cfg.GetString("players.defaultProperties")
+	if template == "" {
+		return properties, nil
+	}
+
+	defaults := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(template), &defaults); err != nil {
+		return properties, errors.New("players.defaultProperties is not a valid JSON object: " + err.Error())
+	}
+	if len(defaults) == 0 {
+		return properties, nil
+	}
+
+	merged := make(map[string]interface{})
+	if properties != "" {
+		if err := json.Unmarshal([]byte(properties), &merged); err != nil {
+			return properties, fmt.Errorf("%w: properties is not a valid JSON object: %v", grpcutil.ErrInvalidArgument, err)
+		}
+	}
+	for k, v := range defaults {
+		if _, ok := merged[k]; !ok {
+			merged[k] = v
+		}
+	}
+
+	out, err := json.Marshal(merged)
+	if err != nil {
+		return properties, err
+	}
+	return string(out), nil
+}
+
+// requireAttributes returns an InvalidArgument error naming every attribute in
+// 'players.requiredAttributes' that the properties JSON doesn't have.
+func requireAttributes(cfg *viper.Viper, properties string) error {
+	required := cfg.GetStringSlice("players.requiredAttributes")
+	if len(required) == 0 {
+		return nil
+	}
+
+	present := make(map[string]json.RawMessage)
+	if err := json.Unmarshal([]byte(properties), &present); err != nil {
+		return status.Errorf(codes.InvalidArgument, "properties is not a valid JSON object: %v", err)
+	}
+	missing := make([]string, 0)
+	for _, attribute := range required {
+		if _, ok := present[attribute]; !ok {
+			missing = append(missing, attribute)
+		}
+	}
+	if len(missing) > 0 {
+		return status.Errorf(codes.InvalidArgument, "properties are missing required attributes: %v", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// trackWatcher registers the channel of an in-flight assignment watcher for
+// a player.
+func (s *frontendAPI) trackWatcher(playerID string, deleted chan struct{}) {
+	s.watchersMu.Lock()
+	defer s.watchersMu.Unlock()
+	if _, ok := s.watchers[playerID]; !ok {
+		s.watchers[playerID] = make(map[chan struct{}]struct{})
+	}
+	s.watchers[playerID][deleted] = struct{}{}
+}
+
+// untrackWatcher removes an assignment watcher once it has returned.
+func (s *frontendAPI) untrackWatcher(playerID string, deleted chan struct{}) {
+	s.watchersMu.Lock()
+	defer s.watchersMu.Unlock()
+	delete(s.watchers[playerID], deleted)
+	if len(s.watchers[playerID]) == 0 {
+		delete(s.watchers, playerID)
+	}
+}
+
+// notifyWatchers closes the channel of every assignment watcher for a player
+// and returns how many there were.  Only watchers on this Frontend API
+// instance are notified; those on other instances wait until they time out.
+func (s *frontendAPI) notifyWatchers(playerID string) int {
+	s.watchersMu.Lock()
+	defer s.watchersMu.Unlock()
+	n := len(s.watchers[playerID])
+	for deleted := range s.watchers[playerID] {
+		close(deleted)
+	}
+	delete(s.watchers, playerID)
+	return n
 }
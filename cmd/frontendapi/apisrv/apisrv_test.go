@@ -0,0 +1,446 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apisrv_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/open-match/cmd/frontendapi/apisrv"
+	frontend "github.com/GoogleCloudPlatform/open-match/cmd/frontendapi/proto"
+	"github.com/GoogleCloudPlatform/open-match/internal/testutil"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateAndDeleteRequest(t *testing.T) {
+	h := testutil.NewFrontendAPI(t, nil)
+	defer h.Close()
+
+	g := &frontend.Group{Id: "test1", Properties: `{"mmr.rating": 1200}`}
+	res, err := h.Client.CreateRequest(context.Background(), g)
+	if err != nil || !res.Success {
+		t.Fatalf("CreateRequest failed: %v %v", res, err)
+	}
+	if got := h.Miniredis.HGet("test1", "properties"); got != g.Properties {
+		t.Errorf("stored properties = %q, want %q", got, g.Properties)
+	}
+
+	res, err = h.Client.DeleteRequest(context.Background(), g)
+	if err != nil || !res.Success {
+		t.Fatalf("DeleteRequest failed: %v %v", res, err)
+	}
+	if h.Miniredis.Exists("test1") {
+		t.Error("player record still exists after DeleteRequest")
+	}
+	// The player record and its one index entry.
+	if res.RemovedCount != 2 {
+		t.Errorf("DeleteRequest removed_count = %d, want 2", res.RemovedCount)
+	}
+
+	// Deleting again succeeds, but removes nothing.
+	res, err = h.Client.DeleteRequest(context.Background(), g)
+	if err != nil || !res.Success || res.RemovedCount != 0 {
+		t.Errorf("second DeleteRequest = %v %v, want success with removed_count 0", res, err)
+	}
+}
+
+func TestCreateRequestDefaultProperties(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("players.defaultProperties", `{"region": "us-east", "mmr.rating": 1000}`)
+	h := testutil.NewFrontendAPI(t, cfg)
+	defer h.Close()
+
+	g := &frontend.Group{Id: "test1", Properties: `{"mmr.rating": 1200}`}
+	res, err := h.Client.CreateRequest(context.Background(), g)
+	if err != nil || !res.Success {
+		t.Fatalf("CreateRequest failed: %v %v", res, err)
+	}
+
+	want := `{"mmr.rating":1200,"region":"us-east"}`
+	if got := h.Miniredis.HGet("test1", "properties"); got != want {
+		t.Errorf("stored properties = %q, want %q", got, want)
+	}
+}
+
+func TestCreateRequestRequiredAttributes(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("players.requiredAttributes", []string{"mmr.rating", "region"})
+	h := testutil.NewFrontendAPI(t, cfg)
+	defer h.Close()
+
+	g := &frontend.Group{Id: "test1", Properties: `{"mmr.rating": 1200}`}
+	_, err := h.Client.CreateRequest(context.Background(), g)
+	if status.Code(err) != codes.InvalidArgument || !strings.Contains(err.Error(), "region") {
+		t.Fatalf("CreateRequest without region = %v, want InvalidArgument naming region", err)
+	}
+	if h.Miniredis.Exists("test1") {
+		t.Error("player missing a required attribute was queued")
+	}
+
+	g.Properties = `{"mmr.rating": 1200, "region": 1}`
+	if res, err := h.Client.CreateRequest(context.Background(), g); err != nil || !res.Success {
+		t.Errorf("CreateRequest with every required attribute failed: %v %v", res, err)
+	}
+}
+
+func TestRefreshRequest(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("players.ttl", 60)
+	h := testutil.NewFrontendAPI(t, cfg)
+	defer h.Close()
+
+	g := &frontend.Group{Id: "test1", Properties: `{"mmr.rating": 1200}`}
+	res, err := h.Client.CreateRequest(context.Background(), g)
+	if err != nil || !res.Success {
+		t.Fatalf("CreateRequest failed: %v %v", res, err)
+	}
+	if res.TtlSeconds != 60 || res.ExpiresAt == 0 {
+		t.Errorf("CreateRequest TTL = %d, expiry = %d, want 60 and non-zero", res.TtlSeconds, res.ExpiresAt)
+	}
+
+	h.Miniredis.FastForward(45 * time.Second)
+	res, err = h.Client.RefreshRequest(context.Background(), &frontend.PlayerId{Id: "test1"})
+	if err != nil || !res.Success {
+		t.Fatalf("RefreshRequest failed: %v %v", res, err)
+	}
+	if got := h.Miniredis.TTL("test1"); got != 60*time.Second {
+		t.Errorf("TTL after refresh = %v, want %v", got, 60*time.Second)
+	}
+
+	if _, err = h.Client.RefreshRequest(context.Background(), &frontend.PlayerId{Id: "missing"}); err == nil {
+		t.Error("RefreshRequest succeeded for a player that isn't queued")
+	}
+}
+
+func TestStreamRequests(t *testing.T) {
+	h := testutil.NewFrontendAPI(t, nil)
+	defer h.Close()
+
+	stream, err := h.Client.StreamRequests(context.Background())
+	if err != nil {
+		t.Fatalf("StreamRequests failed: %v", err)
+	}
+	ids := []string{"test1", "test2", "test3"}
+	for _, id := range ids {
+		if err = stream.Send(&frontend.Group{Id: id, Properties: `{"mmr.rating": 1200}`}); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+	stream.CloseSend()
+
+	for _, id := range ids {
+		res, err := stream.Recv()
+		if err != nil || !res.Success {
+			t.Fatalf("Recv failed: %v %v", res, err)
+		}
+		if res.Id != id {
+			t.Errorf("result id = %q, want %q", res.Id, id)
+		}
+		if !h.Miniredis.Exists(id) {
+			t.Errorf("player %v wasn't written to state storage", id)
+		}
+	}
+}
+
+func TestUpdateRequest(t *testing.T) {
+	h := testutil.NewFrontendAPI(t, nil)
+	defer h.Close()
+
+	g := &frontend.Group{Id: "test1", Properties: `{"mmr.rating": 1200, "ping.us-east": 70}`}
+	if res, err := h.Client.CreateRequest(context.Background(), g); err != nil || !res.Success {
+		t.Fatalf("CreateRequest failed: %v %v", res, err)
+	}
+
+	g.Properties = `{"ping.us-east": 40}`
+	if res, err := h.Client.UpdateRequest(context.Background(), g); err != nil || !res.Success {
+		t.Fatalf("UpdateRequest failed: %v %v", res, err)
+	}
+	if got, want := h.Miniredis.HGet("test1", "properties"), `{"mmr.rating":1200,"ping.us-east":40}`; got != want {
+		t.Errorf("stored properties = %q, want %q", got, want)
+	}
+	if score, _ := h.Miniredis.ZScore("ping.us-east", "test1"); score != 40 {
+		t.Errorf("ping.us-east index = %v, want 40", score)
+	}
+
+	// Only queued players can be updated.
+	if _, err := h.Client.UpdateRequest(context.Background(), &frontend.Group{Id: "missing", Properties: `{}`}); err == nil {
+		t.Error("UpdateRequest succeeded for a player that isn't queued")
+	}
+}
+
+func TestGetAssignment(t *testing.T) {
+	h := testutil.NewFrontendAPI(t, nil)
+	defer h.Close()
+
+	h.Miniredis.HSet("test1", "connstring", "127.0.0.1:7777")
+
+	ci, err := h.Client.GetAssignment(context.Background(), &frontend.PlayerId{Id: "test1"})
+	if err != nil {
+		t.Fatalf("GetAssignment failed: %v", err)
+	}
+	if ci.ConnectionString != "127.0.0.1:7777" {
+		t.Errorf("ConnectionString = %q, want %q", ci.ConnectionString, "127.0.0.1:7777")
+	}
+}
+
+// TestConcurrentGetAssignment runs many watchers at once; run it with -race
+// to check that requests don't share mutable logging state.
+func TestConcurrentGetAssignment(t *testing.T) {
+	h := testutil.NewFrontendAPI(t, nil)
+	defer h.Close()
+
+	const players = 20
+	for i := 0; i < players; i++ {
+		h.Miniredis.HSet(fmt.Sprintf("player%d", i), "connstring", fmt.Sprintf("127.0.0.1:%d", 7000+i))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, players)
+	for i := 0; i < players; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ci, err := h.Client.GetAssignment(context.Background(), &frontend.PlayerId{Id: fmt.Sprintf("player%d", i)})
+			if err != nil {
+				errs <- err
+				return
+			}
+			if want := fmt.Sprintf("127.0.0.1:%d", 7000+i); ci.ConnectionString != want {
+				errs <- fmt.Errorf("player%d: ConnectionString = %q, want %q", i, ci.ConnectionString, want)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestRegisterAssignmentWebhook(t *testing.T) {
+	pushed := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		pushed <- string(body)
+	}))
+	defer ts.Close()
+
+	cfg := viper.New()
+	cfg.Set("webhooks.allowedHosts", []string{"127.0.0.1"})
+	h := testutil.NewFrontendAPI(t, cfg)
+	defer h.Close()
+
+	h.Miniredis.HSet("test1", "connstring", "127.0.0.1:7777")
+
+	_, err := h.Client.RegisterAssignmentWebhook(context.Background(), &frontend.AssignmentWebhook{
+		PlayerId: &frontend.PlayerId{Id: "test1"},
+		Url:      "http://disallowed.example.com/assigned",
+	})
+	if err == nil {
+		t.Error("RegisterAssignmentWebhook accepted a host outside the allowlist")
+	}
+
+	res, err := h.Client.RegisterAssignmentWebhook(context.Background(), &frontend.AssignmentWebhook{
+		PlayerId: &frontend.PlayerId{Id: "test1"},
+		Url:      ts.URL + "/assigned",
+	})
+	if err != nil || !res.Success {
+		t.Fatalf("RegisterAssignmentWebhook failed: %v %v", res, err)
+	}
+
+	select {
+	case body := <-pushed:
+		want := `{"connectionString":"127.0.0.1:7777"}`
+		if body != want {
+			t.Errorf("webhook body = %q, want %q", body, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+}
+
+func TestGetAssignmentTimeout(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("assignments.timeout", 1)
+	h := testutil.NewFrontendAPI(t, cfg)
+	defer h.Close()
+
+	// A timeout must not look like a valid, empty assignment.
+	ci, err := h.Client.GetAssignment(context.Background(), &frontend.PlayerId{Id: "unassigned"})
+	if err == nil {
+		t.Fatal("GetAssignment succeeded for a player with no assignment")
+	}
+	if ci != nil {
+		t.Errorf("GetAssignment returned ConnectionInfo %v with a timeout", ci)
+	}
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("GetAssignment error code = %v, want %v", status.Code(err), codes.DeadlineExceeded)
+	}
+}
+
+func TestGetAssignmentFallback(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("assignments.timeout", 1)
+	cfg.Set("assignments.fallbackConnstring", "lobby.example.com:7777")
+	h := testutil.NewFrontendAPI(t, cfg)
+	defer h.Close()
+
+	ci, err := h.Client.GetAssignment(context.Background(), &frontend.PlayerId{Id: "unassigned"})
+	if err != nil {
+		t.Fatalf("GetAssignment failed with a fallback configured: %v", err)
+	}
+	if !ci.Fallback || ci.ConnectionString != "lobby.example.com:7777" {
+		t.Errorf("GetAssignment = %v, want the fallback connection string", ci)
+	}
+}
+
+func TestGetAssignmentMatchPending(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("assignments.timeout", 1)
+	cfg.Set("assignments.matchPending", "response")
+	h := testutil.NewFrontendAPI(t, cfg)
+	defer h.Close()
+
+	// Claimed for a match by an MMF proposal, but not yet assigned.
+	h.Miniredis.HSet("claimed", "properties", `{"mmr": 1200}`)
+	h.Miniredis.ZAdd("proposed", float64(time.Now().Unix()), "claimed")
+
+	ci, err := h.Client.GetAssignment(context.Background(), &frontend.PlayerId{Id: "claimed"})
+	if err != nil {
+		t.Fatalf("GetAssignment failed for a claimed player: %v", err)
+	}
+	if !ci.MatchPending || ci.ConnectionString != "" {
+		t.Errorf("GetAssignment = %v, want a pending match without a connection string", ci)
+	}
+
+	_, err = h.Client.GetAssignment(context.Background(), &frontend.PlayerId{Id: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetAssignment error code for an unknown player = %v, want %v", status.Code(err), codes.NotFound)
+	}
+}
+
+func TestGetPlayerStatuses(t *testing.T) {
+	h := testutil.NewFrontendAPI(t, nil)
+	defer h.Close()
+
+	h.Miniredis.HSet("queued", "properties", `{"mmr": 1200}`)
+	h.Miniredis.HSet("claimed", "properties", `{"mmr": 1200}`)
+	h.Miniredis.ZAdd("deindexed", float64(time.Now().Unix()), "claimed")
+	h.Miniredis.HSet("assigned", "connstring", "127.0.0.1:7777")
+
+	stream, err := h.Client.GetPlayerStatuses(context.Background(), &frontend.PlayerIds{Ids: []string{"queued", "claimed", "assigned", "unknown"}})
+	if err != nil {
+		t.Fatalf("GetPlayerStatuses failed: %v", err)
+	}
+	want := []frontend.PlayerState{frontend.PlayerState_QUEUED, frontend.PlayerState_DEINDEXED, frontend.PlayerState_ASSIGNED, frontend.PlayerState_NOT_FOUND}
+	for _, state := range want {
+		ps, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		if ps.State != state {
+			t.Errorf("player %v state = %v, want %v", ps.Id, ps.State, state)
+		}
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("Recv after the last status = %v, want EOF", err)
+	}
+}
+
+func TestDeleteAssignmentStopsWatchers(t *testing.T) {
+	h := testutil.NewFrontendAPI(t, nil)
+	defer h.Close()
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := h.Client.GetAssignment(context.Background(), &frontend.PlayerId{Id: "unassigned"})
+		errs <- err
+	}()
+
+	// The watcher may not have started yet, so keep deleting until it stops.
+	deadline := time.After(5 * time.Second)
+	for {
+		_, err := h.Client.DeleteAssignment(context.Background(), &frontend.PlayerId{Id: "unassigned"})
+		if err != nil {
+			t.Fatalf("DeleteAssignment failed: %v", err)
+		}
+		select {
+		case err := <-errs:
+			if status.Code(err) != codes.NotFound {
+				t.Errorf("GetAssignment error code = %v, want %v", status.Code(err), codes.NotFound)
+			}
+			return
+		case <-deadline:
+			t.Fatal("GetAssignment still waiting after DeleteAssignment")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+func TestGetAssignmentPollLimit(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("assignments.maxPolls", 1)
+	h := testutil.NewFrontendAPI(t, cfg)
+	defer h.Close()
+
+	_, err := h.Client.GetAssignment(context.Background(), &frontend.PlayerId{Id: "unassigned"})
+	if err == nil {
+		t.Fatal("GetAssignment succeeded for a player with no assignment")
+	}
+	if !strings.Contains(err.Error(), apisrv.ErrPollLimitReached.Error()) {
+		t.Errorf("GetAssignment error = %v, want %v", err, apisrv.ErrPollLimitReached)
+	}
+}
+
+func TestGetAssignmentStateStorageDown(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("assignments.maxConsecutiveFailures", 1)
+	h := testutil.NewFrontendAPI(t, cfg)
+	defer h.Close()
+	h.Miniredis.Close()
+
+	_, err := h.Client.GetAssignment(context.Background(), &frontend.PlayerId{Id: "unassigned"})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("GetAssignment error = %v, want code %v", err, codes.Unavailable)
+	}
+}
+
+func TestGetAssignmentInitialPollDelay(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("assignments.initialPollDelay", 60000)
+	cfg.Set("assignments.timeout", 1)
+	h := testutil.NewFrontendAPI(t, cfg)
+	defer h.Close()
+
+	// The assignment is already there, but the watcher is still waiting to
+	// make its first poll when the request times out.
+	h.Miniredis.HSet("assigned", "connstring", "127.0.0.1:7777")
+	_, err := h.Client.GetAssignment(context.Background(), &frontend.PlayerId{Id: "assigned"})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("GetAssignment error code = %v, want %v", status.Code(err), codes.DeadlineExceeded)
+	}
+}
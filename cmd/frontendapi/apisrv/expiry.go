@@ -0,0 +1,219 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package apisrv
+
+import (
+	"context"
+	"time"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	redisHelpers "github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis"
+	playerq "github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/playerq"
+	"github.com/gomodule/redigo/redis"
+	log "github.com/sirupsen/logrus"
+)
+
+// expiredChannel is the redis keyevent notification channel pattern for
+// expired keys in any database.
+const expiredChannel = "__keyevent@*__:expired"
+
+// Index purge defaults, used when 'players.purgeInterval' or
+// 'players.purgeBatchSize' are not set in the config.
+const (
+	defaultPurgeInterval  = 60 // seconds between purges
+	defaultPurgeBatchSize = 1000
+)
+
+// purgeExpiredIndices periodically removes players whose TTL has passed from
+// the indices, until ctx is cancelled.  Matchmaking already ignores them from
+// the moment they expire; this only keeps the indices from growing.
+func (s *FrontendAPI) purgeExpiredIndices(ctx context.Context) {
+	interval := defaultPurgeInterval
+	if s.cfg.IsSet("players.purgeInterval") && s.cfg.GetInt("players.purgeInterval") > 0 {
+		interval = s.cfg.GetInt("players.purgeInterval")
+	}
+	batchSize := defaultPurgeBatchSize
+	if s.cfg.IsSet("players.purgeBatchSize") && s.cfg.GetInt("players.purgeBatchSize") > 0 {
+		batchSize = s.cfg.GetInt("players.purgeBatchSize")
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.purgeOnce(ctx, batchSize)
+			if err != nil && ctx.Err() == nil {
+				s.log.WithFields(log.Fields{
+					"error":     err.Error(),
+					"component": "statestorage",
+				}).Error("Expired player purge failed")
+			}
+		}
+	}
+}
+
+// purgeOnce purges expired players a batch at a time until none are left.
+func (s *FrontendAPI) purgeOnce(ctx context.Context, batchSize int) error {
+	redisConn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer redisConn.Close()
+
+	total := 0
+	for ctx.Err() == nil {
+		n, err := playerq.PurgeExpired(redisConn, batchSize)
+		if err != nil {
+			return err
+		}
+		total += n
+		if n < batchSize {
+			break
+		}
+	}
+	if total > 0 {
+		s.log.WithFields(log.Fields{"players": total}).Debug("Purged expired players from the indices")
+	}
+	return nil
+}
+
+// watchExpirations subscribes to redis keyspace expiry notifications and
+// counts every queued player whose record expires before they were matched
+// (see FePlayerExpirations), and stops counting them as active if
+// 'activePlayers.enabled' is set.  A player counts as queued if they are still in
+// the index named by 'ignoreLists.expired.name', which holds the epoch
+// timestamp each player entered the queue; players that were deleted or
+// deindexed have already been removed from it.  Runs until ctx is cancelled,
+// re-subscribing after connection errors.
+func (s *FrontendAPI) watchExpirations(ctx context.Context) {
+	s.enableKeyspaceEvents("Ex")
+
+	for ctx.Err() == nil {
+		err := s.subscribeExpirations(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.log.WithFields(log.Fields{
+				"error": err.Error(),
+			}).Error("Keyspace expiry subscription error, resubscribing")
+			time.Sleep(5 * time.Second)
+		}
+	}
+}
+
+// enableKeyspaceEvents asks redis to publish the keyspace events in flags,
+// on top of those already enabled.  Managed redis offerings often disallow
+// CONFIG; in that case they must be enabled by the operator.
+func (s *FrontendAPI) enableKeyspaceEvents(flags string) {
+	redisConn := s.pool.Get()
+	defer redisConn.Close()
+	if err := redisHelpers.EnableKeyspaceEvents(redisConn, flags); err != nil {
+		s.log.WithFields(log.Fields{
+			"error":  err.Error(),
+			"events": flags,
+		}).Warn("Unable to enable keyspace notifications, they must be enabled in the redis config")
+	}
+}
+
+// subscribeExpirations handles expiry notifications on a single pubsub
+// connection until it fails or ctx is cancelled.
+func (s *FrontendAPI) subscribeExpirations(ctx context.Context) error {
+	redisConn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	psc := redis.PubSubConn{Conn: redisConn}
+	defer psc.Close()
+
+	if err := psc.PSubscribe(expiredChannel); err != nil {
+		return err
+	}
+
+	// Unblock Receive() when the context is cancelled.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			psc.PUnsubscribe(expiredChannel)
+		case <-done:
+		}
+	}()
+
+	for {
+		// Messages can be far apart, so wait without the read timeout.
+		switch v := psc.ReceiveWithTimeout(0).(type) {
+		case redis.Message:
+			s.playerExpired(ctx, string(v.Data))
+		case redis.Subscription:
+			if v.Count == 0 {
+				return nil
+			}
+		case error:
+			return v
+		}
+	}
+}
+
+// playerExpired records an expiration if the expired key was a queued
+// player, and removes it from the queue timestamp index and the active
+// players.
+func (s *FrontendAPI) playerExpired(ctx context.Context, key string) {
+	index := s.cfg.GetString("ignoreLists.expired.name")
+	activeKey := playerq.ActivePlayersKey(s.cfg)
+	if index == "" && activeKey == "" {
+		return
+	}
+
+	redisConn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error": err.Error(),
+			"key":   key,
+		}).Error("State storage connection error")
+		return
+	}
+	defer redisConn.Close()
+
+	if err := playerq.RemoveActive(redisConn, activeKey, []string{key}); err != nil {
+		s.log.WithFields(log.Fields{
+			"error": err.Error(),
+			"key":   key,
+		}).Error("State storage error")
+	}
+	if index == "" {
+		return
+	}
+
+	removed, err := redis.Int(redisConn.Do("ZREM", index, key))
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error": err.Error(),
+			"key":   key,
+		}).Error("State storage error")
+		return
+	}
+	if removed == 0 {
+		// Not a queued player.
+		return
+	}
+
+	s.log.WithFields(log.Fields{"playerid": key}).Info("Player expired from the queue without a match")
+	metrics.Record(ctx, FePlayerExpirations.M(1))
+}
@@ -16,6 +16,7 @@ limitations under the License.
 package apisrv
 
 import (
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
@@ -68,6 +69,18 @@ var (
 
 	// Failure instrumentation
 	FeFailures = stats.Int64("frontendapi/failures_total", "Number of Frontend API failures", "1")
+
+	// Queue instrumentation
+	FePlayerExpirations = stats.Int64("frontendapi/player_expirations_total", "Number of players whose queued record expired before they were matched", "1")
+	FeWriteQueueDepth   = stats.Int64("frontendapi/write_queue_depth", "Number of state storage writes waiting for a write worker", "1")
+	FeActivePlayers     = stats.Int64("frontendapi/active_players", "Number of players currently in matchmaking", "1")
+
+	// Assignment instrumentation
+	FeAssignmentLatencyMs = stats.Int64("frontendapi/assignment_latency_ms", "Milliseconds from CreateAssignments writing an assignment to GetAssignment returning it", "ms")
+	FeWatcherTerminations = stats.Int64("frontendapi/watcher_terminations_total", "Number of assignment watchers terminated as leaked after outliving their maximum lifetime", "1")
+
+	// Client instrumentation
+	FeClientCancellations = stats.Int64("frontendapi/client_cancellations_total", "Number of calls abandoned by the client before a result was ready", "1")
 )
 
 var (
@@ -80,6 +93,11 @@ var (
 	// Latency in buckets:
 	// [>=0ms, >=25ms, >=50ms, >=75ms, >=100ms, >=200ms, >=400ms, >=600ms, >=800ms, >=1s, >=2s, >=4s, >=6s]
 	latencyDistribution = view.Distribution(0, 25, 50, 75, 100, 200, 400, 600, 800, 1000, 2000, 4000, 6000)
+
+	// Assignment propagation in buckets, which includes the watcher's
+	// polling interval:
+	// [>=0ms, >=100ms, >=250ms, >=500ms, >=1s, >=2s, >=3s, >=5s, >=7.5s, >=10s, >=20s, >=30s, >=60s]
+	assignmentLatencyDistribution = view.Distribution(0, 100, 250, 500, 1000, 2000, 3000, 5000, 7500, 10000, 20000, 30000, 60000)
 )
 
 // Package metrics provides some convience views.
@@ -127,6 +145,49 @@ var (
 		Description: "The number of failures",
 		Aggregation: view.Count(),
 	}
+
+	FePlayerExpirationCountView = &view.View{
+		Name:        "frontend/players/expirations",
+		Measure:     FePlayerExpirations,
+		Description: "The number of players that expired from the queue without a match",
+		Aggregation: view.Count(),
+	}
+
+	FeClientCancellationCountView = &view.View{
+		Name:        "frontend/grpc/client_cancellations",
+		Measure:     FeClientCancellations,
+		Description: "The number of calls abandoned by the client before a result was ready",
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{KeyMethod},
+	}
+
+	FeAssignmentLatencyView = &view.View{
+		Name:        "frontend/assignments/latency",
+		Measure:     FeAssignmentLatencyMs,
+		Description: "The distribution of milliseconds from an assignment being written to it being returned to the player",
+		Aggregation: assignmentLatencyDistribution,
+	}
+
+	FeWatcherTerminationCountView = &view.View{
+		Name:        "frontend/watchers/forced_terminations",
+		Measure:     FeWatcherTerminations,
+		Description: "The number of assignment watchers terminated as leaked after outliving their maximum lifetime",
+		Aggregation: view.Count(),
+	}
+
+	FeWriteQueueDepthView = &view.View{
+		Name:        "frontend/write_queue/depth",
+		Measure:     FeWriteQueueDepth,
+		Description: "The number of state storage writes waiting for a write worker",
+		Aggregation: view.LastValue(),
+	}
+
+	FeActivePlayersView = &view.View{
+		Name:        "frontend/players/active",
+		Measure:     FeActivePlayers,
+		Description: "The number of players currently in matchmaking",
+		Aggregation: view.LastValue(),
+	}
 )
 
 // DefaultFrontendAPIViews are the default frontend API OpenCensus measure views.
@@ -136,4 +197,14 @@ var DefaultFrontendAPIViews = []*view.View{
 	FeErrorCountView,
 	FeLogCountView,
 	FeFailureCountView,
+	FePlayerExpirationCountView,
+	FeWriteQueueDepthView,
+	FeActivePlayersView,
+	FeClientCancellationCountView,
+	FeAssignmentLatencyView,
+	FeWatcherTerminationCountView,
+}
+
+func init() {
+	metrics.AddViews(DefaultFrontendAPIViews...)
 }
@@ -0,0 +1,141 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package apisrv
+
+import (
+	"fmt"
+
+	frontend "github.com/GoogleCloudPlatform/open-match/cmd/frontendapi/proto"
+	"github.com/GoogleCloudPlatform/open-match/internal/grpcutil"
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	"github.com/GoogleCloudPlatform/open-match/internal/playerid"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/assignment"
+	playerq "github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/playerq"
+	"github.com/gomodule/redigo/redis"
+	log "github.com/sirupsen/logrus"
+	"go.opencensus.io/tag"
+)
+
+// maxPlayerStatuses is the most players GetPlayerStatuses reports on in one
+// call, which is plenty for a party while bounding the pipeline it runs.
+const maxPlayerStatuses = 100
+
+// GetPlayerStatuses is this service's implementation of the GetPlayerStatuses
+// gRPC method defined in frontendapi/proto/frontend.proto
+func (s *frontendAPI) GetPlayerStatuses(ids *frontend.PlayerIds, stream frontend.API_GetPlayerStatusesServer) error {
+	ctx := stream.Context()
+
+	// Create context for tagging OpenCensus metrics.
+	funcName := "GetPlayerStatuses"
+	fnCtx, _ := tag.New(ctx, tag.Insert(KeyMethod, funcName))
+
+	if len(ids.Ids) == 0 || len(ids.Ids) > maxPlayerStatuses {
+		err := fmt.Errorf("%w: GetPlayerStatuses requires 1 to %v player ids, got %v", grpcutil.ErrInvalidArgument, maxPlayerStatuses, len(ids.Ids))
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return err
+	}
+	playerIDs := make([]string, len(ids.Ids))
+	for i, id := range ids.Ids {
+		playerIDs[i] = playerid.Normalize(s.cfg, id)
+	}
+
+	// Assignments are read with the configured codec.
+	codec, err := assignment.NewCodec(s.cfg)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Assignment codec error")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return err
+	}
+
+	redisConn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage error")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return err
+	}
+	statuses, err := playerStatuses(redisConn, codec, playerIDs)
+	redisConn.Close()
+	if err != nil {
+		s.log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage error reading player statuses")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return err
+	}
+
+	for _, status := range statuses {
+		if err := stream.Send(status); err != nil {
+			metrics.Record(fnCtx, FeGrpcErrors.M(1))
+			return err
+		}
+	}
+
+	metrics.Record(fnCtx, FeGrpcRequests.M(1))
+	return nil
+}
+
+// playerStatuses reads the state of each player, in order, in one pipeline:
+// their record, and whether they're in the "proposed" or "deindexed"
+// ignorelists.
+func playerStatuses(redisConn redis.Conn, codec assignment.AssignmentCodec, playerIDs []string) ([]*frontend.PlayerStatus, error) {
+	for _, id := range playerIDs {
+		redisConn.Send("HGETALL", id)
+		redisConn.Send("ZSCORE", "proposed", id)
+		redisConn.Send("ZSCORE", "deindexed", id)
+	}
+	if err := redisConn.Flush(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*frontend.PlayerStatus, len(playerIDs))
+	for i, id := range playerIDs {
+		fields, err := redis.StringMap(redisConn.Receive())
+		if err != nil {
+			return nil, err
+		}
+		// Ignorelists that don't hold the player reply with nil.
+		var claimed bool
+		for j := 0; j < 2; j++ {
+			score, err := redisConn.Receive()
+			if err != nil {
+				return nil, err
+			}
+			claimed = claimed || score != nil
+		}
+
+		state := frontend.PlayerState_NOT_FOUND
+		if _, err := codec.Decode(fields); err == nil {
+			state = frontend.PlayerState_ASSIGNED
+		} else if len(fields) > 0 && claimed {
+			state = frontend.PlayerState_DEINDEXED
+		} else if playerq.HasProperties(fields) {
+			state = frontend.PlayerState_QUEUED
+		}
+		statuses[i] = &frontend.PlayerStatus{Id: id, State: state}
+	}
+	return statuses, nil
+}
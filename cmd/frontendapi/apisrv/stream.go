@@ -0,0 +1,194 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apisrv
+
+import (
+	"context"
+	"io"
+	"time"
+
+	frontend "github.com/GoogleCloudPlatform/open-match/cmd/frontendapi/proto"
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	"github.com/GoogleCloudPlatform/open-match/internal/playerid"
+	playerq "github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/playerq"
+	"github.com/gomodule/redigo/redis"
+	log "github.com/sirupsen/logrus"
+	"go.opencensus.io/tag"
+)
+
+// defaultStreamBatchSize is the most groups StreamRequests writes in one redis
+// pipeline when 'players.streamBatchSize' is not set in the config.
+const defaultStreamBatchSize = 100
+
+// StreamRequests is this service's implementation of the StreamRequests gRPC
+// method defined in frontendapi/proto/frontend.proto
+func (s *frontendAPI) StreamRequests(stream frontend.API_StreamRequestsServer) error {
+	ctx := stream.Context()
+
+	// Create context for tagging OpenCensus metrics.
+	funcName := "StreamRequests"
+	fnCtx, _ := tag.New(ctx, tag.Insert(KeyMethod, funcName))
+
+	batchSize := defaultStreamBatchSize
+	if s.cfg.GetInt("players.streamBatchSize") > 0 {
+		batchSize = s.cfg.GetInt("players.streamBatchSize")
+	}
+
+	// Receive groups in the background, so groups that arrive while a batch
+	// is being written are ready to go in the next batch.
+	groups := make(chan *frontend.Group, batchSize)
+	recvErr := make(chan error, 1)
+	go func() {
+		defer close(groups)
+		for {
+			g, err := stream.Recv()
+			if err != nil {
+				if err != io.EOF {
+					recvErr <- err
+				}
+				return
+			}
+			select {
+			case groups <- g:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for g := range groups {
+		// Batch this group with any others that are already waiting.
+		batch := []*frontend.Group{g}
+	fill:
+		for len(batch) < batchSize {
+			select {
+			case g, ok := <-groups:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, g)
+			default:
+				break fill
+			}
+		}
+
+		for _, result := range s.writeGroups(ctx, batch) {
+			if result.Success {
+				metrics.Record(fnCtx, FeGrpcRequests.M(1))
+			} else {
+				metrics.Record(fnCtx, FeGrpcErrors.M(1))
+			}
+			if err := stream.Send(result); err != nil {
+				return err
+			}
+		}
+	}
+
+	select {
+	case err := <-recvErr:
+		return err
+	default:
+		return nil
+	}
+}
+
+// writeGroups writes a batch of groups to state storage in a single redis
+// pipeline, and returns a result for each group in the same order.
+func (s *frontendAPI) writeGroups(ctx context.Context, batch []*frontend.Group) []*frontend.Result {
+	ttl, ttlErr := playerq.TTLFromConfig(s.cfg)
+	layout, layoutErr := playerq.LayoutFromConfig(s.cfg)
+	scoring, scoringErr := playerq.ScoringFromConfig(s.cfg)
+	activeKey := playerq.ActivePlayersKey(s.cfg)
+	results := make([]*frontend.Result, len(batch))
+	properties := make([]string, len(batch))
+	valid := make([]bool, len(batch))
+	// Results are reported under the ID the client sent.
+	ids := make([]string, len(batch))
+	for i, g := range batch {
+		results[i] = &frontend.Result{Id: g.Id, Success: true}
+		ids[i] = playerid.Normalize(s.cfg, g.Id)
+		p, err := defaultProperties(s.cfg, g.Properties)
+		if err == nil {
+			err = requireAttributes(s.cfg, p)
+		}
+		if err == nil {
+			err = layoutErr
+		}
+		if err == nil {
+			err = scoringErr
+		}
+		if err == nil {
+			err = ttlErr
+		}
+		if err != nil {
+			results[i].Success, results[i].Error = false, err.Error()
+			continue
+		}
+		properties[i], valid[i] = p, true
+	}
+
+	// The write may still be running if queueWrite gives up on it, so it
+	// doesn't touch results, and records errors in its own slice that is
+	// only read once it has finished.
+	writeErrs := make([]error, len(batch))
+	err := s.queueWrite(ctx, func(redisConn redis.Conn) error {
+		replies := make([]int, len(batch))
+		for i := range batch {
+			if valid[i] {
+				replies[i], writeErrs[i] = playerq.SendCreate(redisConn, ids[i], properties[i], ttl, layout, scoring)
+			}
+		}
+		if err := redisConn.Flush(); err != nil {
+			return err
+		}
+		for i := range batch {
+			if replies[i] > 0 {
+				writeErrs[i] = playerq.ReceiveCreate(redisConn, ids[i], replies[i])
+			}
+		}
+
+		queued := make([]string, 0, len(batch))
+		for i := range batch {
+			if replies[i] > 0 && writeErrs[i] == nil {
+				queued = append(queued, ids[i])
+			}
+		}
+		return playerq.AddActive(redisConn, activeKey, queued)
+	})
+
+	now := time.Now().Unix()
+	for i, result := range results {
+		switch {
+		case !result.Success:
+		case err != nil:
+			result.Success, result.Error = false, err.Error()
+		case writeErrs[i] != nil:
+			result.Success, result.Error = false, writeErrs[i].Error()
+		default:
+			if seconds := int64(ttl.TTL(properties[i])); seconds > 0 {
+				result.TtlSeconds, result.ExpiresAt = seconds, now+seconds
+			}
+		}
+		if !result.Success {
+			s.log.WithFields(log.Fields{
+				"error":    result.Error,
+				"playerid": result.Id,
+			}).Error("Unable to enqueue group from stream")
+		}
+	}
+	return results
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apisrv
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/assignment"
+	"github.com/alicebob/miniredis"
+	"github.com/gomodule/redigo/redis"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWatcherMaxLifetime(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("assignments.timeout", 1)
+	cfg.Set("webhooks.assignmentWait", 1)
+	s := &frontendAPI{cfg: cfg, log: log.WithFields(feLogFields)}
+
+	// A lifetime no longer than the longest wait is ignored.
+	cfg.Set("assignments.watcherMaxLifetime", 1)
+	if got, want := s.watcherMaxLifetime(), time.Duration(1+watcherLifetimeGrace)*time.Second; got != want {
+		t.Errorf("watcherMaxLifetime = %v, want %v", got, want)
+	}
+	cfg.Set("assignments.watcherMaxLifetime", 2)
+	if got := s.watcherMaxLifetime(); got != 2*time.Second {
+		t.Errorf("watcherMaxLifetime = %v, want %v", got, 2*time.Second)
+	}
+}
+
+func TestWatcherTerminated(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) }}
+
+	cfg := viper.New()
+	cfg.Set("jsonkeys.connstring", "connstring")
+	cfg.Set("assignments.timeout", 1)
+	cfg.Set("webhooks.assignmentWait", 1)
+	cfg.Set("assignments.watcherMaxLifetime", 2)
+	s := &frontendAPI{cfg: cfg, pool: pool, log: log.WithFields(feLogFields)}
+	codec, err := assignment.NewCodec(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nobody cancels the watcher, so it's terminated as leaked, and must say
+	// so rather than look like its caller went away.
+	select {
+	case r, ok := <-s.watcher(context.Background(), pool, codec, "missing"):
+		if !ok || status.Code(r.err) != codes.Aborted {
+			t.Errorf("terminated watcher sent %v, %v, want a codes.Aborted error", r, ok)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher outlived its maximum lifetime")
+	}
+}
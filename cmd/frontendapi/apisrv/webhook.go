@@ -0,0 +1,191 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package apisrv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	frontend "github.com/GoogleCloudPlatform/open-match/cmd/frontendapi/proto"
+	"github.com/GoogleCloudPlatform/open-match/internal/grpcutil"
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	"github.com/GoogleCloudPlatform/open-match/internal/playerid"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/assignment"
+	"github.com/golang/protobuf/jsonpb"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go.opencensus.io/tag"
+)
+
+// Defaults used when the 'webhooks' config section doesn't set a value.
+const (
+	defaultWebhookWait        = 300 // seconds to wait for an assignment
+	defaultWebhookMaxAttempts = 5
+	defaultWebhookBackoff     = 500 // milliseconds before the first retry
+	webhookRequestTimeout     = 10 * time.Second
+)
+
+// RegisterAssignmentWebhook is this service's implementation of the
+// RegisterAssignmentWebhook gRPC method defined in frontendapi/proto/frontend.proto
+func (s *frontendAPI) RegisterAssignmentWebhook(c context.Context, w *frontend.AssignmentWebhook) (*frontend.Result, error) {
+	// Create context for tagging OpenCensus metrics.
+	funcName := "RegisterAssignmentWebhook"
+	fnCtx, _ := tag.New(c, tag.Insert(KeyMethod, funcName))
+
+	if w.GetPlayerId().GetId() == "" {
+		err := fmt.Errorf("%w: webhook registration requires a player id", grpcutil.ErrInvalidArgument)
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return &frontend.Result{Success: false, Error: err.Error()}, err
+	}
+	w.PlayerId.Id = playerid.Normalize(s.cfg, w.PlayerId.Id)
+
+	if err := checkWebhookURL(s.cfg, w.Url); err != nil {
+		s.log.WithFields(log.Fields{
+			"error":    err.Error(),
+			"playerid": w.PlayerId.Id,
+			"url":      w.Url,
+		}).Warn("Rejected assignment webhook")
+
+		metrics.Record(fnCtx, FeGrpcErrors.M(1))
+		return &frontend.Result{Success: false, Error: err.Error()}, err
+	}
+
+	// The push outlives this call, so don't tie it to the request context.
+	go s.pushAssignment(w.PlayerId.Id, w.Url)
+
+	metrics.Record(fnCtx, FeGrpcRequests.M(1))
+	return &frontend.Result{Success: true, Error: ""}, nil
+}
+
+// checkWebhookURL returns an error unless the URL is an http(s) URL whose
+// host is listed in 'webhooks.allowedHosts'.  An empty allowlist rejects
+// every webhook.
+func checkWebhookURL(cfg *viper.Viper, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: invalid webhook url: %v", grpcutil.ErrInvalidArgument, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: webhook url scheme must be http or https, not '%s'", grpcutil.ErrInvalidArgument, u.Scheme)
+	}
+	for _, host := range cfg.GetStringSlice("webhooks.allowedHosts") {
+		if strings.EqualFold(host, u.Hostname()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: webhook host '%s' is not in the allowlist", grpcutil.ErrInvalidArgument, u.Hostname())
+}
+
+// pushAssignment waits for the player's assignment to appear in state
+// storage, then POSTs it to the webhook URL, retrying with exponential
+// backoff until the webhook returns a 2xx status or the configured number of
+// attempts is exhausted.
+func (s *frontendAPI) pushAssignment(playerID string, webhookURL string) {
+	pLog := s.log.WithFields(log.Fields{"playerid": playerID, "url": webhookURL})
+
+	wait := defaultWebhookWait
+	if s.cfg.IsSet("webhooks.assignmentWait") && s.cfg.GetInt("webhooks.assignmentWait") > 0 {
+		wait = s.cfg.GetInt("webhooks.assignmentWait")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(wait)*time.Second)
+	defer cancel()
+
+	codec, err := assignment.NewCodec(s.cfg)
+	if err != nil {
+		pLog.WithFields(log.Fields{"error": err.Error()}).Error("Assignment codec error")
+		return
+	}
+
+	// Stop waiting if the player's assignment is deleted.
+	deleted := make(chan struct{})
+	s.trackWatcher(playerID, deleted)
+	defer s.untrackWatcher(playerID, deleted)
+
+	var fields map[string]string
+	select {
+	case <-deleted:
+		pLog.Info("Assignment deleted before it could be pushed")
+		return
+	case r, ok := <-s.watcher(ctx, s.pool, codec, playerID):
+		if !ok {
+			pLog.Warn("No assignment before the webhook wait expired")
+			return
+		}
+		if r.err != nil {
+			pLog.WithFields(log.Fields{"error": r.err.Error()}).Warn("Gave up waiting for an assignment")
+			return
+		}
+		fields = r.fields
+	}
+	ci, err := codec.Decode(fields)
+	if err != nil {
+		pLog.WithFields(log.Fields{"error": err.Error()}).Error("Connection string decoding error")
+		return
+	}
+
+	body, err := (&jsonpb.Marshaler{}).MarshalToString(&frontend.ConnectionInfo{ConnectionString: ci.ConnectionString, Metadata: ci.Metadata})
+	if err != nil {
+		pLog.WithFields(log.Fields{"error": err.Error()}).Error("Unable to marshal connection info")
+		return
+	}
+
+	attempts := defaultWebhookMaxAttempts
+	if s.cfg.IsSet("webhooks.maxAttempts") && s.cfg.GetInt("webhooks.maxAttempts") > 0 {
+		attempts = s.cfg.GetInt("webhooks.maxAttempts")
+	}
+	backoff := time.Duration(defaultWebhookBackoff) * time.Millisecond
+	if s.cfg.IsSet("webhooks.initialBackoffMs") && s.cfg.GetInt("webhooks.initialBackoffMs") > 0 {
+		backoff = time.Duration(s.cfg.GetInt("webhooks.initialBackoffMs")) * time.Millisecond
+	}
+
+	client := &http.Client{Timeout: webhookRequestTimeout}
+	for i := 1; i <= attempts; i++ {
+		err = postWebhook(client, webhookURL, body)
+		if err == nil {
+			pLog.WithFields(log.Fields{"attempt": i}).Debug("Assignment pushed to webhook")
+			return
+		}
+		pLog.WithFields(log.Fields{
+			"error":   err.Error(),
+			"attempt": i,
+		}).Warn("Assignment webhook failed")
+		if i < attempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	pLog.Error("Giving up on assignment webhook")
+}
+
+// postWebhook sends a single POST and treats any non-2xx response as an error.
+func postWebhook(client *http.Client, webhookURL string, body string) error {
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+	return nil
+}
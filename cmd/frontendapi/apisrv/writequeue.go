@@ -0,0 +1,94 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package apisrv
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/grpcutil"
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	"github.com/gomodule/redigo/redis"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Defaults used when the 'players' config section doesn't size the write
+// queue.
+const (
+	defaultWriteWorkers    = 16
+	defaultWriteQueueDepth = 1000
+)
+
+// writeJob is a state storage write waiting for a write worker.
+type writeJob struct {
+	ctx   context.Context
+	write func(redis.Conn) error
+	done  chan error
+}
+
+// startWriteWorkers creates the bounded queue for state storage writes and
+// the workers that drain it, sized by 'players.writeWorkers' and
+// 'players.writeQueueDepth'.  Only the workers hold redis connections for
+// writes, so a burst of requests queues up here instead of every handler
+// blocking on the redis pool.
+func (s *FrontendAPI) startWriteWorkers() {
+	workers := defaultWriteWorkers
+	if s.cfg.GetInt("players.writeWorkers") > 0 {
+		workers = s.cfg.GetInt("players.writeWorkers")
+	}
+	depth := defaultWriteQueueDepth
+	if s.cfg.GetInt("players.writeQueueDepth") > 0 {
+		depth = s.cfg.GetInt("players.writeQueueDepth")
+	}
+
+	s.writes = make(chan writeJob, depth)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range s.writes {
+				redisConn, err := grpcutil.GetConn(job.ctx, s.pool)
+				if err != nil {
+					job.done <- err
+					continue
+				}
+				job.done <- job.write(redisConn)
+				redisConn.Close()
+			}
+		}()
+	}
+}
+
+// queueWrite hands write to the write workers and waits for its result.  If
+// the queue is full it returns a ResourceExhausted error immediately, so
+// clients see backpressure instead of a hung call.
+func (s *frontendAPI) queueWrite(ctx context.Context, write func(redis.Conn) error) error {
+	job := writeJob{ctx: ctx, write: write, done: make(chan error, 1)}
+	select {
+	case s.writes <- job:
+	default:
+		metrics.Record(ctx, FeWriteQueueDepth.M(int64(cap(s.writes))))
+		return status.Error(codes.ResourceExhausted, "state storage write queue is full, try again later")
+	}
+	metrics.Record(ctx, FeWriteQueueDepth.M(int64(len(s.writes))))
+
+	select {
+	case err := <-job.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -26,6 +26,9 @@ It has these top-level messages:
 	PlayerId
 	ConnectionInfo
 	Result
+	AssignmentWebhook
+	PlayerIds
+	PlayerStatus
 */
 package frontend
 
@@ -43,6 +46,34 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
+// A player's place in matchmaking.
+type PlayerState int32
+
+const (
+	PlayerState_NOT_FOUND PlayerState = 0
+	PlayerState_QUEUED    PlayerState = 1
+	PlayerState_DEINDEXED PlayerState = 2
+	PlayerState_ASSIGNED  PlayerState = 3
+)
+
+var PlayerState_name = map[int32]string{
+	0: "NOT_FOUND",
+	1: "QUEUED",
+	2: "DEINDEXED",
+	3: "ASSIGNED",
+}
+var PlayerState_value = map[string]int32{
+	"NOT_FOUND": 0,
+	"QUEUED":    1,
+	"DEINDEXED": 2,
+	"ASSIGNED":  3,
+}
+
+func (x PlayerState) String() string {
+	return proto.EnumName(PlayerState_name, int32(x))
+}
+func (PlayerState) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the proto package it is being compiled against.
 // A compilation error at this line likely means your copy of the
@@ -93,7 +124,11 @@ func (m *PlayerId) GetId() string {
 
 // Simple message used to pass the connection string for the DGS to the player.
 type ConnectionInfo struct {
-	ConnectionString string `protobuf:"bytes,1,opt,name=connection_string,json=connectionString" json:"connection_string,omitempty"`
+	ConnectionString  string            `protobuf:"bytes,1,opt,name=connection_string,json=connectionString" json:"connection_string,omitempty"`
+	RetryAfterSeconds int64             `protobuf:"varint,2,opt,name=retry_after_seconds,json=retryAfterSeconds" json:"retry_after_seconds,omitempty"`
+	Metadata          map[string]string `protobuf:"bytes,3,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	MatchPending      bool              `protobuf:"varint,4,opt,name=match_pending,json=matchPending" json:"match_pending,omitempty"`
+	Fallback          bool              `protobuf:"varint,5,opt,name=fallback" json:"fallback,omitempty"`
 }
 
 func (m *ConnectionInfo) Reset()                    { *m = ConnectionInfo{} }
@@ -108,10 +143,42 @@ func (m *ConnectionInfo) GetConnectionString() string {
 	return ""
 }
 
+func (m *ConnectionInfo) GetRetryAfterSeconds() int64 {
+	if m != nil {
+		return m.RetryAfterSeconds
+	}
+	return 0
+}
+
+func (m *ConnectionInfo) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *ConnectionInfo) GetMatchPending() bool {
+	if m != nil {
+		return m.MatchPending
+	}
+	return false
+}
+
+func (m *ConnectionInfo) GetFallback() bool {
+	if m != nil {
+		return m.Fallback
+	}
+	return false
+}
+
 // Simple message to return success/failure and error status.
 type Result struct {
-	Success bool   `protobuf:"varint,1,opt,name=success" json:"success,omitempty"`
-	Error   string `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+	Success      bool   `protobuf:"varint,1,opt,name=success" json:"success,omitempty"`
+	Error        string `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+	TtlSeconds   int64  `protobuf:"varint,3,opt,name=ttl_seconds,json=ttlSeconds" json:"ttl_seconds,omitempty"`
+	ExpiresAt    int64  `protobuf:"varint,4,opt,name=expires_at,json=expiresAt" json:"expires_at,omitempty"`
+	Id           string `protobuf:"bytes,5,opt,name=id" json:"id,omitempty"`
+	RemovedCount int64  `protobuf:"varint,6,opt,name=removed_count,json=removedCount" json:"removed_count,omitempty"`
 }
 
 func (m *Result) Reset()                    { *m = Result{} }
@@ -133,11 +200,108 @@ func (m *Result) GetError() string {
 	return ""
 }
 
+func (m *Result) GetTtlSeconds() int64 {
+	if m != nil {
+		return m.TtlSeconds
+	}
+	return 0
+}
+
+func (m *Result) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+func (m *Result) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Result) GetRemovedCount() int64 {
+	if m != nil {
+		return m.RemovedCount
+	}
+	return 0
+}
+
+type AssignmentWebhook struct {
+	PlayerId *PlayerId `protobuf:"bytes,1,opt,name=player_id,json=playerId" json:"player_id,omitempty"`
+	Url      string    `protobuf:"bytes,2,opt,name=url" json:"url,omitempty"`
+}
+
+func (m *AssignmentWebhook) Reset()                    { *m = AssignmentWebhook{} }
+func (m *AssignmentWebhook) String() string            { return proto.CompactTextString(m) }
+func (*AssignmentWebhook) ProtoMessage()               {}
+func (*AssignmentWebhook) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *AssignmentWebhook) GetPlayerId() *PlayerId {
+	if m != nil {
+		return m.PlayerId
+	}
+	return nil
+}
+
+func (m *AssignmentWebhook) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+// IDs of the players to report on, e.g. the members of a party.
+type PlayerIds struct {
+	Ids []string `protobuf:"bytes,1,rep,name=ids" json:"ids,omitempty"`
+}
+
+func (m *PlayerIds) Reset()                    { *m = PlayerIds{} }
+func (m *PlayerIds) String() string            { return proto.CompactTextString(m) }
+func (*PlayerIds) ProtoMessage()               {}
+func (*PlayerIds) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *PlayerIds) GetIds() []string {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+type PlayerStatus struct {
+	Id    string      `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	State PlayerState `protobuf:"varint,2,opt,name=state,enum=PlayerState" json:"state,omitempty"`
+}
+
+func (m *PlayerStatus) Reset()                    { *m = PlayerStatus{} }
+func (m *PlayerStatus) String() string            { return proto.CompactTextString(m) }
+func (*PlayerStatus) ProtoMessage()               {}
+func (*PlayerStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *PlayerStatus) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *PlayerStatus) GetState() PlayerState {
+	if m != nil {
+		return m.State
+	}
+	return PlayerState_NOT_FOUND
+}
+
 func init() {
 	proto.RegisterType((*Group)(nil), "Group")
 	proto.RegisterType((*PlayerId)(nil), "PlayerId")
 	proto.RegisterType((*ConnectionInfo)(nil), "ConnectionInfo")
 	proto.RegisterType((*Result)(nil), "Result")
+	proto.RegisterType((*AssignmentWebhook)(nil), "AssignmentWebhook")
+	proto.RegisterType((*PlayerIds)(nil), "PlayerIds")
+	proto.RegisterType((*PlayerStatus)(nil), "PlayerStatus")
+	proto.RegisterEnum("PlayerState", PlayerState_name, PlayerState_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -155,6 +319,26 @@ type APIClient interface {
 	DeleteRequest(ctx context.Context, in *Group, opts ...grpc.CallOption) (*Result, error)
 	GetAssignment(ctx context.Context, in *PlayerId, opts ...grpc.CallOption) (*ConnectionInfo, error)
 	DeleteAssignment(ctx context.Context, in *PlayerId, opts ...grpc.CallOption) (*Result, error)
+	// Ask the Frontend API to POST the player's ConnectionInfo, as JSON, to
+	// the provided URL once an assignment is made, instead of the client
+	// polling GetAssignment. The URL's host must be listed in the
+	// 'webhooks.allowedHosts' config.
+	RegisterAssignmentWebhook(ctx context.Context, in *AssignmentWebhook, opts ...grpc.CallOption) (*Result, error)
+	// Reset the expiry of a queued player's record to the configured queue TTL,
+	// without changing their properties. Clients in long matchmaking sessions
+	// should call this periodically to stay in the queue.
+	RefreshRequest(ctx context.Context, in *PlayerId, opts ...grpc.CallOption) (*Result, error)
+	// Enqueue a stream of groups over a single long-lived call. Each group is
+	// acknowledged with a Result whose id is the group's id. Groups that
+	// arrive together are written to state storage in a single pipeline.
+	StreamRequests(ctx context.Context, opts ...grpc.CallOption) (API_StreamRequestsClient, error)
+	// Merge the properties in the Group into a queued player's properties,
+	// re-indexing only the properties it contains. Other properties, the
+	// record's expiry and the player's place in the queue are unchanged.
+	UpdateRequest(ctx context.Context, in *Group, opts ...grpc.CallOption) (*Result, error)
+	// Report the matchmaking state of each player, e.g. every member of a
+	// party, in one call.  Statuses are streamed in the order of the IDs.
+	GetPlayerStatuses(ctx context.Context, in *PlayerIds, opts ...grpc.CallOption) (API_GetPlayerStatusesClient, error)
 }
 
 type aPIClient struct {
@@ -201,6 +385,96 @@ func (c *aPIClient) DeleteAssignment(ctx context.Context, in *PlayerId, opts ...
 	return out, nil
 }
 
+func (c *aPIClient) RegisterAssignmentWebhook(ctx context.Context, in *AssignmentWebhook, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := grpc.Invoke(ctx, "/API/RegisterAssignmentWebhook", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) RefreshRequest(ctx context.Context, in *PlayerId, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := grpc.Invoke(ctx, "/API/RefreshRequest", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) StreamRequests(ctx context.Context, opts ...grpc.CallOption) (API_StreamRequestsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_API_serviceDesc.Streams[0], c.cc, "/API/StreamRequests", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIStreamRequestsClient{stream}
+	return x, nil
+}
+
+type API_StreamRequestsClient interface {
+	Send(*Group) error
+	Recv() (*Result, error)
+	grpc.ClientStream
+}
+
+type aPIStreamRequestsClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIStreamRequestsClient) Send(m *Group) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *aPIStreamRequestsClient) Recv() (*Result, error) {
+	m := new(Result)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aPIClient) UpdateRequest(ctx context.Context, in *Group, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := grpc.Invoke(ctx, "/API/UpdateRequest", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) GetPlayerStatuses(ctx context.Context, in *PlayerIds, opts ...grpc.CallOption) (API_GetPlayerStatusesClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_API_serviceDesc.Streams[1], c.cc, "/API/GetPlayerStatuses", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIGetPlayerStatusesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type API_GetPlayerStatusesClient interface {
+	Recv() (*PlayerStatus, error)
+	grpc.ClientStream
+}
+
+type aPIGetPlayerStatusesClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIGetPlayerStatusesClient) Recv() (*PlayerStatus, error) {
+	m := new(PlayerStatus)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for API service
 
 type APIServer interface {
@@ -208,6 +482,26 @@ type APIServer interface {
 	DeleteRequest(context.Context, *Group) (*Result, error)
 	GetAssignment(context.Context, *PlayerId) (*ConnectionInfo, error)
 	DeleteAssignment(context.Context, *PlayerId) (*Result, error)
+	// Ask the Frontend API to POST the player's ConnectionInfo, as JSON, to
+	// the provided URL once an assignment is made, instead of the client
+	// polling GetAssignment. The URL's host must be listed in the
+	// 'webhooks.allowedHosts' config.
+	RegisterAssignmentWebhook(context.Context, *AssignmentWebhook) (*Result, error)
+	// Reset the expiry of a queued player's record to the configured queue TTL,
+	// without changing their properties. Clients in long matchmaking sessions
+	// should call this periodically to stay in the queue.
+	RefreshRequest(context.Context, *PlayerId) (*Result, error)
+	// Enqueue a stream of groups over a single long-lived call. Each group is
+	// acknowledged with a Result whose id is the group's id. Groups that
+	// arrive together are written to state storage in a single pipeline.
+	StreamRequests(API_StreamRequestsServer) error
+	// Merge the properties in the Group into a queued player's properties,
+	// re-indexing only the properties it contains. Other properties, the
+	// record's expiry and the player's place in the queue are unchanged.
+	UpdateRequest(context.Context, *Group) (*Result, error)
+	// Report the matchmaking state of each player, e.g. every member of a
+	// party, in one call.  Statuses are streamed in the order of the IDs.
+	GetPlayerStatuses(*PlayerIds, API_GetPlayerStatusesServer) error
 }
 
 func RegisterAPIServer(s *grpc.Server, srv APIServer) {
@@ -286,6 +580,107 @@ func _API_DeleteAssignment_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_RegisterAssignmentWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AssignmentWebhook)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).RegisterAssignmentWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/RegisterAssignmentWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).RegisterAssignmentWebhook(ctx, req.(*AssignmentWebhook))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_RefreshRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayerId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).RefreshRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/RefreshRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).RefreshRequest(ctx, req.(*PlayerId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_StreamRequests_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(APIServer).StreamRequests(&aPIStreamRequestsServer{stream})
+}
+
+type API_StreamRequestsServer interface {
+	Send(*Result) error
+	Recv() (*Group, error)
+	grpc.ServerStream
+}
+
+type aPIStreamRequestsServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPIStreamRequestsServer) Send(m *Result) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *aPIStreamRequestsServer) Recv() (*Group, error) {
+	m := new(Group)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _API_UpdateRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Group)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).UpdateRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/UpdateRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).UpdateRequest(ctx, req.(*Group))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetPlayerStatuses_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PlayerIds)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).GetPlayerStatuses(m, &aPIGetPlayerStatusesServer{stream})
+}
+
+type API_GetPlayerStatusesServer interface {
+	Send(*PlayerStatus) error
+	grpc.ServerStream
+}
+
+type aPIGetPlayerStatusesServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPIGetPlayerStatusesServer) Send(m *PlayerStatus) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _API_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "API",
 	HandlerType: (*APIServer)(nil),
@@ -306,30 +701,77 @@ var _API_serviceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteAssignment",
 			Handler:    _API_DeleteAssignment_Handler,
 		},
+		{
+			MethodName: "RegisterAssignmentWebhook",
+			Handler:    _API_RegisterAssignmentWebhook_Handler,
+		},
+		{
+			MethodName: "RefreshRequest",
+			Handler:    _API_RefreshRequest_Handler,
+		},
+		{
+			MethodName: "UpdateRequest",
+			Handler:    _API_UpdateRequest_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamRequests",
+			Handler:       _API_StreamRequests_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "GetPlayerStatuses",
+			Handler:       _API_GetPlayerStatuses_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "frontend.proto",
 }
 
 func init() { proto.RegisterFile("frontend.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 260 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x90, 0x41, 0x4b, 0xfb, 0x40,
-	0x10, 0xc5, 0x9b, 0xfc, 0x69, 0xda, 0x0e, 0x34, 0xff, 0xba, 0x78, 0x08, 0x39, 0x88, 0xec, 0xa9,
-	0x20, 0xee, 0x41, 0x0f, 0x7a, 0xf1, 0x50, 0x2a, 0x94, 0xdc, 0x4a, 0xfc, 0x00, 0x52, 0x93, 0x69,
-	0x59, 0x88, 0xbb, 0x71, 0x66, 0x72, 0xf0, 0x0b, 0xf9, 0x39, 0xc5, 0x4d, 0x6b, 0x55, 0xc4, 0xe3,
-	0xfb, 0xed, 0x7b, 0x8f, 0x7d, 0x03, 0xe9, 0x96, 0xbc, 0x13, 0x74, 0xb5, 0x69, 0xc9, 0x8b, 0xd7,
-	0x37, 0x30, 0x5c, 0x91, 0xef, 0x5a, 0x95, 0x42, 0x6c, 0xeb, 0x2c, 0x3a, 0x8f, 0xe6, 0x93, 0x32,
-	0xb6, 0xb5, 0x3a, 0x03, 0x68, 0xc9, 0xb7, 0x48, 0x62, 0x91, 0xb3, 0x38, 0xf0, 0x2f, 0x44, 0xe7,
-	0x30, 0x5e, 0x37, 0x9b, 0x57, 0xa4, 0xa2, 0xfe, 0x99, 0xd5, 0x77, 0x90, 0x2e, 0xbd, 0x73, 0x58,
-	0x89, 0xf5, 0xae, 0x70, 0x5b, 0xaf, 0x2e, 0xe0, 0xa4, 0xfa, 0x24, 0x8f, 0x2c, 0x64, 0xdd, 0x6e,
-	0x1f, 0x98, 0x1d, 0x1f, 0x1e, 0x02, 0xd7, 0xb7, 0x90, 0x94, 0xc8, 0x5d, 0x23, 0x2a, 0x83, 0x11,
-	0x77, 0x55, 0x85, 0xcc, 0xc1, 0x3c, 0x2e, 0x0f, 0x52, 0x9d, 0xc2, 0x10, 0x89, 0x3c, 0xed, 0x7f,
-	0xd6, 0x8b, 0xab, 0xb7, 0x08, 0xfe, 0x2d, 0xd6, 0x85, 0xd2, 0x30, 0x5d, 0x12, 0x6e, 0x04, 0x4b,
-	0x7c, 0xe9, 0x90, 0x45, 0x25, 0x26, 0xac, 0xcc, 0x47, 0xa6, 0x6f, 0xd6, 0x83, 0x0f, 0xcf, 0x3d,
-	0x36, 0xf8, 0xa7, 0xe7, 0x12, 0xa6, 0x2b, 0x94, 0x05, 0xb3, 0xdd, 0xb9, 0x67, 0x74, 0xa2, 0x26,
-	0xe6, 0x30, 0x3a, 0xff, 0x6f, 0xbe, 0x6f, 0xd4, 0x03, 0x35, 0x87, 0x59, 0x5f, 0xf9, 0x7b, 0xe2,
-	0x58, 0xfc, 0x94, 0x84, 0xeb, 0x5f, 0xbf, 0x07, 0x00, 0x00, 0xff, 0xff, 0x2b, 0xde, 0x2c, 0x5b,
-	0x8f, 0x01, 0x00, 0x00,
+	// 639 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7d, 0x54, 0x4d, 0x6f, 0xd3, 0x40,
+	0x10, 0x8d, 0x63, 0x92, 0x26, 0x93, 0x0f, 0x92, 0x85, 0x43, 0x88, 0x54, 0xa8, 0x8c, 0x54, 0x22,
+	0x10, 0x56, 0x15, 0x0e, 0x40, 0x39, 0x85, 0x24, 0x44, 0x39, 0x34, 0x0d, 0x0e, 0x11, 0xbd, 0x59,
+	0xae, 0x3d, 0x69, 0xad, 0x3a, 0xb6, 0xd9, 0x5d, 0x57, 0xf4, 0x37, 0xf0, 0x3f, 0xb8, 0xf1, 0x1f,
+	0xd9, 0x5d, 0xdb, 0x49, 0xfa, 0xa1, 0xde, 0x76, 0xde, 0x9b, 0xb7, 0xb3, 0xf3, 0x66, 0x6c, 0x68,
+	0xae, 0x68, 0x14, 0x72, 0x0c, 0x3d, 0x33, 0xa6, 0x11, 0x8f, 0x8c, 0x8f, 0x50, 0x9a, 0xd0, 0x28,
+	0x89, 0x49, 0x13, 0x8a, 0xbe, 0xd7, 0xd1, 0x0e, 0xb4, 0x5e, 0xd5, 0x12, 0x27, 0xf2, 0x12, 0x40,
+	0x64, 0xc4, 0x48, 0xb9, 0x8f, 0xac, 0x53, 0x54, 0xf8, 0x0e, 0x62, 0x74, 0xa1, 0x32, 0x0f, 0x9c,
+	0x1b, 0xa4, 0x53, 0xef, 0xae, 0xd6, 0xf8, 0x5b, 0x84, 0xe6, 0x30, 0x0a, 0x43, 0x74, 0xb9, 0x1f,
+	0x85, 0xd3, 0x70, 0x15, 0x91, 0x77, 0xd0, 0x76, 0x37, 0x88, 0xcd, 0x38, 0xf5, 0xc3, 0x8b, 0x4c,
+	0xd1, 0xda, 0x12, 0x0b, 0x85, 0x13, 0x13, 0x9e, 0x51, 0xe4, 0xf4, 0xc6, 0x76, 0x56, 0x1c, 0xa9,
+	0xcd, 0x50, 0x64, 0x78, 0xe9, 0x23, 0x74, 0xab, 0xad, 0xa8, 0x81, 0x64, 0x16, 0x29, 0x41, 0x3e,
+	0x43, 0x65, 0x8d, 0xdc, 0xf1, 0x1c, 0xee, 0x74, 0xf4, 0x03, 0xbd, 0x57, 0xeb, 0xef, 0x9b, 0xb7,
+	0xeb, 0x9b, 0x27, 0x19, 0x3f, 0x0e, 0x85, 0xd8, 0xda, 0xa4, 0x93, 0xd7, 0xd0, 0x58, 0x3b, 0xdc,
+	0xbd, 0xb4, 0x63, 0xe1, 0x89, 0x7c, 0xd3, 0x13, 0x51, 0xa4, 0x62, 0xd5, 0x15, 0x38, 0x4f, 0x31,
+	0x22, 0x7a, 0x5d, 0x39, 0x41, 0x70, 0xee, 0xb8, 0x57, 0x9d, 0x92, 0xe2, 0x37, 0x71, 0xf7, 0x0b,
+	0x34, 0x6e, 0xdd, 0x4d, 0x5a, 0xa0, 0x5f, 0xe1, 0x4d, 0xd6, 0x9b, 0x3c, 0x92, 0xe7, 0x50, 0xba,
+	0x76, 0x82, 0x04, 0x33, 0x17, 0xd3, 0xe0, 0xb8, 0xf8, 0x49, 0x33, 0xfe, 0x69, 0x50, 0xb6, 0x90,
+	0x25, 0x01, 0x27, 0x1d, 0xd8, 0x63, 0x89, 0xeb, 0x22, 0x63, 0x4a, 0x5a, 0xb1, 0xf2, 0x50, 0xca,
+	0x91, 0xd2, 0x88, 0xe6, 0x72, 0x15, 0x90, 0x57, 0x50, 0xe3, 0x3c, 0xd8, 0x78, 0xa3, 0x2b, 0x6f,
+	0x40, 0x40, 0xb9, 0x29, 0xfb, 0x00, 0xf8, 0x3b, 0xf6, 0x29, 0x32, 0xdb, 0xe1, 0xaa, 0x2d, 0xdd,
+	0xaa, 0x66, 0xc8, 0x80, 0x67, 0x33, 0x2b, 0x6d, 0xe6, 0x2d, 0x8c, 0xa0, 0xb8, 0x8e, 0xae, 0xd1,
+	0xb3, 0xdd, 0x28, 0x09, 0x79, 0xa7, 0xac, 0x14, 0xf5, 0x0c, 0x1c, 0x4a, 0xcc, 0x38, 0x81, 0xf6,
+	0x80, 0x31, 0xff, 0x22, 0x5c, 0x63, 0xc8, 0x7f, 0xe2, 0xf9, 0x65, 0x14, 0x5d, 0x91, 0x43, 0xa8,
+	0xc6, 0x6a, 0x13, 0xec, 0x6c, 0x09, 0x6a, 0xfd, 0xaa, 0x99, 0xef, 0x86, 0x55, 0x89, 0xf3, 0x2d,
+	0x11, 0xc6, 0x24, 0x34, 0xc8, 0xba, 0x90, 0x47, 0x63, 0x1f, 0xaa, 0x79, 0x1e, 0x93, 0xb4, 0xef,
+	0xc9, 0xe6, 0x75, 0x49, 0x8b, 0xa3, 0xf1, 0x15, 0xea, 0x29, 0xbd, 0xe0, 0x0e, 0x4f, 0xd8, 0xbd,
+	0x15, 0x35, 0xa0, 0xc4, 0x04, 0x93, 0xfa, 0xda, 0xec, 0xd7, 0xcd, 0x6d, 0x36, 0x5a, 0x29, 0xf5,
+	0x76, 0x0c, 0xb5, 0x1d, 0x94, 0x34, 0xa0, 0x3a, 0x3b, 0xfd, 0x61, 0x7f, 0x3b, 0x5d, 0xce, 0x46,
+	0xad, 0x02, 0x01, 0x28, 0x7f, 0x5f, 0x8e, 0x97, 0xe3, 0x51, 0x4b, 0x93, 0xd4, 0x68, 0x3c, 0x9d,
+	0x8d, 0xc6, 0x67, 0x22, 0x2c, 0x92, 0x3a, 0x54, 0x06, 0x8b, 0xc5, 0x74, 0x32, 0x13, 0x91, 0xde,
+	0xff, 0xa3, 0x83, 0x3e, 0x98, 0x4f, 0x45, 0xc9, 0xc6, 0x90, 0xa2, 0xbc, 0x1f, 0x7f, 0x25, 0xc8,
+	0x38, 0x29, 0x9b, 0xea, 0xf3, 0xe9, 0xee, 0x99, 0xe9, 0x1c, 0x8d, 0x82, 0xcc, 0x19, 0x61, 0x80,
+	0x8f, 0xe6, 0xbc, 0x87, 0xc6, 0x04, 0xf9, 0xd6, 0x4b, 0xb2, 0x75, 0xac, 0xfb, 0xf4, 0xce, 0xee,
+	0x8a, 0xf4, 0x1e, 0xb4, 0xd2, 0x2b, 0x1f, 0x56, 0xec, 0x5c, 0x7c, 0x0c, 0x2f, 0x2c, 0xbc, 0xf0,
+	0x99, 0xf8, 0x3a, 0xee, 0x4f, 0x8a, 0x98, 0xf7, 0xb0, 0x5d, 0xed, 0x21, 0x34, 0x2d, 0x5c, 0x89,
+	0xf5, 0xb8, 0xcc, 0x5f, 0xfe, 0x70, 0x8d, 0x37, 0xd0, 0x14, 0x1f, 0x2a, 0x3a, 0xeb, 0x2c, 0x8d,
+	0x3d, 0xd0, 0x61, 0x4f, 0x3b, 0xd2, 0xa4, 0x13, 0xcb, 0xd8, 0x7b, 0xdc, 0xad, 0x3e, 0xb4, 0x85,
+	0x13, 0xbb, 0x73, 0x46, 0x46, 0x60, 0x53, 0x97, 0x75, 0x1b, 0xe6, 0x2e, 0x69, 0x14, 0x8e, 0xb4,
+	0xf3, 0xb2, 0xfa, 0x77, 0x7d, 0xf8, 0x0f, 0x6e, 0x36, 0xf0, 0xa6, 0xcd, 0x04, 0x00, 0x00,
 }
@@ -0,0 +1,120 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/redispb"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/gomodule/redigo/redis"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// MMF invocation protocols, set per MMF in 'mmfs.<name>.protocol'.
+const (
+	protocolJob  = "job"  // Run the MMF image as a k8s job (the default).
+	protocolHTTP = "http" // POST the profile to an MMF served over HTTP.
+)
+
+// defaultMmfTimeout is how long, in seconds, an MMF may run when neither
+// 'mmfs.<name>.timeout' nor 'interval.mmfTimeout' is set.
+const defaultMmfTimeout = 25
+
+// mmfTimeout returns how long the MMF at the given 'mmfs.<name>' config key
+// may run, regardless of protocol.  Pass an empty key for the default MMF.
+func mmfTimeout(cfg *viper.Viper, mmfCfg string) time.Duration {
+	if mmfCfg != "" && cfg.GetInt(mmfCfg+".timeout") > 0 {
+		return time.Duration(cfg.GetInt(mmfCfg+".timeout")) * time.Second
+	}
+	if cfg.GetInt("interval.mmfTimeout") > 0 {
+		return time.Duration(cfg.GetInt("interval.mmfTimeout")) * time.Second
+	}
+	return defaultMmfTimeout * time.Second
+}
+
+// httpMmf runs an MMF served over HTTP: it POSTs the profile MatchObject as
+// JSON to url and expects a MatchObject as JSON in response, which it then
+// hands to the MMLogic API's CreateProposal exactly as an MMF job would, as a
+// proposal or, if the MMF set its error field, as an error result.  Any
+// transport failure, including exceeding the timeout, is returned as an error.
+// The request metadata in md is sent as HTTP headers to the MMF, and as gRPC
+// metadata to the MMLogic API.
+func httpMmf(ctx context.Context, cfg *viper.Viper, pool *redis.Pool, url string, timeout time.Duration, profID string, propID string, resultsID string, md map[string]string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	profile := &pb.MatchObject{Id: profID}
+	err := redispb.UnmarshalFromRedis(ctx, pool, profile)
+	if err != nil {
+		return fmt.Errorf("failure retrieving profile from statestorage: %v", err)
+	}
+	body, err := (&jsonpb.Marshaler{}).MarshalToString(profile)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", url, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for header, value := range md {
+		req.Header.Set(header, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("http MMF request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("http MMF returned status %s", resp.Status)
+	}
+
+	result := &pb.MatchObject{}
+	err = (&jsonpb.Unmarshaler{AllowUnknownFields: true}).Unmarshal(resp.Body, result)
+	if err != nil {
+		return fmt.Errorf("http MMF returned an invalid MatchObject: %v", err)
+	}
+	if result.Error == "" {
+		result.Id = propID
+	} else {
+		result.Id = resultsID
+	}
+
+	conn, err := grpc.DialContext(ctx, cfg.GetString("api.mmlogic.hostname")+":"+cfg.GetString("api.mmlogic.port"), grpc.WithInsecure())
+	if err != nil {
+		return fmt.Errorf("failure connecting to the MMLogic API: %v", err)
+	}
+	defer conn.Close()
+
+	res, err := pb.NewMmLogicClient(conn).CreateProposal(metadata.NewOutgoingContext(ctx, metadata.New(md)), result)
+	if err != nil {
+		return fmt.Errorf("failure writing http MMF results: %v", err)
+	}
+	if !res.Success {
+		return fmt.Errorf("failure writing http MMF results: %v", res.Error)
+	}
+	return nil
+}
@@ -21,6 +21,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"os"
 	"strconv"
@@ -29,9 +30,11 @@ import (
 
 	"github.com/GoogleCloudPlatform/open-match/config"
 	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	"github.com/GoogleCloudPlatform/open-match/internal/mmfallow"
+	"github.com/GoogleCloudPlatform/open-match/internal/pb"
 	redisHelpers "github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/redispb"
 	"github.com/tidwall/gjson"
-	"go.opencensus.io/stats"
 	"go.opencensus.io/tag"
 
 	"github.com/gomodule/redigo/redis"
@@ -83,15 +86,20 @@ func init() {
 	}
 
 	// Configure OpenCensus exporter to Prometheus
-	// metrics.ConfigureOpenCensusPrometheusExporter expects that every OpenCensus view you
-	// want to register is in an array, so append any views you want from other
-	// packages to a single array here.
-	ocMmforcViews := DefaultMmforcViews // mmforc OpenCensus views.
+	// Packages that define OpenCensus measures add their views when they're
+	// initialized, so only views from libraries need to be added here.
+	// This package's own views are added here too, as its init functions may
+	// not have run yet.
+	metrics.AddViews(DefaultMmforcViews...) // mmforc OpenCensus views.
 	// Waiting on https://github.com/opencensus-integrations/redigo/pull/1
-	// ocMmforcViews = append(ocMmforcViews, redis.ObservabilityMetricViews...) // redis OpenCensus views.
-	mmforcLog.WithFields(log.Fields{"viewscount": len(ocMmforcViews)}).Info("Loaded OpenCensus views")
-	metrics.ConfigureOpenCensusPrometheusExporter(cfg, ocMmforcViews)
+	// metrics.AddViews(redis.ObservabilityMetricViews...) // redis OpenCensus views.
+	if err := metrics.RegisterAllViews(); err != nil {
+		mmforcLog.WithFields(log.Fields{"error": err.Error()}).Fatal("Failed to register OpenCensus views for metrics gathering")
+	}
+	metrics.ConfigureOpenCensusPrometheusExporter(cfg)
 
+	// Optionally serve runtime profiles on the debug port.
+	metrics.ConfigurePprof(cfg)
 }
 
 func main() {
@@ -276,9 +284,45 @@ func mmfunc(ctx context.Context, resultsID string, cfg *viper.Viper, clientset *
 		mmfuncLog.WithFields(log.Fields{"error": err.Error()}).Error("Failure retreiving profile from statestorage")
 		return
 	}
+	if stored, ok := profile[redispb.MatchObjectField]; ok {
+		// The profile was written whole, per 'statestorage.matchFormat'.
+		mo := &pb.MatchObject{}
+		if err = redispb.DecodeMatchObject(stored, mo); err != nil {
+			mmfuncLog.WithFields(log.Fields{"error": err.Error()}).Error("Failure decoding profile")
+			return
+		}
+		profile["properties"] = mo.Properties
+		profile["mmf"] = mo.Mmf
+	} else {
+		// Large properties may have been compressed by the Backend API.
+		profile["properties"], err = redispb.Decompress(profile["properties"])
+		if err != nil {
+			mmfuncLog.WithFields(log.Fields{"error": err.Error()}).Error("Failure decompressing profile properties")
+			return
+		}
+	}
 
-	// Got profile from state storage, make sure it is valid
-	if gjson.Valid(profile["properties"]) {
+	// If the profile names a registered MMF, run that one. Otherwise, fall
+	// back to looking for an image name in the profile properties.
+	mmfCfg := ""
+	protocol := protocolJob
+	if mmf := profile["mmf"]; mmf != "" {
+		mmfCfg = "mmfs." + mmf
+		if !cfg.IsSet(mmfCfg) {
+			// The backend API validates this before queueing the profile, so
+			// this only happens if the config changed in the meantime.
+			mmfuncLog.WithFields(log.Fields{"mmf": mmf}).Error("Profile requested an MMF that isn't registered in the config")
+			metrics.Record(ctx, mmforcMmfFailures.M(1))
+			mmfError(ctx, resultsID, "mmf '"+mmf+"' is not registered in the config", cfg, pool)
+			return
+		}
+		imageName = cfg.GetString(mmfCfg+".name") + ":" + cfg.GetString(mmfCfg+".tag")
+		mmfuncLog = mmfuncLog.WithFields(log.Fields{"containerImage": imageName, "mmf": mmf})
+		if cfg.GetString(mmfCfg+".protocol") != "" {
+			protocol = cfg.GetString(mmfCfg + ".protocol")
+		}
+	} else if gjson.Valid(profile["properties"]) {
+		// Got profile from state storage, make sure it is valid
 		profileImage := gjson.Get(profile["properties"], cfg.GetString("jsonkeys.mmfImage"))
 		if profileImage.Exists() {
 			imageName = profileImage.String()
@@ -287,24 +331,82 @@ func mmfunc(ctx context.Context, resultsID string, cfg *viper.Viper, clientset *
 			mmfuncLog.Warn("Failed to read image name from profile at configured json key, using default image instead")
 		}
 	}
-	mmfuncLog.Info("Attempting to create mmf k8s job")
+	// The backend API checks this before queueing the profile, but the
+	// profile or config may have changed in the meantime.
+	target := imageName
+	if protocol == protocolHTTP {
+		target = cfg.GetString(mmfCfg + ".url")
+	}
+	if err := mmfallow.Check(cfg, protocol, target); err != nil {
+		mmfuncLog.WithFields(log.Fields{"error": err.Error()}).Error("Profile selected a disallowed MMF")
+		metrics.Record(ctx, mmforcMmfFailures.M(1))
+		mmfError(ctx, resultsID, err.Error(), cfg, pool)
+		return
+	}
 
-	// Kick off k8s job
-	envvars := []apiv1.EnvVar{
-		{Name: "MMF_PROFILE_ID", Value: profID},
-		{Name: "MMF_PROPOSAL_ID", Value: propID},
-		{Name: "MMF_REQUEST_ID", Value: moID},
-		{Name: "MMF_ERROR_ID", Value: resultsID},
-		{Name: "MMF_TIMESTAMP", Value: timestamp},
+	// Pass on any request metadata the Backend API stored for the MMF.
+	md, err := redisHelpers.RetrieveAll(ctx, pool, "metadata."+resultsID)
+	if err != nil {
+		mmfuncLog.WithFields(log.Fields{"error": err.Error()}).Warn("Failure retreiving MMF metadata from statestorage")
+		md = nil
+	}
+
+	// Both protocols get the same time limit and report failures the same way.
+	timeout := mmfTimeout(cfg, mmfCfg)
+	switch protocol {
+	case protocolHTTP:
+		url := cfg.GetString(mmfCfg + ".url")
+		mmfuncLog = mmfuncLog.WithFields(log.Fields{"url": url})
+		mmfuncLog.Info("Attempting to call http mmf")
+		err = httpMmf(ctx, cfg, pool, url, timeout, profID, propID, resultsID, md)
+	case protocolJob:
+		mmfuncLog.Info("Attempting to create mmf k8s job")
+
+		// Kick off k8s job
+		envvars := []apiv1.EnvVar{
+			{Name: "MMF_PROFILE_ID", Value: profID},
+			{Name: "MMF_PROPOSAL_ID", Value: propID},
+			{Name: "MMF_REQUEST_ID", Value: moID},
+			{Name: "MMF_ERROR_ID", Value: resultsID},
+			{Name: "MMF_TIMESTAMP", Value: timestamp},
+		}
+		if len(md) > 0 {
+			mdJSON, _ := json.Marshal(md)
+			envvars = append(envvars, apiv1.EnvVar{Name: "MMF_METADATA", Value: string(mdJSON)})
+		}
+		err = submitJob(clientset, jobType, jobName, imageName, envvars, timeout)
+	default:
+		err = errors.New("unknown mmf protocol '" + protocol + "'")
 	}
-	err = submitJob(clientset, jobType, jobName, imageName, envvars)
 	if err != nil {
 		// Record failure & log
-		stats.Record(ctx, mmforcMmfFailures.M(1))
-		mmfuncLog.WithFields(log.Fields{"error": err.Error()}).Error("MMF job submission failure!")
+		metrics.Record(ctx, mmforcMmfFailures.M(1))
+		mmfuncLog.WithFields(log.Fields{"error": err.Error(), "protocol": protocol}).Error("MMF invocation failure!")
+		mmfError(ctx, resultsID, "MMF invocation failure: "+err.Error(), cfg, pool)
 	} else {
 		// Record Success
-		stats.Record(ctx, mmforcMmfs.M(1))
+		metrics.Record(ctx, mmforcMmfs.M(1))
+	}
+}
+
+// mmfError writes the details of an MMF failure to the results key the
+// Backend API is watching, so the backend client gets the actual cause instead
+// of waiting for a timeout.  Only done when 'mmfErrors.persist' is enabled.
+func mmfError(ctx context.Context, resultsID string, details string, cfg *viper.Viper, pool *redis.Pool) {
+	if !cfg.GetBool("mmfErrors.persist") {
+		return
+	}
+
+	mo := &pb.MatchObject{
+		Id:    resultsID,
+		Error: redispb.TruncateError(details, cfg.GetInt("mmfErrors.maxLength")),
+	}
+	err := redispb.MarshalToRedis(ctx, mo, pool, cfg)
+	if err != nil {
+		mmforcLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"resultsID": resultsID,
+		}).Error("Failure writing MMF error details to statestorage")
 	}
 }
 
@@ -324,10 +426,10 @@ func evaluator(ctx context.Context, cfg *viper.Viper, clientset *kubernetes.Clie
 
 	// Kick off k8s job
 	envvars := []apiv1.EnvVar{{Name: "MMF_TIMESTAMP", Value: timestamp}}
-	err = submitJob(clientset, jobType, jobName, imageName, envvars)
+	err = submitJob(clientset, jobType, jobName, imageName, envvars, 0)
 	if err != nil {
 		// Record failure & log
-		stats.Record(ctx, mmforcEvalFailures.M(1))
+		metrics.Record(ctx, mmforcEvalFailures.M(1))
 		mmforcLog.WithFields(log.Fields{
 			"error":          err.Error(),
 			"jobName":        jobName,
@@ -335,12 +437,13 @@ func evaluator(ctx context.Context, cfg *viper.Viper, clientset *kubernetes.Clie
 		}).Error("Evaluator job submission failure!")
 	} else {
 		// Record success
-		stats.Record(ctx, mmforcEvals.M(1))
+		metrics.Record(ctx, mmforcEvals.M(1))
 	}
 }
 
-// submitJob submits a job to kubernetes
-func submitJob(clientset *kubernetes.Clientset, jobType string, jobName string, imageName string, envvars []apiv1.EnvVar) error {
+// submitJob submits a job to kubernetes.  If deadline is non-zero, kubernetes
+// terminates the job if it is still running after that long.
+func submitJob(clientset *kubernetes.Clientset, jobType string, jobName string, imageName string, envvars []apiv1.EnvVar, deadline time.Duration) error {
 
 	// DEPRECATED: will be removed in a future vrsion.  Please switch to using the 'MMF_*' environment variables.
 	v := strings.Split(jobName, ".")
@@ -380,6 +483,10 @@ func submitJob(clientset *kubernetes.Clientset, jobType string, jobName string,
 		},
 	}
 
+	if deadline > 0 {
+		job.Spec.ActiveDeadlineSeconds = int64Ptr(int64(deadline.Seconds()))
+	}
+
 	// Get the namespace for the job from the current namespace, otherwise, use default
 	namespace := os.Getenv("METADATA_NAMESPACE")
 	if len(namespace) == 0 {
@@ -405,4 +512,5 @@ func submitJob(clientset *kubernetes.Clientset, jobType string, jobName string,
 
 // readability functions used by generateJobSpec
 func int32Ptr(i int32) *int32 { return &i }
+func int64Ptr(i int64) *int64 { return &i }
 func strPtr(i string) *string { return &i }
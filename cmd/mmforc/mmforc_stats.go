@@ -88,6 +88,14 @@ var (
 // cardinality" error, chances are you forgot to set the tags specified in the
 // view for a given measure when you tried to do a stats.Record()
 var (
+	mmforcLogCountView = &view.View{
+		Name:        "log_lines/total",
+		Measure:     MmforcLogLines,
+		Description: "The number of lines logged",
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{KeySeverity},
+	}
+
 	mmforcMmfsCountView = &view.View{
 		Name:        "mmforc/mmfs",
 		Measure:     mmforcMmfs,
@@ -121,6 +129,7 @@ var (
 
 // DefaultMmforcViews are the default matchmaker orchestrator OpenCensus measure views.
 var DefaultMmforcViews = []*view.View{
+	mmforcLogCountView,
 	mmforcEvalsCountView,
 	mmforcMmfFailuresCountView,
 	mmforcMmfsCountView,
@@ -27,16 +27,19 @@ import (
 	"math"
 	"net"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/GoogleCloudPlatform/open-match/internal/affinity"
+	"github.com/GoogleCloudPlatform/open-match/internal/grpcutil"
 	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
 	mmlogic "github.com/GoogleCloudPlatform/open-match/internal/pb"
 	"github.com/GoogleCloudPlatform/open-match/internal/set"
 	redishelpers "github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis"
 	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/ignorelist"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/playerq"
 	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/redispb"
 	log "github.com/sirupsen/logrus"
-	"go.opencensus.io/stats"
 	"go.opencensus.io/tag"
 
 	"github.com/gomodule/redigo/redis"
@@ -67,9 +70,12 @@ type mmlogicAPI MmlogicAPI
 
 // New returns an instantiated srvice
 func New(cfg *viper.Viper, pool *redis.Pool) *MmlogicAPI {
+	opts := []grpc.ServerOption{grpc.StatsHandler(&ocgrpc.ServerHandler{})}
+	opts = append(opts, grpcutil.ServerOptions(cfg, "mmlogic")...)
+
 	s := MmlogicAPI{
 		pool: pool,
-		grpc: grpc.NewServer(grpc.StatsHandler(&ocgrpc.ServerHandler{})),
+		grpc: grpc.NewServer(opts...),
 		cfg:  cfg,
 	}
 
@@ -109,10 +115,6 @@ func (s *MmlogicAPI) Open() error {
 // mmlogicapi/proto/mmlogic.proto
 func (s *mmlogicAPI) GetProfile(c context.Context, profile *mmlogic.MatchObject) (*mmlogic.MatchObject, error) {
 
-	// Get redis connection from pool
-	redisConn := s.pool.Get()
-	defer redisConn.Close()
-
 	// Create context for tagging OpenCensus metrics.
 	funcName := "GetProfile"
 	fnCtx, _ := tag.New(c, tag.Insert(KeyMethod, funcName))
@@ -128,14 +130,14 @@ func (s *mmlogicAPI) GetProfile(c context.Context, profile *mmlogic.MatchObject)
 			"profileid": profile.Id,
 		}).Error("State storage error")
 
-		stats.Record(fnCtx, MlGrpcErrors.M(1))
+		metrics.Record(fnCtx, MlGrpcErrors.M(1))
 		return profile, err
 	}
 	mlLog.WithFields(log.Fields{"profileid": profile.Id}).Debug("Retrieved profile from state storage")
 
 	mlLog.Debug(profile)
 
-	stats.Record(fnCtx, MlGrpcRequests.M(1))
+	metrics.Record(fnCtx, MlGrpcRequests.M(1))
 	//return out, err
 	return profile, err
 
@@ -149,32 +151,65 @@ func (s *mmlogicAPI) CreateProposal(c context.Context, prop *mmlogic.MatchObject
 	list := "proposed"
 	proposalq := s.cfg.GetString("queues.proposals.name")
 
-	// Get redis connection from pool
-	redisConn := s.pool.Get()
-	defer redisConn.Close()
-
 	// Create context for tagging OpenCensus metrics.
 	funcName := "CreateProposal"
 	fnCtx, _ := tag.New(c, tag.Insert(KeyMethod, funcName))
 
+	// Get redis connection from pool, waiting no longer than the client will.
+	redisConn, err := grpcutil.GetConn(c, s.pool)
+	if err != nil {
+		mlLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage connection error")
+
+		metrics.Record(fnCtx, MlGrpcErrors.M(1))
+		return &mmlogic.Result{Success: false, Error: err.Error()}, err
+	}
+	defer redisConn.Close()
+
+	// A match made for a dry run from the Backend API skips evaluation: it
+	// goes straight to the results key, and its players aren't proposed.
+	// Proposal IDs are 'proposal.<timestamp>.<moID>.<profID>'.
+	dryRun := false
+	if ids := strings.Split(prop.Id, "."); len(prop.Error) == 0 && len(ids) == 4 && ids[0] == "proposal" {
+		dryRun, err = isDryRun(redisConn, ids[3])
+		if err != nil {
+			mlLog.WithFields(log.Fields{
+				"error":     err.Error(),
+				"component": "statestorage",
+				"profile":   ids[3],
+			}).Error("State storage error")
+
+			metrics.Record(fnCtx, MlGrpcErrors.M(1))
+			return &mmlogic.Result{Success: false, Error: err.Error()}, err
+		}
+		if dryRun {
+			prop.Id = ids[2] + "." + ids[3]
+		}
+	}
+
 	// Log what kind of results we received.
-	cpLog := mlLog.WithFields(log.Fields{"id": prop.Id})
+	cpLog := mlLog.WithFields(log.Fields{"id": prop.Id, "dryRun": dryRun})
 	if len(prop.Error) == 0 {
 		cpLog.Info("writing MMF propsal to state storage")
 	} else {
 		cpLog.Info("writing MMF error to state storage")
 	}
 
+	// Bound the size of any error details the MMF sent back.
+	prop.Error = redispb.TruncateError(prop.Error, s.cfg.GetInt("mmfErrors.maxLength"))
+
 	// Write all non-id fields from the protobuf message to state storage.
-	err := redispb.MarshalToRedis(c, prop, s.pool)
+	err = redispb.MarshalToRedis(c, prop, s.pool, s.cfg)
 	if err != nil {
-		stats.Record(fnCtx, MlGrpcErrors.M(1))
+		metrics.Record(fnCtx, MlGrpcErrors.M(1))
 		return &mmlogic.Result{Success: false, Error: err.Error()}, err
 	}
 
 	// Proposals need two more actions: players added to ignorelist, and adding
 	// the proposalkey to the proposal queue for the evaluator to read.
-	if len(prop.Error) == 0 {
+	if len(prop.Error) == 0 && !dryRun {
 		// look for players to add to the ignorelist
 		cpLog.Info("parsing rosters")
 		playerIDs := make([]string, 0)
@@ -198,7 +233,7 @@ func (s *mmlogicAPI) CreateProposal(c context.Context, prop *mmlogic.MatchObject
 				}).Error("State storage error")
 
 				// record error.
-				stats.Record(fnCtx, MlGrpcErrors.M(1))
+				metrics.Record(fnCtx, MlGrpcErrors.M(1))
 				return &mmlogic.Result{Success: false, Error: err.Error()}, err
 			}
 		} else {
@@ -217,7 +252,7 @@ func (s *mmlogicAPI) CreateProposal(c context.Context, prop *mmlogic.MatchObject
 			pqLog.WithFields(log.Fields{"error": err.Error()}).Error("State storage error")
 
 			// record error.
-			stats.Record(fnCtx, MlGrpcErrors.M(1))
+			metrics.Record(fnCtx, MlGrpcErrors.M(1))
 			return &mmlogic.Result{Success: false, Error: err.Error()}, err
 		}
 	}
@@ -235,11 +270,11 @@ func (s *mmlogicAPI) CreateProposal(c context.Context, prop *mmlogic.MatchObject
 		cmLog.WithFields(log.Fields{"error": err.Error()}).Error("State storage error")
 
 		// record error.
-		stats.Record(fnCtx, MlGrpcErrors.M(1))
+		metrics.Record(fnCtx, MlGrpcErrors.M(1))
 		return &mmlogic.Result{Success: false, Error: err.Error()}, err
 	}
 
-	stats.Record(fnCtx, MlGrpcRequests.M(1))
+	metrics.Record(fnCtx, MlGrpcRequests.M(1))
 	return &mmlogic.Result{Success: true, Error: ""}, err
 }
 
@@ -266,6 +301,9 @@ func (s *mmlogicAPI) GetPlayerPool(pool *mmlogic.PlayerPool, stream mmlogic.MmLo
 
 	// One working Roster per filter in the set.  Combined at the end.
 	filteredRosters := make(map[string][]string)
+	// How many players each filter matched, reported in the pool stats so
+	// MMF authors can see which filters are narrowing the pool.
+	filterCounts := make(map[string]int64)
 	// Temp store the results so we can also populate some field values in the final return roster.
 	filteredResults := make(map[string]map[string]int64)
 	overlap := make([]string, 0)
@@ -277,6 +315,11 @@ func (s *mmlogicAPI) GetPlayerPool(pool *mmlogic.PlayerPool, stream mmlogic.MmLo
 		filterStart := time.Now()
 		results, err := s.applyFilter(ctx, thisFilter)
 		thisFilter.Stats = &mmlogic.Stats{Count: int64(len(results)), Elapsed: time.Since(filterStart).Seconds()}
+		if thisFilter.Name != "" {
+			filterCounts[thisFilter.Name] = int64(len(results))
+		} else {
+			filterCounts[thisFilter.Attribute] = int64(len(results))
+		}
 		mlLog.WithFields(log.Fields{
 			"count":      int64(len(results)),
 			"elapsed":    time.Since(filterStart).Seconds(),
@@ -298,14 +341,18 @@ func (s *mmlogicAPI) GetPlayerPool(pool *mmlogic.PlayerPool, stream mmlogic.MmLo
 				}).Warn("returning empty pool")
 
 				// Fill in the stats for this player pool.
-				pool.Stats = &mmlogic.Stats{Count: int64(len(results)), Elapsed: time.Since(filterStart).Seconds()}
+				pool.Stats = &mmlogic.Stats{
+					Count:        int64(len(results)),
+					Elapsed:      time.Since(filterStart).Seconds(),
+					FilterCounts: filterCounts,
+				}
 
 				// Send the empty pool and exit.
 				if err = stream.Send(pool); err != nil {
-					stats.Record(fnCtx, MlGrpcErrors.M(1))
+					metrics.Record(fnCtx, MlGrpcErrors.M(1))
 					return err
 				}
-				stats.Record(fnCtx, MlGrpcRequests.M(1))
+				metrics.Record(fnCtx, MlGrpcRequests.M(1))
 				return nil
 			}
 
@@ -345,6 +392,21 @@ func (s *mmlogicAPI) GetPlayerPool(pool *mmlogic.PlayerPool, stream mmlogic.MmLo
 	playerList := set.Difference(overlap, il) // removes ignorelist from the Roster
 	mlLog.WithFields(log.Fields{"count": len(playerList)}).Debug("Final Pool size")
 
+	// Read the players' affinity hints so MMFs can honor them.
+	var hints map[string]affinity.Hints
+	if pool.AffinityHints && len(playerList) > 0 {
+		hints, err = s.affinityHints(ctx, playerList)
+		if err != nil {
+			mlLog.WithFields(log.Fields{
+				"error":     err.Error(),
+				"component": "statestorage",
+				"pool":      pool.Name,
+			}).Error("Failed to retrieve player affinity hints")
+			metrics.Record(fnCtx, MlGrpcErrors.M(1))
+			return err
+		}
+	}
+
 	// Reformat the playerList as a gRPC PlayerPool message. Send partial results as we go.
 	// This is pretty agressive in the partial result 'page'
 	// sizes it sends, and that is partially because it assumes you're running
@@ -354,7 +416,12 @@ func (s *mmlogicAPI) GetPlayerPool(pool *mmlogic.PlayerPool, stream mmlogic.MmLo
 	pageCount := int(math.Ceil((float64(len(playerList)) / float64(pageSize)))) // Divides and rounds up on any remainder
 	//TODO: change if removing filtersets from rosters in favor of it being in pools
 	partialRoster := mmlogic.Roster{Name: fmt.Sprintf("%v.partialRoster", pool.Name)}
-	pool.Stats = &mmlogic.Stats{Count: int64(len(playerList)), Elapsed: time.Since(fnStart).Seconds()}
+	pool.Stats = &mmlogic.Stats{
+		Count:             int64(len(playerList)),
+		Elapsed:           time.Since(fnStart).Seconds(),
+		FilterCounts:      filterCounts,
+		IntersectionCount: int64(len(overlap)),
+	}
 	for i := 0; i < len(playerList); i++ {
 		// Check if we've filled in enough players to fill a page of results.
 		if (i > 0 && i%pageSize == 0) || i == (len(playerList)-1) {
@@ -366,7 +433,7 @@ func (s *mmlogicAPI) GetPlayerPool(pool *mmlogic.PlayerPool, stream mmlogic.MmLo
 				Roster:  &partialRoster,
 			}
 			if err = stream.Send(poolChunk); err != nil {
-				stats.Record(fnCtx, MlGrpcErrors.M(1))
+				metrics.Record(fnCtx, MlGrpcErrors.M(1))
 				return err
 			}
 			partialRoster.Players = []*mmlogic.Player{}
@@ -374,6 +441,10 @@ func (s *mmlogicAPI) GetPlayerPool(pool *mmlogic.PlayerPool, stream mmlogic.MmLo
 
 		// Add one additional player result to the partial pool.
 		player := &mmlogic.Player{Id: playerList[i], Attributes: []*mmlogic.Player_Attribute{}}
+		if h, ok := hints[playerList[i]]; ok {
+			player.Affinity = h.Affinity
+			player.AntiAffinity = h.AntiAffinity
+		}
 		// Collect all the filtered attributes into the player protobuf.
 		for attribute, fr := range filteredResults {
 			if value, ok := fr[playerList[i]]; ok {
@@ -386,10 +457,21 @@ func (s *mmlogicAPI) GetPlayerPool(pool *mmlogic.PlayerPool, stream mmlogic.MmLo
 
 	mlLog.WithFields(log.Fields{"count": len(playerList), "pool": pool.Name}).Debug("player pool streaming complete")
 
-	stats.Record(fnCtx, MlGrpcRequests.M(1))
+	metrics.Record(fnCtx, MlGrpcRequests.M(1))
 	return nil
 }
 
+// affinityHints reads the affinity hints of every player in playerIDs from
+// state storage.
+func (s *mmlogicAPI) affinityHints(c context.Context, playerIDs []string) (map[string]affinity.Hints, error) {
+	redisConn, err := grpcutil.GetConn(c, s.pool)
+	if err != nil {
+		return nil, err
+	}
+	defer redisConn.Close()
+	return affinity.Retrieve(redisConn, s.cfg, playerIDs)
+}
+
 // applyFilter is a sequential query of every entry in the Redis sorted set
 // that fall beween the minimum and maximum values passed in through the filter
 // argument.  This can be likely sped up later using concurrent access, but
@@ -412,7 +494,10 @@ func (s *mmlogicAPI) applyFilter(c context.Context, filter *mmlogic.Filter) (map
 	mlLog.WithFields(log.Fields{"filterField": filter.Attribute}).Debug("In applyFilter")
 
 	// Get redis connection from pool
-	redisConn := s.pool.Get()
+	redisConn, err := grpcutil.GetConn(c, s.pool)
+	if err != nil {
+		return nil, err
+	}
 	defer redisConn.Close()
 
 	// Check how many expected matches for this filter before we start retrieving.
@@ -472,6 +557,15 @@ func (s *mmlogicAPI) applyFilter(c context.Context, filter *mmlogic.Filter) (map
 			}).Error("statestorage error")
 		}
 
+		// Optionally remove the entries of players whose record is gone, so
+		// indices heal themselves as they're queried.
+		if err == nil && s.cfg.GetBool("indexCleanup.enabled") {
+			removed := s.pruneIndex(c, redisConn, filter.Attribute, results)
+			// The entries after the removed ones moved up, so the next page
+			// starts that many entries earlier.
+			offset -= removed
+		}
+
 		// Increment the offset for the next query by the 'count' config value
 		offset = offset + s.cfg.GetInt("redis.queryArgs.count")
 
@@ -500,6 +594,36 @@ func (s *mmlogicAPI) applyFilter(c context.Context, filter *mmlogic.Filter) (map
 	return pool, nil
 }
 
+// pruneIndex removes the players in results whose record no longer exists
+// from index and from results, and returns how many it removed.  Failures
+// are only logged, as the filter's results are still usable.
+func (s *mmlogicAPI) pruneIndex(c context.Context, redisConn redis.Conn, index string, results map[string]int64) int {
+	playerIDs := make([]string, 0, len(results))
+	for playerID := range results {
+		playerIDs = append(playerIDs, playerID)
+	}
+	removed, err := playerq.PruneIndex(redisConn, index, playerIDs)
+	if err != nil {
+		mlLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+			"index":     index,
+		}).Warn("Failed to remove stale index entries")
+		return 0
+	}
+	for _, playerID := range removed {
+		delete(results, playerID)
+	}
+	if len(removed) > 0 {
+		mlLog.WithFields(log.Fields{
+			"index": index,
+			"count": len(removed),
+		}).Debug("Removed index entries of players without a record")
+		metrics.Record(c, MlIndexEntriesPruned.M(int64(len(removed))))
+	}
+	return len(removed)
+}
+
 // GetAllIgnoredPlayers is this service's implementation of the gRPC call defined in
 // mmlogicapi/proto/mmlogic.proto
 // This is a wrapper around allIgnoreLists, and converts the []string return
@@ -512,7 +636,7 @@ func (s *mmlogicAPI) GetAllIgnoredPlayers(c context.Context, in *mmlogic.IlInput
 
 	il, err := s.allIgnoreLists(c, in)
 
-	stats.Record(fnCtx, MlGrpcRequests.M(1))
+	metrics.Record(fnCtx, MlGrpcRequests.M(1))
 	return createRosterfromPlayerIds(il), err
 }
 
@@ -523,14 +647,23 @@ func (s *mmlogicAPI) ListIgnoredPlayers(c context.Context, olderThan *mmlogic.Il
 	// TODO: is this supposed to able to take any list?
 	ilName := "proposed"
 
-	// Get redis connection from pool
-	redisConn := s.pool.Get()
-	defer redisConn.Close()
-
 	// Create context for tagging OpenCensus metrics.
 	funcName := "ListIgnoredPlayers"
 	fnCtx, _ := tag.New(c, tag.Insert(KeyMethod, funcName))
 
+	// Get redis connection from pool, waiting no longer than the client will.
+	redisConn, err := grpcutil.GetConn(c, s.pool)
+	if err != nil {
+		mlLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage connection error")
+
+		metrics.Record(fnCtx, MlGrpcErrors.M(1))
+		return &mmlogic.Roster{}, err
+	}
+	defer redisConn.Close()
+
 	mlLog.WithFields(log.Fields{"ignorelist": ilName}).Info("Attempting to get ignorelist")
 
 	// retreive ignore list
@@ -542,13 +675,13 @@ func (s *mmlogicAPI) ListIgnoredPlayers(c context.Context, olderThan *mmlogic.Il
 			"key":       ilName,
 		}).Error("State storage error")
 
-		stats.Record(fnCtx, MlGrpcErrors.M(1))
+		metrics.Record(fnCtx, MlGrpcErrors.M(1))
 		return &mmlogic.Roster{}, err
 	}
 	// TODO: fix this
 	mlLog.Debug(fmt.Sprintf("Retreival success %v", il))
 
-	stats.Record(fnCtx, MlGrpcRequests.M(1))
+	metrics.Record(fnCtx, MlGrpcRequests.M(1))
 	return createRosterfromPlayerIds(il), err
 }
 
@@ -556,7 +689,10 @@ func (s *mmlogicAPI) ListIgnoredPlayers(c context.Context, olderThan *mmlogic.Il
 func (s *mmlogicAPI) allIgnoreLists(c context.Context, in *mmlogic.IlInput) (allIgnored []string, err error) {
 
 	// Get redis connection from pool
-	redisConn := s.pool.Get()
+	redisConn, err := grpcutil.GetConn(c, s.pool)
+	if err != nil {
+		return nil, err
+	}
 	defer redisConn.Close()
 
 	mlLog.Info("Attempting to get and combine ignorelists")
@@ -573,11 +709,28 @@ func (s *mmlogicAPI) allIgnoreLists(c context.Context, in *mmlogic.IlInput) (all
 		allIgnored = set.Union(allIgnored, thisIl)
 	}
 
+	// Players whose TTL has passed are ignored from the moment their record
+	// expires, even if their index entries haven't been purged yet.
+	expired, err := ignorelist.Retrieve(redisConn, viper.New(), playerq.ExpiryIndex)
+	if err != nil {
+		return nil, err
+	}
+	allIgnored = set.Union(allIgnored, expired)
+
 	return allIgnored, err
 }
 
 // Functions for getting or setting player IDs to/from rosters
 // Probably should get moved to an internal module in a future version.
+// isDryRun returns true if the stored profile was requested as a dry run.
+func isDryRun(redisConn redis.Conn, profileID string) (bool, error) {
+	flag, err := redis.String(redisConn.Do("HGET", profileID, "dryrun"))
+	if err == redis.ErrNil {
+		return false, nil
+	}
+	return flag == "true", err
+}
+
 func getPlayerIdsFromRoster(r *mmlogic.Roster) []string {
 	playerIDs := make([]string, 0)
 	for _, p := range r.Players {
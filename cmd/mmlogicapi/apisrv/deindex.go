@@ -0,0 +1,80 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package apisrv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/grpcutil"
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	mmlogic "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/ignorelist"
+	log "github.com/sirupsen/logrus"
+	"go.opencensus.io/tag"
+)
+
+// DeindexPlayers is this service's implementation of the gRPC call defined in
+// mmlogicapi/proto/mmlogic.proto
+func (s *mmlogicAPI) DeindexPlayers(c context.Context, roster *mmlogic.Roster) (*mmlogic.Result, error) {
+	// Players are deindexed the same way proposed players are.
+	list := "proposed"
+
+	// Create context for tagging OpenCensus metrics.
+	funcName := "DeindexPlayers"
+	fnCtx, _ := tag.New(c, tag.Insert(KeyMethod, funcName))
+
+	playerIDs := getPlayerIdsFromRoster(roster)
+	if len(playerIDs) == 0 {
+		err := fmt.Errorf("%w: DeindexPlayers requires at least one player", grpcutil.ErrInvalidArgument)
+		metrics.Record(fnCtx, MlGrpcErrors.M(1))
+		return &mmlogic.Result{Success: false, Error: err.Error()}, err
+	}
+
+	// Get redis connection from pool, waiting no longer than the client will.
+	redisConn, err := grpcutil.GetConn(c, s.pool)
+	if err != nil {
+		mlLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("State storage connection error")
+
+		metrics.Record(fnCtx, MlGrpcErrors.M(1))
+		return &mmlogic.Result{Success: false, Error: err.Error()}, err
+	}
+	defer redisConn.Close()
+
+	if err := ignorelist.Add(redisConn, list, playerIDs); err != nil {
+		mlLog.WithFields(log.Fields{
+			"error":      err.Error(),
+			"component":  "statestorage",
+			"ignorelist": list,
+		}).Error("State storage error")
+
+		metrics.Record(fnCtx, MlGrpcErrors.M(1))
+		return &mmlogic.Result{Success: false, Error: err.Error()}, err
+	}
+
+	mlLog.WithFields(log.Fields{
+		"count":      len(playerIDs),
+		"ignorelist": list,
+	}).Info("deindexed players")
+
+	metrics.Record(fnCtx, MlGrpcRequests.M(1))
+	return &mmlogic.Result{Success: true, Error: ""}, nil
+}
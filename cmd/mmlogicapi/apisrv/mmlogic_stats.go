@@ -16,6 +16,7 @@ limitations under the License.
 package apisrv
 
 import (
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
@@ -68,6 +69,9 @@ var (
 
 	// Failure instrumentation
 	MlFailures = stats.Int64("mmlogicapi/failures_total", "Number of Frontend API failures", "1")
+
+	// Index instrumentation
+	MlIndexEntriesPruned = stats.Int64("mmlogicapi/index_entries_pruned_total", "Number of index entries removed because the player's record no longer exists", "1")
 )
 
 var (
@@ -127,6 +131,13 @@ var (
 		Description: "The number of failures",
 		Aggregation: view.Count(),
 	}
+
+	MlIndexEntriesPrunedView = &view.View{
+		Name:        "mmlogic/index/pruned_entries",
+		Measure:     MlIndexEntriesPruned,
+		Description: "The number of index entries removed because the player's record no longer exists",
+		Aggregation: view.Sum(),
+	}
 )
 
 // DefaultMmlogicAPIViews are the default mmlogic API OpenCensus measure views.
@@ -136,4 +147,9 @@ var DefaultMmlogicAPIViews = []*view.View{
 	MlErrorCountView,
 	MlLogCountView,
 	MlFailureCountView,
+	MlIndexEntriesPrunedView,
+}
+
+func init() {
+	metrics.AddViews(DefaultMmlogicAPIViews...)
 }
@@ -0,0 +1,63 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package apisrv
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/grpcutil"
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	mmlogic "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/redispb"
+	log "github.com/sirupsen/logrus"
+	"go.opencensus.io/tag"
+)
+
+// ReportProgress is this service's implementation of the gRPC call defined in
+// mmlogicapi/proto/mmlogic.proto
+func (s *mmlogicAPI) ReportProgress(c context.Context, p *mmlogic.MatchProgress) (*mmlogic.Result, error) {
+	// Create context for tagging OpenCensus metrics.
+	funcName := "ReportProgress"
+	fnCtx, _ := tag.New(c, tag.Insert(KeyMethod, funcName))
+
+	if p.Id == "" || p.Stage == "" {
+		err := fmt.Errorf("%w: ReportProgress requires an id and a stage", grpcutil.ErrInvalidArgument)
+		metrics.Record(fnCtx, MlGrpcErrors.M(1))
+		return &mmlogic.Result{Success: false, Error: err.Error()}, err
+	}
+
+	// Only the Backend API attaches match results.
+	p.Match = nil
+	p.Timestamp = time.Now().UnixNano() / int64(time.Millisecond)
+
+	if err := redispb.PublishProgress(c, s.pool, p); err != nil {
+		mlLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+			"id":        p.Id,
+		}).Error("State storage error publishing MMF progress")
+
+		metrics.Record(fnCtx, MlGrpcErrors.M(1))
+		return &mmlogic.Result{Success: false, Error: err.Error()}, err
+	}
+
+	metrics.Record(fnCtx, MlGrpcRequests.M(1))
+	return &mmlogic.Result{Success: true, Error: ""}, nil
+}
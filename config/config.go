@@ -18,6 +18,11 @@ limitations under the License.
 package config
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"go.opencensus.io/stats"
@@ -65,8 +70,32 @@ var (
 	}
 )
 
+func init() {
+	metrics.AddViews(CfgVarCountView)
+}
+
+const (
+	// OverrideEnvVar names the environment variable holding the path of a
+	// JSON config file to merge over the base config file.
+	OverrideEnvVar = "OM_CONFIG_OVERRIDE"
+	// defaultOverrideFile is merged over the base config file, if it exists
+	// in the working directory and OverrideEnvVar isn't set.
+	defaultOverrideFile = "matchmaker_config.override.json"
+	// envPrefix prefixes the environment variables that set any config key.
+	envPrefix = "OM"
+)
+
 // Read reads a config file into a viper.Viper instance and associates environment vars defined in
 // config.envMappings
+//
+// Config is layered from these sources, each taking precedence over the ones
+// before it:
+//   - the base config file, matchmaker_config.json
+//   - an optional override file, named by the OM_CONFIG_OVERRIDE env var or
+//     else matchmaker_config.override.json, holding only the keys it changes
+//   - environment variables: OM_ followed by the key, upper-cased, with dots
+//     replaced by underscores (e.g. OM_ASSIGNMENTS_TIMEOUT), and the k8s
+//     variables in config.envMappings
 func Read() (*viper.Viper, error) {
 
 	// Viper config management initialization
@@ -82,6 +111,19 @@ func Read() (*viper.Viper, error) {
 		}).Fatal("Fatal error reading config file")
 	}
 
+	// Merge the optional override file over the base config.
+	err = mergeOverride(cfg)
+	if err != nil {
+		cfgLog.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Fatal("Fatal error reading override config file")
+	}
+
+	// Let any key be set by an environment variable, e.g. OM_REDIS_PORT.
+	cfg.SetEnvPrefix(envPrefix)
+	cfg.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	cfg.AutomaticEnv()
+
 	// Bind this envvars to viper config vars.
 	// https://github.com/spf13/viper#working-with-environment-variables
 	// One important thing to recognize when working with ENV variables is
@@ -111,3 +153,30 @@ func Read() (*viper.Viper, error) {
 
 	return cfg, err
 }
+
+// mergeOverride merges the override config file over cfg.  A missing
+// override file is only an error if it was named by OverrideEnvVar.
+func mergeOverride(cfg *viper.Viper) error {
+	path := os.Getenv(OverrideEnvVar)
+	required := path != ""
+	if !required {
+		path = defaultOverrideFile
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) && !required {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := cfg.MergeConfig(f); err != nil {
+		return fmt.Errorf("merging %v: %v", path, err)
+	}
+	cfgLog.WithFields(log.Fields{
+		"file": path,
+	}).Info("Merged override config file over base config")
+	return nil
+}
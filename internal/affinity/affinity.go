@@ -0,0 +1,174 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package affinity provides helpers for MMFs to honor players' social
+// constraints: the players they want to be matched with (affinity, e.g. their
+// party) and the players they must not be matched with (anti-affinity, e.g.
+// their blocklist).
+package affinity
+
+import (
+	"sort"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/statestorage/redis/playerq"
+	"github.com/gomodule/redigo/redis"
+	"github.com/spf13/viper"
+	"github.com/tidwall/gjson"
+)
+
+// Hints are the player IDs a player wants to be, and must not be, matched
+// with.
+type Hints struct {
+	Affinity     []string
+	AntiAffinity []string
+}
+
+// FromProperties reads a player's hints from their JSON properties, at the
+// 'jsonkeys.affinity' and 'jsonkeys.antiAffinity' config keys.  Missing or
+// unconfigured lists are empty.
+func FromProperties(cfg *viper.Viper, properties string) Hints {
+	return Hints{
+		Affinity:     stringArray(properties, cfg.GetString("jsonkeys.affinity")),
+		AntiAffinity: stringArray(properties, cfg.GetString("jsonkeys.antiAffinity")),
+	}
+}
+
+// stringArray returns the strings in the JSON array at key.
+func stringArray(properties string, key string) (out []string) {
+	if key == "" {
+		return nil
+	}
+	for _, v := range gjson.Get(properties, key).Array() {
+		if id := v.String(); id != "" {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// Retrieve reads the hints of each player from state storage.  Players that
+// no longer exist have no hints.
+func Retrieve(redisConn redis.Conn, cfg *viper.Viper, playerIDs []string) (map[string]Hints, error) {
+	for _, id := range playerIDs {
+		redisConn.Send("HGETALL", id)
+	}
+	if err := redisConn.Flush(); err != nil {
+		return nil, err
+	}
+
+	hints := make(map[string]Hints, len(playerIDs))
+	for _, id := range playerIDs {
+		fields, err := redis.StringMap(redisConn.Receive())
+		if err != nil {
+			return nil, err
+		}
+		if properties, ok := playerq.PropertiesJSON(fields); ok {
+			hints[id] = FromProperties(cfg, properties)
+		}
+	}
+	return hints, nil
+}
+
+// Blocked reports whether either of players a and b has the other in their
+// anti-affinity list.
+func Blocked(hints map[string]Hints, a string, b string) bool {
+	return contains(hints[a].AntiAffinity, b) || contains(hints[b].AntiAffinity, a)
+}
+
+func contains(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ExcludeBlocked returns the candidates that can be matched with every one of
+// the selected players, i.e. that neither block, nor are blocked by, any of
+// them.  Use it to narrow a pool as players are added to a roster.
+func ExcludeBlocked(candidates []string, selected []string, hints map[string]Hints) (out []string) {
+	out = make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		ok := true
+		for _, s := range selected {
+			if Blocked(hints, c, s) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Groups partitions players into the groups that should be matched together:
+// players are in the same group if either lists the other in their affinity
+// list, directly or through other players in the pool.  Affinity for players
+// not in the pool is ignored.  Each group keeps the order of players, and
+// groups are ordered by their first player.  A group may contain players that
+// block one another; check it with Conflicted before placing it.
+func Groups(players []string, hints map[string]Hints) [][]string {
+	parent := make(map[string]string, len(players))
+	for _, p := range players {
+		parent[p] = p
+	}
+	var find func(string) string
+	find = func(p string) string {
+		if parent[p] != p {
+			parent[p] = find(parent[p])
+		}
+		return parent[p]
+	}
+	for _, p := range players {
+		for _, a := range hints[p].Affinity {
+			if _, ok := parent[a]; ok {
+				parent[find(a)] = find(p)
+			}
+		}
+	}
+
+	index := make(map[string]int)
+	var groups [][]string
+	for _, p := range players {
+		root := find(p)
+		i, ok := index[root]
+		if !ok {
+			i = len(groups)
+			index[root] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], p)
+	}
+	return groups
+}
+
+// Conflicted returns the pairs of players in group that block one another,
+// each pair sorted, e.g. to report why a party couldn't be matched.
+func Conflicted(group []string, hints map[string]Hints) (pairs [][2]string) {
+	for i := range group {
+		for j := i + 1; j < len(group); j++ {
+			if Blocked(hints, group[i], group[j]) {
+				pair := []string{group[i], group[j]}
+				sort.Strings(pair)
+				pairs = append(pairs, [2]string{pair[0], pair[1]})
+			}
+		}
+	}
+	return pairs
+}
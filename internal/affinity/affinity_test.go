@@ -0,0 +1,64 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package affinity
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestFromProperties(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("jsonkeys.affinity", "party")
+	cfg.Set("jsonkeys.antiAffinity", "blocklist")
+
+	h := FromProperties(cfg, `{"mmr": 1200, "party": ["b", "c"], "blocklist": ["x"]}`)
+	if !reflect.DeepEqual(h.Affinity, []string{"b", "c"}) || !reflect.DeepEqual(h.AntiAffinity, []string{"x"}) {
+		t.Errorf("FromProperties = %+v", h)
+	}
+	if h := FromProperties(cfg, `{"mmr": 1200}`); h.Affinity != nil || h.AntiAffinity != nil {
+		t.Errorf("FromProperties without hints = %+v, want none", h)
+	}
+}
+
+func TestExcludeBlocked(t *testing.T) {
+	hints := map[string]Hints{
+		"a": {AntiAffinity: []string{"b"}},
+		"c": {AntiAffinity: []string{"a"}},
+	}
+	got := ExcludeBlocked([]string{"b", "c", "d"}, []string{"a"}, hints)
+	if !reflect.DeepEqual(got, []string{"d"}) {
+		t.Errorf("ExcludeBlocked = %v, want [d]", got)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	hints := map[string]Hints{
+		"a": {Affinity: []string{"c", "absent"}},
+		"d": {Affinity: []string{"c"}, AntiAffinity: []string{"a"}},
+	}
+	groups := Groups([]string{"a", "b", "c", "d", "e"}, hints)
+	want := [][]string{{"a", "c", "d"}, {"b"}, {"e"}}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("Groups = %v, want %v", groups, want)
+	}
+	if pairs := Conflicted(groups[0], hints); !reflect.DeepEqual(pairs, [][2]string{{"a", "d"}}) {
+		t.Errorf("Conflicted = %v, want [[a d]]", pairs)
+	}
+}
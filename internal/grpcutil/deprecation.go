@@ -0,0 +1,45 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcutil
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// DeprecationTrailer is the trailing metadata key that carries deprecation
+// notices.  Each value has the form '<field>: <notice>'.
+const DeprecationTrailer = "x-open-match-deprecation"
+
+// Deprecated tells the client that it populated a deprecated field, by
+// attaching a notice to the trailing metadata of the gRPC call in ctx.  The
+// call itself is unaffected, so clients can migrate at their own pace.
+func Deprecated(ctx context.Context, field string, notice string) {
+	guLog.WithFields(log.Fields{
+		"field":  field,
+		"notice": notice,
+	}).Debug("Client used deprecated field")
+
+	err := grpc.SetTrailer(ctx, metadata.Pairs(DeprecationTrailer, field+": "+notice))
+	if err != nil {
+		// Not called from a gRPC handler (e.g. in a test); nothing to do.
+		guLog.WithFields(log.Fields{"error": err.Error()}).Debug("Unable to set deprecation trailer")
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcutil
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/retrybudget"
+	"github.com/gomodule/redigo/redis"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrInvalidArgument is wrapped by handler errors caused by the request
+// rather than the server, e.g.
+//
+//	fmt.Errorf("%w: DescribePlayer requires a player id", grpcutil.ErrInvalidArgument)
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// errorCode maps the errors an errorCode matches to a gRPC status code.  The
+// name selects it in the 'errorCodes' config.
+type errorCode struct {
+	name  string
+	match func(error) bool
+	code  codes.Code
+}
+
+// is returns a matcher for errors that are, or wrap, target.
+func is(target error) func(error) bool {
+	return func(err error) bool { return errors.Is(err, target) }
+}
+
+var (
+	// errorCodes are checked in order, so more specific errors come first.
+	// Packages with their own sentinel errors add theirs, ahead of these,
+	// with RegisterErrorCode.
+	errorCodes = []errorCode{
+		{"invalidArgument", is(ErrInvalidArgument), codes.InvalidArgument},
+		{"notFound", is(redis.ErrNil), codes.NotFound},
+		{"poolExhausted", is(redis.ErrPoolExhausted), codes.ResourceExhausted},
+		{"retryBudgetExhausted", is(retrybudget.ErrExhausted), codes.Unavailable},
+		{"stateStorageUnavailable", func(err error) bool {
+			var netErr net.Error
+			return errors.As(err, &netErr)
+		}, codes.Unavailable},
+		{"deadlineExceeded", is(context.DeadlineExceeded), codes.DeadlineExceeded},
+		{"canceled", is(context.Canceled), codes.Canceled},
+	}
+	errorCodesMu sync.RWMutex
+)
+
+// defaultErrorCode names the code for errors no mapping matches.
+const defaultErrorCode = "default"
+
+// RegisterErrorCode maps errors that are, or wrap, err to code, so an API
+// service or state storage package can give its own sentinel errors a
+// status.  Registered errors are checked before those already mapped.  name
+// selects the mapping in the 'errorCodes' config.
+func RegisterErrorCode(name string, err error, code codes.Code) {
+	errorCodesMu.Lock()
+	defer errorCodesMu.Unlock()
+	errorCodes = append([]errorCode{{name, is(err), code}}, errorCodes...)
+}
+
+// ErrorCode returns the gRPC status code for a handler error: the code of
+// the first mapping that matches it, or codes.Internal.  Each mapping's code,
+// and the one for unmatched errors, can be overridden by setting
+// 'errorCodes.<name>' (e.g. 'errorCodes.playerNotFound' or
+// 'errorCodes.default') to the code's name, e.g. "FailedPrecondition".
+func ErrorCode(cfg *viper.Viper, err error) codes.Code {
+	errorCodesMu.RLock()
+	defer errorCodesMu.RUnlock()
+	for _, ec := range errorCodes {
+		if ec.match(err) {
+			return configuredCode(cfg, ec.name, ec.code)
+		}
+	}
+	return configuredCode(cfg, defaultErrorCode, codes.Internal)
+}
+
+// configuredCode returns the code set at 'errorCodes.<name>', or code.
+func configuredCode(cfg *viper.Viper, name string, code codes.Code) codes.Code {
+	configured := cfg.GetString("errorCodes." + name)
+	if configured == "" {
+		return code
+	}
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if strings.EqualFold(c.String(), configured) {
+			return c
+		}
+	}
+	guLog.WithFields(log.Fields{
+		"name": name,
+		"code": configured,
+	}).Warn("Ignoring unknown gRPC code in errorCodes config")
+	return code
+}
+
+// StatusError returns err as a gRPC status error with the code ErrorCode
+// maps it to, keeping its message.  Errors that already carry a status are
+// returned unchanged.
+func StatusError(cfg *viper.Viper, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(ErrorCode(cfg, err), err.Error())
+}
+
+// ErrorStatusUnaryInterceptor returns an interceptor that converts the error
+// of every unary call with StatusError.
+func ErrorStatusUnaryInterceptor(cfg *viper.Viper) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, StatusError(cfg, err)
+	}
+}
+
+// ErrorStatusStreamInterceptor returns an interceptor that converts the
+// error of every streaming call with StatusError.
+func ErrorStatusStreamInterceptor(cfg *viper.Viper) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return StatusError(cfg, handler(srv, ss))
+	}
+}
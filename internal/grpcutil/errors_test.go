@@ -0,0 +1,67 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcutil
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errTestAborted is registered like a package's own sentinel error.
+var errTestAborted = errors.New("test aborted")
+
+func init() {
+	RegisterErrorCode("testAborted", errTestAborted, codes.Aborted)
+}
+
+func TestErrorCode(t *testing.T) {
+	cfg := viper.New()
+	cases := []struct {
+		err  error
+		want codes.Code
+	}{
+		{fmt.Errorf("%w: missing id", ErrInvalidArgument), codes.InvalidArgument},
+		{fmt.Errorf("lookup: %w", redis.ErrNil), codes.NotFound},
+		{fmt.Errorf("retrieve: %w", errTestAborted), codes.Aborted},
+		{errors.New("something broke"), codes.Internal},
+	}
+	for _, c := range cases {
+		if got := ErrorCode(cfg, c.err); got != c.want {
+			t.Errorf("ErrorCode(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+
+	cfg.Set("errorCodes.testAborted", "FailedPrecondition")
+	cfg.Set("errorCodes.default", "unknown")
+	if got := ErrorCode(cfg, errTestAborted); got != codes.FailedPrecondition {
+		t.Errorf("overridden testAborted = %v, want FailedPrecondition", got)
+	}
+	if got := ErrorCode(cfg, errors.New("something broke")); got != codes.Unknown {
+		t.Errorf("overridden default = %v, want Unknown", got)
+	}
+
+	existing := status.Error(codes.Aborted, "aborted")
+	if got := StatusError(cfg, existing); got != existing {
+		t.Errorf("StatusError changed an existing status error to %v", got)
+	}
+}
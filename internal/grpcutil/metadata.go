@@ -0,0 +1,80 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcutil
+
+import (
+	"context"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequiredMetadata returns the metadata keys every call to the named API
+// service (e.g. 'frontend') must carry, from 'api.<service>.requiredMetadata'.
+func RequiredMetadata(cfg *viper.Viper, service string) []string {
+	keys := make([]string, 0)
+	for _, key := range cfg.GetStringSlice("api." + service + ".requiredMetadata") {
+		if key != "" {
+			// gRPC metadata keys are always lowercase.
+			keys = append(keys, strings.ToLower(key))
+		}
+	}
+	return keys
+}
+
+// RequiredMetadataUnaryInterceptor returns an interceptor that rejects unary
+// calls missing any of the metadata keys with codes.FailedPrecondition,
+// before the handler runs.
+func RequiredMetadataUnaryInterceptor(keys []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := checkMetadata(ctx, keys, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RequiredMetadataStreamInterceptor returns an interceptor that rejects
+// streaming calls missing any of the metadata keys with
+// codes.FailedPrecondition, before the handler runs.
+func RequiredMetadataStreamInterceptor(keys []string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkMetadata(ss.Context(), keys, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func checkMetadata(ctx context.Context, keys []string, method string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, key := range keys {
+		if len(md.Get(key)) == 0 {
+			guLog.WithFields(log.Fields{
+				"method": method,
+				"key":    key,
+			}).Warn("Rejecting request missing required metadata")
+			return status.Errorf(codes.FailedPrecondition, "request is missing required metadata '%s'", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequiredMetadata(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("api.frontend.requiredMetadata", []string{"X-Tenant-Id"})
+	interceptor := RequiredMetadataUnaryInterceptor(RequiredMetadata(cfg, "frontend"))
+
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/api.Frontend/CreateRequest"}
+
+	_, err := interceptor(context.Background(), nil, info, handler)
+	if status.Code(err) != codes.FailedPrecondition || called {
+		t.Errorf("call without metadata: err = %v, handler called = %v; want FailedPrecondition before the handler", err, called)
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant-id", "tenant1"))
+	if _, err = interceptor(ctx, nil, info, handler); err != nil || !called {
+		t.Errorf("call with metadata: err = %v, handler called = %v; want success", err, called)
+	}
+}
+
+func TestTenantTagger(t *testing.T) {
+	cfg := viper.New()
+	if newTenantTagger(cfg, "backend") != nil {
+		t.Fatal("tenant metrics enabled without a metadata key")
+	}
+
+	cfg.Set("api.backend.tenantMetadataKey", "X-Tenant-Id")
+	cfg.Set("api.backend.tenants", []string{"tenant1"})
+	tt := newTenantTagger(cfg, "backend")
+
+	tests := []struct {
+		md   metadata.MD
+		want string
+	}{
+		{nil, DefaultTenant},
+		{metadata.Pairs("x-tenant-id", "tenant1"), "tenant1"},
+		{metadata.Pairs("x-tenant-id", "tenant2"), DefaultTenant},
+	}
+	for _, test := range tests {
+		ctx := metadata.NewIncomingContext(context.Background(), test.md)
+		if got := tt.tenant(ctx); got != test.want {
+			t.Errorf("tenant(%v) = %v, want %v", test.md, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package grpcutil contains gRPC server options and interceptors shared by
+// the Open Match API servers.
+package grpcutil
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Logrus structured logging setup
+var (
+	guLogFields = log.Fields{
+		"app":       "openmatch",
+		"component": "grpcutil",
+		"caller":    "internal/grpcutil/msgsize.go",
+	}
+	guLog = log.WithFields(guLogFields)
+)
+
+// DefaultMaxRecvMsgSize is the largest message, in bytes, an API server will
+// accept when 'api.<service>.maxRecvMsgSize' is not set in the config.  This
+// matches the gRPC library default.
+const DefaultMaxRecvMsgSize = 4 * 1024 * 1024
+
+// MaxRecvMsgSize returns the configured maximum inbound message size for the
+// named API service (e.g. 'backend'), or DefaultMaxRecvMsgSize.
+func MaxRecvMsgSize(cfg *viper.Viper, service string) int {
+	key := "api." + service + ".maxRecvMsgSize"
+	if cfg.IsSet(key) && cfg.GetInt(key) > 0 {
+		return cfg.GetInt(key)
+	}
+	return DefaultMaxRecvMsgSize
+}
+
+// MaxMsgSizeUnaryInterceptor returns an interceptor that rejects unary
+// requests larger than limit bytes with codes.ResourceExhausted.
+func MaxMsgSizeUnaryInterceptor(limit int) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := checkMsgSize(req, limit, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// MaxMsgSizeStreamInterceptor returns an interceptor that rejects any message
+// received on a stream that is larger than limit bytes with
+// codes.ResourceExhausted.
+func MaxMsgSizeStreamInterceptor(limit int) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &sizeLimitedStream{ServerStream: ss, limit: limit, method: info.FullMethod})
+	}
+}
+
+// sizeLimitedStream wraps a grpc.ServerStream to check the size of each
+// message it receives.
+type sizeLimitedStream struct {
+	grpc.ServerStream
+	limit  int
+	method string
+}
+
+func (s *sizeLimitedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return checkMsgSize(m, s.limit, s.method)
+}
+
+func checkMsgSize(m interface{}, limit int, method string) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil
+	}
+	if size := proto.Size(msg); size > limit {
+		guLog.WithFields(log.Fields{
+			"method": method,
+			"size":   size,
+			"limit":  limit,
+		}).Warn("Rejecting oversized request")
+		return status.Errorf(codes.ResourceExhausted, "request of %d bytes exceeds the maximum of %d bytes", size, limit)
+	}
+	return nil
+}
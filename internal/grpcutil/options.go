@@ -0,0 +1,107 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcutil
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/retrybudget"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+)
+
+// ServerOptions returns the gRPC server options shared by the API servers,
+// configured for the named API service (e.g. 'backend'):
+//   - grpc.MaxRecvMsgSize rejects oversized payloads at the transport before
+//     they are deserialized; the size interceptors re-check every received
+//     message before it reaches a handler, so the limit still holds for
+//     messages read off a stream and is logged with the method that received
+//     it.
+//   - if 'api.<service>.tenantMetadataKey' is set, calls and their errors
+//     are counted per tenant.
+//   - calls missing any of the keys in 'api.<service>.requiredMetadata' are
+//     rejected before the handler runs.
+//   - if 'api.<service>.versionTrailer' is set, every response's trailer
+//     identifies the server's build; see VersionTrailerKey.
+//   - if 'retryBudget.retries' or 'retryBudget.seconds' is set, each call's
+//     state storage retries are limited in total; see package retrybudget.
+//   - handler errors are returned with the gRPC status code ErrorCode maps
+//     them to.
+//
+// A server accepts only one interceptor of each kind, so they are chained.
+func ServerOptions(cfg *viper.Viper, service string) []grpc.ServerOption {
+	limit := MaxRecvMsgSize(cfg, service)
+	unary := make([]grpc.UnaryServerInterceptor, 0)
+	stream := make([]grpc.StreamServerInterceptor, 0)
+	// The version trailer comes first, so it's on rejected calls too.
+	if VersionTrailerEnabled(cfg, service) {
+		unary = append(unary, VersionTrailerUnaryInterceptor())
+		stream = append(stream, VersionTrailerStreamInterceptor())
+	}
+	// Tenant metrics come first, so rejected calls are counted too.
+	if interceptor := TenantMetricsUnaryInterceptor(cfg, service); interceptor != nil {
+		unary = append(unary, interceptor)
+		stream = append(stream, TenantMetricsStreamInterceptor(cfg, service))
+	}
+	if keys := RequiredMetadata(cfg, service); len(keys) > 0 {
+		unary = append(unary, RequiredMetadataUnaryInterceptor(keys))
+		stream = append(stream, RequiredMetadataStreamInterceptor(keys))
+	}
+	if retrybudget.Enabled(cfg) {
+		unary = append(unary, RetryBudgetUnaryInterceptor(cfg))
+		stream = append(stream, RetryBudgetStreamInterceptor(cfg))
+	}
+	unary = append(unary, MaxMsgSizeUnaryInterceptor(limit))
+	stream = append(stream, MaxMsgSizeStreamInterceptor(limit))
+	// Error statuses are set last, so the interceptors above see them.
+	unary = append(unary, ErrorStatusUnaryInterceptor(cfg))
+	stream = append(stream, ErrorStatusStreamInterceptor(cfg))
+
+	return []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(limit),
+		grpc.UnaryInterceptor(chainUnary(unary)),
+		grpc.StreamInterceptor(chainStream(stream)),
+	}
+}
+
+// chainUnary combines interceptors into one, which runs them in order.
+func chainUnary(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, inner)
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+// chainStream combines interceptors into one, which runs them in order.
+func chainStream(interceptors []grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, inner)
+			}
+		}
+		return next(srv, ss)
+	}
+}
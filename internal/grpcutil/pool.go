@@ -0,0 +1,48 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcutil
+
+import (
+	"context"
+
+	"github.com/gomodule/redigo/redis"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetConn gets a redis connection for a gRPC handler.  With 'Wait: true'
+// an exhausted pool blocks, so ctx bounds how long the handler waits for a
+// connection.  Failures are returned as gRPC status errors: DeadlineExceeded
+// or Canceled if ctx ended first, ResourceExhausted if the pool is exhausted
+// and not waiting, and Unavailable if redis can't be reached.
+func GetConn(ctx context.Context, pool *redis.Pool) (redis.Conn, error) {
+	redisConn, err := pool.GetContext(ctx)
+	if err == nil {
+		return redisConn, nil
+	}
+
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return nil, status.Error(codes.DeadlineExceeded, "timed out waiting for a state storage connection")
+	case ctx.Err() == context.Canceled:
+		return nil, status.Error(codes.Canceled, "cancelled while waiting for a state storage connection")
+	case err == redis.ErrPoolExhausted:
+		return nil, status.Error(codes.ResourceExhausted, "no state storage connections available, try again later")
+	default:
+		return nil, status.Errorf(codes.Unavailable, "state storage connection failed: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/gomodule/redigo/redis"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetConnExhaustedPool(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+
+	for _, wait := range []bool{true, false} {
+		pool := &redis.Pool{
+			MaxActive: 1,
+			Wait:      wait,
+			Dial:      func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) },
+		}
+		held, err := GetConn(context.Background(), pool)
+		if err != nil {
+			t.Fatalf("GetConn failed: %v", err)
+		}
+
+		want := codes.ResourceExhausted
+		if wait {
+			want = codes.DeadlineExceeded
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		_, err = GetConn(ctx, pool)
+		cancel()
+		if status.Code(err) != want {
+			t.Errorf("Wait=%v: GetConn on an exhausted pool returned %v, want %v", wait, err, want)
+		}
+
+		held.Close()
+		pool.Close()
+	}
+}
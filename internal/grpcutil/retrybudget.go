@@ -0,0 +1,54 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcutil
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/retrybudget"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+)
+
+// RetryBudgetUnaryInterceptor returns an interceptor that gives each call the
+// retry budget configured in the 'retryBudget' config, shared by every layer
+// the handler retries state storage operations in.
+func RetryBudgetUnaryInterceptor(cfg *viper.Viper) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(retrybudget.FromConfig(ctx, cfg), req)
+	}
+}
+
+// RetryBudgetStreamInterceptor returns an interceptor that gives each stream
+// the retry budget configured in the 'retryBudget' config.  The budget is
+// shared by everything done for the stream, however long it runs.
+func RetryBudgetStreamInterceptor(cfg *viper.Viper) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &budgetedStream{ServerStream: ss, ctx: retrybudget.FromConfig(ss.Context(), cfg)})
+	}
+}
+
+// budgetedStream wraps a grpc.ServerStream to carry a retry budget in its
+// context.
+type budgetedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *budgetedStream) Context() context.Context {
+	return s.ctx
+}
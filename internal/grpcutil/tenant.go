@@ -0,0 +1,150 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcutil
+
+import (
+	"context"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	"github.com/spf13/viper"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// DefaultTenant is the tenant recorded for calls that carry no tenant ID, or
+// one that isn't in the configured list of tenants.
+const DefaultTenant = "default"
+
+var (
+	// TenantRequests counts gRPC calls, tagged by tenant and method.
+	TenantRequests = stats.Int64("grpc/tenant/requests_total", "Number of gRPC calls per tenant", "1")
+	// TenantErrors counts gRPC calls that returned an error, tagged by
+	// tenant and method.
+	TenantErrors = stats.Int64("grpc/tenant/errors_total", "Number of failed gRPC calls per tenant", "1")
+
+	// KeyTenant is used to tag a measure with the tenant that made the call.
+	KeyTenant, _ = tag.NewKey("tenant")
+	// KeyGrpcMethod is used to tag a measure with the full gRPC method name.
+	KeyGrpcMethod, _ = tag.NewKey("grpc_method")
+
+	// TenantRequestCountView is the OpenCensus view for TenantRequests.
+	TenantRequestCountView = &view.View{
+		Name:        "grpc/tenant/requests",
+		Measure:     TenantRequests,
+		Description: "The number of gRPC calls per tenant",
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{KeyTenant, KeyGrpcMethod},
+	}
+	// TenantErrorCountView is the OpenCensus view for TenantErrors.
+	TenantErrorCountView = &view.View{
+		Name:        "grpc/tenant/errors",
+		Measure:     TenantErrors,
+		Description: "The number of failed gRPC calls per tenant",
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{KeyTenant, KeyGrpcMethod},
+	}
+)
+
+func init() {
+	metrics.AddViews(TenantRequestCountView, TenantErrorCountView)
+}
+
+// tenantTagger maps the tenant ID in a call's metadata to the value it is
+// tagged with.
+type tenantTagger struct {
+	key   string
+	known map[string]bool
+}
+
+// newTenantTagger reads the named API service's tenant settings:
+// 'api.<service>.tenantMetadataKey' is the metadata key holding the tenant
+// ID, and if 'api.<service>.tenants' is set, IDs not in that list are
+// recorded as DefaultTenant so clients can't create unbounded tag values.
+// It returns nil if no metadata key is configured.
+func newTenantTagger(cfg *viper.Viper, service string) *tenantTagger {
+	key := cfg.GetString("api." + service + ".tenantMetadataKey")
+	if key == "" {
+		return nil
+	}
+	tt := &tenantTagger{key: strings.ToLower(key)}
+	if tenants := cfg.GetStringSlice("api." + service + ".tenants"); len(tenants) > 0 {
+		tt.known = make(map[string]bool)
+		for _, tenant := range tenants {
+			tt.known[tenant] = true
+		}
+	}
+	return tt
+}
+
+// tenant returns the tag value for the call in ctx.
+func (tt *tenantTagger) tenant(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ids := md.Get(tt.key)
+	if len(ids) == 0 || ids[0] == "" {
+		return DefaultTenant
+	}
+	if tt.known != nil && !tt.known[ids[0]] {
+		return DefaultTenant
+	}
+	return ids[0]
+}
+
+// record counts a call, and its failure if err is set.
+func (tt *tenantTagger) record(ctx context.Context, method string, err error) {
+	tagCtx, tagErr := tag.New(ctx, tag.Upsert(KeyTenant, tt.tenant(ctx)), tag.Upsert(KeyGrpcMethod, method))
+	if tagErr != nil {
+		return
+	}
+	metrics.Record(tagCtx, TenantRequests.M(1))
+	if err != nil {
+		metrics.Record(tagCtx, TenantErrors.M(1))
+	}
+}
+
+// TenantMetricsUnaryInterceptor returns an interceptor that counts unary
+// calls and their errors per tenant, using the named API service's tenant
+// settings, or nil if tenant metrics aren't configured.
+func TenantMetricsUnaryInterceptor(cfg *viper.Viper, service string) grpc.UnaryServerInterceptor {
+	tt := newTenantTagger(cfg, service)
+	if tt == nil {
+		return nil
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		tt.record(ctx, info.FullMethod, err)
+		return resp, err
+	}
+}
+
+// TenantMetricsStreamInterceptor returns an interceptor that counts
+// streaming calls and their errors per tenant, using the named API service's
+// tenant settings, or nil if tenant metrics aren't configured.
+func TenantMetricsStreamInterceptor(cfg *viper.Viper, service string) grpc.StreamServerInterceptor {
+	tt := newTenantTagger(cfg, service)
+	if tt == nil {
+		return nil
+	}
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		tt.record(ss.Context(), info.FullMethod, err)
+		return err
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcutil
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Version and GitSHA identify the build of the running server.  They are set
+// at build time, e.g.
+//
+//	go build -ldflags "-X github.com/GoogleCloudPlatform/open-match/internal/grpcutil.Version=0.2.0 -X github.com/GoogleCloudPlatform/open-match/internal/grpcutil.GitSHA=$(git rev-parse HEAD)"
+var (
+	Version = "dev"
+	GitSHA  = "unknown"
+)
+
+// Trailer metadata keys added to every response when
+// 'api.<service>.versionTrailer' is set.
+const (
+	VersionTrailerKey  = "x-openmatch-version"
+	GitSHATrailerKey   = "x-openmatch-git-sha"
+	HostnameTrailerKey = "x-openmatch-hostname"
+)
+
+// VersionTrailerEnabled returns true if the named API service (e.g.
+// 'backend') should report its build in response trailers, from
+// 'api.<service>.versionTrailer'.
+func VersionTrailerEnabled(cfg *viper.Viper, service string) bool {
+	return cfg.GetBool("api." + service + ".versionTrailer")
+}
+
+// versionTrailer returns the trailer metadata identifying this server.  The
+// hostname is the pod name under Kubernetes, so mixed-version rollouts can be
+// traced to the instance that served each call.
+func versionTrailer() metadata.MD {
+	hostname, _ := os.Hostname()
+	return metadata.Pairs(
+		VersionTrailerKey, Version,
+		GitSHATrailerKey, GitSHA,
+		HostnameTrailerKey, hostname,
+	)
+}
+
+// VersionTrailerUnaryInterceptor returns an interceptor that adds the
+// server's version, git SHA and hostname to the trailer of every unary
+// response, including failed calls.
+func VersionTrailerUnaryInterceptor() grpc.UnaryServerInterceptor {
+	md := versionTrailer()
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		grpc.SetTrailer(ctx, md)
+		return handler(ctx, req)
+	}
+}
+
+// VersionTrailerStreamInterceptor returns an interceptor that adds the
+// server's version, git SHA and hostname to the trailer of every stream.
+func VersionTrailerStreamInterceptor() grpc.StreamServerInterceptor {
+	md := versionTrailer()
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ss.SetTrailer(md)
+		return handler(srv, ss)
+	}
+}
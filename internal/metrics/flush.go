@@ -0,0 +1,61 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"go.opencensus.io/stats/view"
+)
+
+// flusher is implemented by exporters that batch metrics before sending
+// them, such as the Stackdriver exporter.
+type flusher interface {
+	Flush()
+}
+
+var (
+	flushersMu sync.Mutex
+	flushers   []flusher
+)
+
+// RegisterExporter registers an OpenCensus exporter for view data, and
+// remembers it so Flush can send any metrics it still has buffered.
+func RegisterExporter(e view.Exporter) {
+	view.RegisterExporter(e)
+	if f, ok := e.(flusher); ok {
+		flushersMu.Lock()
+		flushers = append(flushers, f)
+		flushersMu.Unlock()
+	}
+}
+
+// Flush sends the metrics buffered by every registered exporter that
+// supports it.  Call it on shutdown, so a process that exits before the
+// exporter's next batch doesn't drop the last of its metrics.  Exporters
+// that are scraped, like Prometheus, have nothing to flush.
+func Flush() {
+	flushersMu.Lock()
+	defer flushersMu.Unlock()
+
+	for _, f := range flushers {
+		f.Flush()
+	}
+	mhLog.WithFields(log.Fields{"exporters": len(flushers)}).Info("Flushed buffered metrics")
+}
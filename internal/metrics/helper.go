@@ -44,10 +44,9 @@ var (
 
 // ConfigureOpenCensusPrometheusExporter reads from the provided viper
 // config's 'metrics' section to set up a metrics endpoint that can be scraped
-// by Promethus for  metrics gathering. The calling code can select any views
-// it wants to  register, from any number of libraries, and pass them in as an
-// array.
-func ConfigureOpenCensusPrometheusExporter(cfg *viper.Viper, views []*view.View) {
+// by Promethus for  metrics gathering. Views must be registered separately,
+// with RegisterAllViews.
+func ConfigureOpenCensusPrometheusExporter(cfg *viper.Viper) {
 
 	//var infoCtx, err = tag.New(context.Background(), tag.Insert(KeySeverity, "info"))
 	metricsPort := cfg.GetInt("metrics.port")
@@ -61,14 +60,7 @@ func ConfigureOpenCensusPrometheusExporter(cfg *viper.Viper, views []*view.View)
 			"Failed to initialize OpenCensus exporter to Prometheus")
 	}
 	mhLog.Info("OpenCensus exporter to Promethus initialized")
-	view.RegisterExporter(pe)
-
-	// Register the OpenCensus views we want to export to Prometheus
-	err = view.Register(views...)
-	if err != nil {
-		mhLog.Fatalf("Failed to register OpenCensus views for metrics gathering: %v", err)
-	}
-	mhLog.Info("Opencensus views registered")
+	RegisterExporter(pe)
 
 	// Change the frequency of updates to the metrics endpoint
 	view.SetReportingPeriod(time.Duration(metricsRP) * time.Second)
@@ -122,7 +114,7 @@ func (h Hook) Fire(e *log.Entry) error {
 	if err != nil {
 		fmt.Println(err)
 	}
-	stats.Record(ctx, h.count.M(1))
+	Record(ctx, h.count.M(1))
 	return err
 }
 
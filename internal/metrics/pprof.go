@@ -0,0 +1,64 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// defaultPprofPort is the debug port used when 'debug.pprof.port' is not set.
+const defaultPprofPort = 6060
+
+// ConfigurePprof serves the net/http/pprof runtime profiles under
+// /debug/pprof/ on a separate port, 'debug.pprof.port', if
+// 'debug.pprof.enabled' is set.  Profiling is off by default; the port should
+// not be exposed outside the cluster.
+func ConfigurePprof(cfg *viper.Viper) {
+	if !cfg.GetBool("debug.pprof.enabled") {
+		return
+	}
+	port := defaultPprofPort
+	if cfg.IsSet("debug.pprof.port") && cfg.GetInt("debug.pprof.port") > 0 {
+		port = cfg.GetInt("debug.pprof.port")
+	}
+
+	// Use a dedicated mux rather than http.DefaultServeMux, so the profiles
+	// are only reachable on the debug port.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	go func() {
+		mhLog.WithFields(log.Fields{"port": port}).Info("Attempting to start http server for pprof profiles")
+		err := http.ListenAndServe(":"+strconv.Itoa(port), mux)
+		if err != nil {
+			// Profiling is a debugging aid, so the service keeps running.
+			mhLog.WithFields(log.Fields{
+				"error": err,
+				"port":  port,
+			}).Error("Failed to run pprof endpoint")
+		}
+	}()
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+	"go.opencensus.io/stats"
+)
+
+// Recorder records measurements for Record.  It is stats.Record, and is only
+// replaced by tests simulating a broken stats backend.
+var Recorder = stats.Record
+
+// recordFailed is set once a failure to record has been logged.
+var recordFailed int32
+
+// Record is stats.Record, but a panic in the stats backend is recovered, so
+// a misconfigured backend never fails the request being measured.  Only the
+// first failure is logged, as every request would otherwise log one.  All
+// metrics in Open Match are recorded through Record.
+func Record(ctx context.Context, ms ...stats.Measurement) {
+	defer func() {
+		if r := recover(); r != nil {
+			// Not a sync.Once: the log hook records the line it logs, which
+			// would re-enter it if recording is still failing.
+			if atomic.CompareAndSwapInt32(&recordFailed, 0, 1) {
+				mhLog.WithFields(log.Fields{
+					"error": fmt.Sprint(r),
+				}).Error("Failed to record metrics, further failures will not be logged")
+			}
+		}
+	}()
+	Recorder(ctx, ms...)
+}
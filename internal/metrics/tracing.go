@@ -0,0 +1,47 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"go.opencensus.io/trace"
+)
+
+// ConfigureOpenCensusTraceSampling reads 'tracing.sampleRate' from the
+// provided viper config and applies it as the global OpenCensus trace
+// sampler. The rate is the fraction of requests to trace, between 0 and 1.
+// If it isn't set, the OpenCensus default sampler is left in place.
+func ConfigureOpenCensusTraceSampling(cfg *viper.Viper) {
+	if !cfg.IsSet("tracing.sampleRate") {
+		mhLog.Info("No trace sample rate configured, using OpenCensus default")
+		return
+	}
+
+	rate := cfg.GetFloat64("tracing.sampleRate")
+	if rate < 0 || rate > 1 {
+		mhLog.WithFields(log.Fields{
+			"sampleRate": rate,
+		}).Warn("Trace sample rate should be between 0 and 1, clamping")
+	}
+
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(rate)})
+	mhLog.WithFields(log.Fields{
+		"sampleRate": rate,
+	}).Info("OpenCensus trace sampling configured")
+}
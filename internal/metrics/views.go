@@ -0,0 +1,64 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"go.opencensus.io/stats/view"
+)
+
+var (
+	viewsMu sync.Mutex
+	views   []*view.View
+)
+
+// AddViews adds views to the set registered by RegisterAllViews.  Packages
+// that define OpenCensus measures add their views from an init function, so
+// a binary exports every measure of every package it links without listing
+// the views itself.
+func AddViews(v ...*view.View) {
+	viewsMu.Lock()
+	defer viewsMu.Unlock()
+	views = append(views, v...)
+}
+
+// RegisterAllViews registers every view added with AddViews, so the data for
+// them is collected and exported.  Call it once at startup, after adding any
+// views from libraries such as ocgrpc.
+func RegisterAllViews() error {
+	viewsMu.Lock()
+	defer viewsMu.Unlock()
+
+	// The same view may have been added by more than one package.
+	unique := make([]*view.View, 0, len(views))
+	seen := make(map[*view.View]bool)
+	for _, v := range views {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+
+	if err := view.Register(unique...); err != nil {
+		return err
+	}
+	mhLog.WithFields(log.Fields{"viewscount": len(unique)}).Info("Opencensus views registered")
+	return nil
+}
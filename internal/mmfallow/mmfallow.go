@@ -0,0 +1,88 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mmfallow restricts the MMFs Open Match will invoke to those at
+// operator-approved addresses, so a client-supplied profile can't make the
+// mmforc run an arbitrary container image or call an arbitrary URL.
+package mmfallow
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/tidwall/gjson"
+)
+
+// MMF invocation protocols, as set per MMF in 'mmfs.<name>.protocol'.
+const (
+	ProtocolJob  = "job"
+	ProtocolHTTP = "http"
+)
+
+// Target returns how the mmforc will invoke the MMF for a profile, and the
+// URL or container image it will invoke: the registered MMF named mmf if
+// set, otherwise the image named in the profile's properties at
+// 'jsonkeys.mmfImage', otherwise the default MMF image.
+func Target(cfg *viper.Viper, mmf string, properties string) (protocol string, target string) {
+	if mmf != "" {
+		key := "mmfs." + mmf
+		if cfg.GetString(key+".protocol") == ProtocolHTTP {
+			return ProtocolHTTP, cfg.GetString(key + ".url")
+		}
+		return ProtocolJob, cfg.GetString(key+".name") + ":" + cfg.GetString(key+".tag")
+	}
+	if gjson.Valid(properties) {
+		if image := gjson.Get(properties, cfg.GetString("jsonkeys.mmfImage")); image.Exists() {
+			return ProtocolJob, image.String()
+		}
+	}
+	return ProtocolJob, cfg.GetString("defaultImages.mmf.name") + ":" + cfg.GetString("defaultImages.mmf.tag")
+}
+
+// Check returns an error if the MMF target isn't allowed by
+// 'mmfAllowlist'.  An HTTP MMF is allowed if an entry is its URL's hostname
+// or host:port; a job MMF if an entry is its image, with or without the tag,
+// or a prefix of it ending at a '/', such as its registry ('gcr.io') or
+// repository ('gcr.io/my-project').  An empty allowlist allows every MMF.
+func Check(cfg *viper.Viper, protocol string, target string) error {
+	allowlist := cfg.GetStringSlice("mmfAllowlist")
+	if len(allowlist) == 0 {
+		return nil
+	}
+
+	if protocol == ProtocolHTTP {
+		u, err := url.Parse(target)
+		if err != nil {
+			return fmt.Errorf("mmf url '%v' is invalid: %v", target, err)
+		}
+		for _, entry := range allowlist {
+			if entry != "" && (entry == u.Hostname() || entry == u.Host) {
+				return nil
+			}
+		}
+		return fmt.Errorf("mmf url '%v' is not in the mmfAllowlist", target)
+	}
+
+	for _, entry := range allowlist {
+		entry = strings.TrimSuffix(entry, "/")
+		if entry != "" && (target == entry || strings.HasPrefix(target, entry+"/") || strings.HasPrefix(target, entry+":")) {
+			return nil
+		}
+	}
+	return fmt.Errorf("mmf image '%v' is not in the mmfAllowlist", target)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mmfallow
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestCheck(t *testing.T) {
+	cfg := viper.New()
+	if err := Check(cfg, ProtocolHTTP, "http://169.254.169.254/"); err != nil {
+		t.Errorf("empty allowlist rejected an MMF: %v", err)
+	}
+
+	cfg.Set("mmfAllowlist", []string{"om-mmf-http:8080", "mmf.internal", "gcr.io/my-project"})
+	tests := []struct {
+		protocol string
+		target   string
+		allowed  bool
+	}{
+		{ProtocolHTTP, "http://om-mmf-http:8080/", true},
+		{ProtocolHTTP, "http://om-mmf-http:9090/", false},
+		{ProtocolHTTP, "https://mmf.internal/match", true},
+		{ProtocolHTTP, "http://169.254.169.254/", false},
+		{ProtocolJob, "gcr.io/my-project/mmf:py3", true},
+		{ProtocolJob, "gcr.io/my-project-evil/mmf:py3", false},
+		{ProtocolJob, "docker.io/attacker/mmf:latest", false},
+	}
+	for _, tt := range tests {
+		err := Check(cfg, tt.protocol, tt.target)
+		if allowed := err == nil; allowed != tt.allowed {
+			t.Errorf("Check(%v, %v) = %v, want allowed %v", tt.protocol, tt.target, err, tt.allowed)
+		}
+	}
+}
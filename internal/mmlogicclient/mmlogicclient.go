@@ -0,0 +1,142 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mmlogicclient wraps the MMLogic API's filter and pool calls in plain
+// Go methods for matchmaking functions, so an MMF doesn't have to drain
+// GetPlayerPool's pages, set its own timeouts or pick apart gRPC statuses.
+package mmlogicclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/grpcutil"
+	"github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultTimeout bounds calls made with a context that has no deadline.
+const DefaultTimeout = 10 * time.Second
+
+// ErrUnavailable is returned when the MMLogic API, or the state storage
+// behind it, can't serve the call right now.  It's worth retrying later.
+var ErrUnavailable = errors.New("mmlogic unavailable")
+
+// Client calls the MMLogic API.
+type Client struct {
+	mml pb.MmLogicClient
+
+	// Timeout bounds each call whose context has no deadline of its own.
+	// Zero means no bound.
+	Timeout time.Duration
+}
+
+// New returns a Client for the MMLogic API served on conn, using
+// DefaultTimeout.
+func New(conn *grpc.ClientConn) *Client {
+	return &Client{mml: pb.NewMmLogicClient(conn), Timeout: DefaultTimeout}
+}
+
+// GetPool returns the players that match every filter, excluding those in
+// any ignorelist, along with the pool's stats.  Per-filter counts are in the
+// stats' FilterCounts.  name identifies the pool in the MMLogic API's logs.
+func (c *Client) GetPool(ctx context.Context, name string, filters ...*pb.Filter) ([]*pb.Player, *pb.Stats, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	stream, err := c.mml.GetPlayerPool(ctx, &pb.PlayerPool{Name: name, Filters: filters})
+	if err != nil {
+		return nil, nil, translate(err)
+	}
+
+	// The pool arrives a page at a time, each page carrying the pool's stats.
+	var players []*pb.Player
+	var stats *pb.Stats
+	for {
+		page, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, translate(err)
+		}
+		if page.Roster != nil {
+			players = append(players, page.Roster.Players...)
+		}
+		if page.Stats != nil {
+			stats = page.Stats
+		}
+	}
+	return players, stats, nil
+}
+
+// Deindex keeps the players with the given ids out of every MMF's pools until
+// the proposed ignorelist's duration passes, without proposing a match.
+func (c *Client) Deindex(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	roster := &pb.Roster{Players: make([]*pb.Player, len(ids))}
+	for i, id := range ids {
+		roster.Players[i] = &pb.Player{Id: id}
+	}
+	result, err := c.mml.DeindexPlayers(ctx, roster)
+	if err != nil {
+		return translate(err)
+	}
+	if !result.Success {
+		return fmt.Errorf("deindexing players: %s", result.Error)
+	}
+	return nil
+}
+
+// withTimeout bounds ctx by c.Timeout, unless it already has a deadline.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || c.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.Timeout)
+}
+
+// translate turns a gRPC status error into an error the caller can test for
+// with errors.Is: context.DeadlineExceeded, context.Canceled,
+// grpcutil.ErrInvalidArgument or ErrUnavailable.  Other errors are returned
+// as they are.
+func translate(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch st.Code() {
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("%w: %s", context.DeadlineExceeded, st.Message())
+	case codes.Canceled:
+		return fmt.Errorf("%w: %s", context.Canceled, st.Message())
+	case codes.InvalidArgument:
+		return fmt.Errorf("%w: %s", grpcutil.ErrInvalidArgument, st.Message())
+	case codes.Unavailable, codes.ResourceExhausted:
+		return fmt.Errorf("%w: %s", ErrUnavailable, st.Message())
+	}
+	return err
+}
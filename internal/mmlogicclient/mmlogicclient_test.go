@@ -0,0 +1,108 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mmlogicclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/grpcutil"
+	"github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeMmLogic serves GetPlayerPool from pages and DeindexPlayers with err.
+type fakeMmLogic struct {
+	pb.MmLogicClient
+	pages     []*pb.PlayerPool
+	err       error
+	deindexed []string
+}
+
+type fakePoolStream struct {
+	grpc.ClientStream
+	pages []*pb.PlayerPool
+}
+
+func (s *fakePoolStream) Recv() (*pb.PlayerPool, error) {
+	if len(s.pages) == 0 {
+		return nil, io.EOF
+	}
+	page := s.pages[0]
+	s.pages = s.pages[1:]
+	return page, nil
+}
+
+func (f *fakeMmLogic) GetPlayerPool(ctx context.Context, in *pb.PlayerPool, opts ...grpc.CallOption) (pb.MmLogic_GetPlayerPoolClient, error) {
+	return &fakePoolStream{pages: f.pages}, nil
+}
+
+func (f *fakeMmLogic) DeindexPlayers(ctx context.Context, in *pb.Roster, opts ...grpc.CallOption) (*pb.Result, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		return nil, errors.New("call has no deadline")
+	}
+	if f.err != nil {
+		return &pb.Result{Success: false, Error: f.err.Error()}, f.err
+	}
+	for _, p := range in.Players {
+		f.deindexed = append(f.deindexed, p.Id)
+	}
+	return &pb.Result{Success: true}, nil
+}
+
+func TestGetPool(t *testing.T) {
+	stats := &pb.Stats{Count: 3}
+	c := &Client{mml: &fakeMmLogic{pages: []*pb.PlayerPool{
+		{Stats: stats, Roster: &pb.Roster{Players: []*pb.Player{{Id: "a"}, {Id: "b"}}}},
+		{Stats: stats, Roster: &pb.Roster{Players: []*pb.Player{{Id: "c"}}}},
+	}}}
+
+	players, got, err := c.GetPool(context.Background(), "pool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(players) != 3 || players[2].Id != "c" {
+		t.Errorf("GetPool players = %v, want a, b and c", players)
+	}
+	if got != stats {
+		t.Errorf("GetPool stats = %v, want %v", got, stats)
+	}
+}
+
+func TestDeindex(t *testing.T) {
+	fake := &fakeMmLogic{}
+	c := &Client{mml: fake, Timeout: DefaultTimeout}
+	if err := c.Deindex(context.Background(), []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.deindexed) != 2 {
+		t.Errorf("deindexed %v, want a and b", fake.deindexed)
+	}
+
+	fake.err = status.Error(codes.Unavailable, "redis down")
+	if err := c.Deindex(context.Background(), []string{"a"}); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Deindex = %v, want %v", err, ErrUnavailable)
+	}
+	fake.err = status.Error(codes.InvalidArgument, "no players")
+	if err := c.Deindex(context.Background(), []string{"a"}); !errors.Is(err, grpcutil.ErrInvalidArgument) {
+		t.Errorf("Deindex = %v, want %v", err, grpcutil.ErrInvalidArgument)
+	}
+}
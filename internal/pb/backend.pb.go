@@ -13,6 +13,9 @@ It is generated from these files:
 It has these top-level messages:
 	Group
 	PlayerId
+	AssignmentWebhook
+	PlayerIds
+	PlayerStatus
 	MatchObject
 	Roster
 	Filter
@@ -23,6 +26,19 @@ It has these top-level messages:
 	IlInput
 	ConnectionInfo
 	Assignments
+	ListAssignmentsRequest
+	PlayerAssignment
+	AssignmentList
+	HealthRequest
+	HealthCheck
+	HealthStatus
+	ProfileList
+	PlayerRecord
+	MatchIdList
+	ResultList
+	ActivePlayerCountRequest
+	ActivePlayerCount
+	MatchProgress
 */
 package pb
 
@@ -63,15 +79,35 @@ type BackendClient interface {
 	//  - properties
 	//  - [optional] roster, any fields you fill are available to your MMF.
 	//  - [optional] pools, any fields you fill are available to your MMF.
+	//  - [optional] mmf, the name of an MMF in the 'mmfs' config section to run.
+	//  - [optional] requested_players, the number of players the match should
+	//    contain. If 'matchValidation.strictPlayerCount' is set, matches of any
+	//    other size are returned as errors.
+	//  - [optional] properties.maxPlayers, the most players the match may
+	//    contain, overriding 'matchValidation.maxPlayers'. Larger matches are
+	//    returned as errors, or truncated if 'matchValidation.maxPlayersMode'
+	//    is 'truncate'.
+	//  - [optional] dry_run, to see the match the MMF would make without
+	//    proposing or deindexing its players, or keeping the match in state
+	//    storage.  Not supported by ListMatches.
 	// OUTPUT: MatchObject message with these fields populated:
 	//  - id
 	//  - properties
 	//  - error. Empty if no error was encountered
+	//  - no_match_reason. If the MMF found no match, why, when the MMF says.
 	//  - rosters, if you choose to fill them in your MMF. (Recommended)
 	//  - pools, if you used the MMLogicAPI in your MMF. (Recommended, and provides stats)
+	//    If no match was found, the profile's pools are returned if the MMF
+	//    returned none, and every pool without stats gets the number of
+	//    players each of its filters matches.
 	CreateMatch(ctx context.Context, in *MatchObject, opts ...grpc.CallOption) (*MatchObject, error)
 	// Continually run MMF and stream matchobjects that fit this profile until
 	// client closes the connection.  Same inputs/outputs as CreateMatch.
+	// A match attempt that finds nothing (the MMF set the 'error' field, or
+	// the match failed validation) is streamed with 'error' populated, and the
+	// stream continues.  If the stream itself fails, it ends with a gRPC status:
+	// DEADLINE_EXCEEDED if no MMF results appeared in time, UNAVAILABLE if state
+	// storage failed.
 	ListMatches(ctx context.Context, in *MatchObject, opts ...grpc.CallOption) (Backend_ListMatchesClient, error)
 	// Delete a matchobject from state storage manually. (Matchobjects in state
 	// storage will also automatically expire after a while)
@@ -85,12 +121,30 @@ type BackendClient interface {
 	// the player from future matchmaking functions by adding them to the
 	// 'deindexed' player list and then deleting their player ID from state storage
 	// indexes.
+	// If 'assignments.deindexAt' is set to 'match', players are instead
+	// deindexed when their match is returned by CreateMatch or ListMatches.
 	// INPUT: Assignments message with these fields populated:
 	//  - connection_info, anything you write to this string is sent to Frontend API
 	//  - rosters. You can send any number of rosters, containing any number of
 	//     player messages. All players from all rosters will be sent the connection_info.
 	//     The only field in the Player object that is used by CreateAssignments is
 	//     the id field.  All others are silently ignored.
+	//     Players whose stored properties match one of the
+	//     'assignments.routing.rules' are sent that rule's connection string
+	//     instead of connection_info.
+	//  - [optional] nonce and timestamp.  Required when
+	//     'assignments.replayProtection' is enabled, in which case calls with a
+	//     timestamp older than 'assignments.maxAge' seconds or a nonce that was
+	//     already used are rejected.
+	//  - [optional] only_unassigned.  When set, each player is only assigned if
+	//     they have no assignment yet, checked and written atomically so two
+	//     backends can't both assign the same player.  Players that were
+	//     already assigned are left unchanged and listed in the result's
+	//     'conflicts' field, and the result's success is false if there are any.
+	//  - [optional] skip_deindex.  When set, the connection info is written,
+	//     but the players stay indexed and in matchmaking, e.g. to attach a
+	//     voice server to players still in the queue.  Their assignments aren't
+	//     tracked for expiry either.
 	CreateAssignments(ctx context.Context, in *Assignments, opts ...grpc.CallOption) (*Result, error)
 	// Remove DGS connection info from state storage for players.
 	// INPUT: Roster message with the 'players' field populated.
@@ -98,6 +152,69 @@ type BackendClient interface {
 	//    DeleteAssignments is the 'id' field.  All others are silently ignored.  If
 	//    you need to delete multiple rosters, make multiple calls.
 	DeleteAssignments(ctx context.Context, in *Roster, opts ...grpc.CallOption) (*Result, error)
+	// Cancel any in-flight CreateMatch or ListMatches calls on this Backend API
+	// instance that are working on the provided profile.  Cancelled CreateMatch
+	// calls remove their request from the profile queue if an MMF hasn't picked
+	// it up yet.
+	// INPUT: MatchObject message with the 'id' field populated with the profile id.
+	// (All other fields are ignored.)
+	CancelMatch(ctx context.Context, in *MatchObject, opts ...grpc.CallOption) (*Result, error)
+	// Admin call to list current player assignments, a page at a time, for
+	// dashboards.  Assignments are returned in no particular order.
+	// INPUT: ListAssignmentsRequest message with these fields populated:
+	//  - [optional] limit, the maximum number of assignments to return.
+	//  - [optional] cursor, the next_cursor returned by the previous call.
+	// OUTPUT: AssignmentList message with these fields populated:
+	//  - assignments, player IDs and their connection info.
+	//  - next_cursor, empty once every assignment has been listed.
+	ListAssignments(ctx context.Context, in *ListAssignmentsRequest, opts ...grpc.CallOption) (*AssignmentList, error)
+	// Admin call reporting the health of this Backend API instance and its
+	// dependencies in one response, for dashboards and monitoring.
+	// INPUT: HealthRequest message, which has no fields.
+	// OUTPUT: HealthStatus message with a check for state storage and for each
+	//    HTTP MMF in the config, state storage pool utilization, and whether
+	//    the instance is draining.
+	GetHealth(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthStatus, error)
+	// Like ListMatches, but runs several profiles at once and multiplexes their
+	// matches over a single stream, so a backend serving many game modes needs
+	// only one connection.  Each match's profile_id holds the ID of the profile
+	// it was made for.  Cancelling a profile with CancelMatch stops only that
+	// profile; the stream ends once every profile has stopped.
+	ListMatchesMulti(ctx context.Context, in *ProfileList, opts ...grpc.CallOption) (Backend_ListMatchesMultiClient, error)
+	// Admin call returning everything state storage holds about one player, for
+	// support and debugging without searching redis by hand.
+	// INPUT: PlayerId message with the 'id' field populated.
+	// OUTPUT: PlayerRecord message with these fields populated:
+	//  - fields, every field of the player's record, e.g. 'properties'.
+	//  - ttl_seconds, until the record expires.  -1 if it never expires, -2 if
+	//    the player has no record.
+	//  - indices, the player's score in each index that holds them.
+	//  - assignment, if the player has been assigned.
+	//  - ignorelists, when the player was added to each ignorelist that holds
+	//    them, in epoch seconds.
+	DescribePlayer(ctx context.Context, in *PlayerId, opts ...grpc.CallOption) (*PlayerRecord, error)
+	// Delete many matchobjects from state storage in one round trip, e.g. for
+	// tooling that cleans up stale matches.
+	// INPUT: MatchIdList message with the IDs of the matchobjects to delete.
+	// OUTPUT: ResultList message with a result for each ID, in the same order.
+	//    'success' is false, and 'error' is 'match not found', for IDs that
+	//    weren't in state storage.
+	DeleteMatches(ctx context.Context, in *MatchIdList, opts ...grpc.CallOption) (*ResultList, error)
+	// Admin call returning the number of players currently in matchmaking:
+	// queued, and not yet assigned, deleted, or expired.  Requires
+	// 'activePlayers.enabled'; the count is also recorded as the
+	// 'frontend/players/active' metric.
+	// INPUT: ActivePlayerCountRequest message, which has no fields.
+	// OUTPUT: ActivePlayerCount message with the 'count' field populated.
+	GetActivePlayerCount(ctx context.Context, in *ActivePlayerCountRequest, opts ...grpc.CallOption) (*ActivePlayerCount, error)
+	// CreateMatchWithProgress is CreateMatch, but streams the progress markers
+	// the MMF reports through the MMLogic API ReportProgress call while it runs,
+	// e.g. "pools_queried" or "candidates_evaluated", before the results.
+	// INPUT: MatchObject message, as for CreateMatch.
+	// OUTPUT: a stream of MatchProgress messages.  The last one has the "match"
+	// field set to the MatchObject CreateMatch would return; if CreateMatch would
+	// fail, the stream ends with its error instead.
+	CreateMatchWithProgress(ctx context.Context, in *MatchObject, opts ...grpc.CallOption) (Backend_CreateMatchWithProgressClient, error)
 }
 
 type backendClient struct {
@@ -176,6 +293,124 @@ func (c *backendClient) DeleteAssignments(ctx context.Context, in *Roster, opts
 	return out, nil
 }
 
+func (c *backendClient) CancelMatch(ctx context.Context, in *MatchObject, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := grpc.Invoke(ctx, "/api.Backend/CancelMatch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) ListAssignments(ctx context.Context, in *ListAssignmentsRequest, opts ...grpc.CallOption) (*AssignmentList, error) {
+	out := new(AssignmentList)
+	err := grpc.Invoke(ctx, "/api.Backend/ListAssignments", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) GetHealth(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthStatus, error) {
+	out := new(HealthStatus)
+	err := grpc.Invoke(ctx, "/api.Backend/GetHealth", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) ListMatchesMulti(ctx context.Context, in *ProfileList, opts ...grpc.CallOption) (Backend_ListMatchesMultiClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Backend_serviceDesc.Streams[1], c.cc, "/api.Backend/ListMatchesMulti", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &backendListMatchesMultiClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Backend_ListMatchesMultiClient interface {
+	Recv() (*MatchObject, error)
+	grpc.ClientStream
+}
+
+type backendListMatchesMultiClient struct {
+	grpc.ClientStream
+}
+
+func (x *backendListMatchesMultiClient) Recv() (*MatchObject, error) {
+	m := new(MatchObject)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *backendClient) DescribePlayer(ctx context.Context, in *PlayerId, opts ...grpc.CallOption) (*PlayerRecord, error) {
+	out := new(PlayerRecord)
+	err := grpc.Invoke(ctx, "/api.Backend/DescribePlayer", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) DeleteMatches(ctx context.Context, in *MatchIdList, opts ...grpc.CallOption) (*ResultList, error) {
+	out := new(ResultList)
+	err := grpc.Invoke(ctx, "/api.Backend/DeleteMatches", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) GetActivePlayerCount(ctx context.Context, in *ActivePlayerCountRequest, opts ...grpc.CallOption) (*ActivePlayerCount, error) {
+	out := new(ActivePlayerCount)
+	err := grpc.Invoke(ctx, "/api.Backend/GetActivePlayerCount", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) CreateMatchWithProgress(ctx context.Context, in *MatchObject, opts ...grpc.CallOption) (Backend_CreateMatchWithProgressClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Backend_serviceDesc.Streams[2], c.cc, "/api.Backend/CreateMatchWithProgress", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &backendCreateMatchWithProgressClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Backend_CreateMatchWithProgressClient interface {
+	Recv() (*MatchProgress, error)
+	grpc.ClientStream
+}
+
+type backendCreateMatchWithProgressClient struct {
+	grpc.ClientStream
+}
+
+func (x *backendCreateMatchWithProgressClient) Recv() (*MatchProgress, error) {
+	m := new(MatchProgress)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for Backend service
 
 type BackendServer interface {
@@ -185,15 +420,35 @@ type BackendServer interface {
 	//  - properties
 	//  - [optional] roster, any fields you fill are available to your MMF.
 	//  - [optional] pools, any fields you fill are available to your MMF.
+	//  - [optional] mmf, the name of an MMF in the 'mmfs' config section to run.
+	//  - [optional] requested_players, the number of players the match should
+	//    contain. If 'matchValidation.strictPlayerCount' is set, matches of any
+	//    other size are returned as errors.
+	//  - [optional] properties.maxPlayers, the most players the match may
+	//    contain, overriding 'matchValidation.maxPlayers'. Larger matches are
+	//    returned as errors, or truncated if 'matchValidation.maxPlayersMode'
+	//    is 'truncate'.
+	//  - [optional] dry_run, to see the match the MMF would make without
+	//    proposing or deindexing its players, or keeping the match in state
+	//    storage.  Not supported by ListMatches.
 	// OUTPUT: MatchObject message with these fields populated:
 	//  - id
 	//  - properties
 	//  - error. Empty if no error was encountered
+	//  - no_match_reason. If the MMF found no match, why, when the MMF says.
 	//  - rosters, if you choose to fill them in your MMF. (Recommended)
 	//  - pools, if you used the MMLogicAPI in your MMF. (Recommended, and provides stats)
+	//    If no match was found, the profile's pools are returned if the MMF
+	//    returned none, and every pool without stats gets the number of
+	//    players each of its filters matches.
 	CreateMatch(context.Context, *MatchObject) (*MatchObject, error)
 	// Continually run MMF and stream matchobjects that fit this profile until
 	// client closes the connection.  Same inputs/outputs as CreateMatch.
+	// A match attempt that finds nothing (the MMF set the 'error' field, or
+	// the match failed validation) is streamed with 'error' populated, and the
+	// stream continues.  If the stream itself fails, it ends with a gRPC status:
+	// DEADLINE_EXCEEDED if no MMF results appeared in time, UNAVAILABLE if state
+	// storage failed.
 	ListMatches(*MatchObject, Backend_ListMatchesServer) error
 	// Delete a matchobject from state storage manually. (Matchobjects in state
 	// storage will also automatically expire after a while)
@@ -207,12 +462,30 @@ type BackendServer interface {
 	// the player from future matchmaking functions by adding them to the
 	// 'deindexed' player list and then deleting their player ID from state storage
 	// indexes.
+	// If 'assignments.deindexAt' is set to 'match', players are instead
+	// deindexed when their match is returned by CreateMatch or ListMatches.
 	// INPUT: Assignments message with these fields populated:
 	//  - connection_info, anything you write to this string is sent to Frontend API
 	//  - rosters. You can send any number of rosters, containing any number of
 	//     player messages. All players from all rosters will be sent the connection_info.
 	//     The only field in the Player object that is used by CreateAssignments is
 	//     the id field.  All others are silently ignored.
+	//     Players whose stored properties match one of the
+	//     'assignments.routing.rules' are sent that rule's connection string
+	//     instead of connection_info.
+	//  - [optional] nonce and timestamp.  Required when
+	//     'assignments.replayProtection' is enabled, in which case calls with a
+	//     timestamp older than 'assignments.maxAge' seconds or a nonce that was
+	//     already used are rejected.
+	//  - [optional] only_unassigned.  When set, each player is only assigned if
+	//     they have no assignment yet, checked and written atomically so two
+	//     backends can't both assign the same player.  Players that were
+	//     already assigned are left unchanged and listed in the result's
+	//     'conflicts' field, and the result's success is false if there are any.
+	//  - [optional] skip_deindex.  When set, the connection info is written,
+	//     but the players stay indexed and in matchmaking, e.g. to attach a
+	//     voice server to players still in the queue.  Their assignments aren't
+	//     tracked for expiry either.
 	CreateAssignments(context.Context, *Assignments) (*Result, error)
 	// Remove DGS connection info from state storage for players.
 	// INPUT: Roster message with the 'players' field populated.
@@ -220,6 +493,69 @@ type BackendServer interface {
 	//    DeleteAssignments is the 'id' field.  All others are silently ignored.  If
 	//    you need to delete multiple rosters, make multiple calls.
 	DeleteAssignments(context.Context, *Roster) (*Result, error)
+	// Cancel any in-flight CreateMatch or ListMatches calls on this Backend API
+	// instance that are working on the provided profile.  Cancelled CreateMatch
+	// calls remove their request from the profile queue if an MMF hasn't picked
+	// it up yet.
+	// INPUT: MatchObject message with the 'id' field populated with the profile id.
+	// (All other fields are ignored.)
+	CancelMatch(context.Context, *MatchObject) (*Result, error)
+	// Admin call to list current player assignments, a page at a time, for
+	// dashboards.  Assignments are returned in no particular order.
+	// INPUT: ListAssignmentsRequest message with these fields populated:
+	//  - [optional] limit, the maximum number of assignments to return.
+	//  - [optional] cursor, the next_cursor returned by the previous call.
+	// OUTPUT: AssignmentList message with these fields populated:
+	//  - assignments, player IDs and their connection info.
+	//  - next_cursor, empty once every assignment has been listed.
+	ListAssignments(context.Context, *ListAssignmentsRequest) (*AssignmentList, error)
+	// Admin call reporting the health of this Backend API instance and its
+	// dependencies in one response, for dashboards and monitoring.
+	// INPUT: HealthRequest message, which has no fields.
+	// OUTPUT: HealthStatus message with a check for state storage and for each
+	//    HTTP MMF in the config, state storage pool utilization, and whether
+	//    the instance is draining.
+	GetHealth(context.Context, *HealthRequest) (*HealthStatus, error)
+	// Like ListMatches, but runs several profiles at once and multiplexes their
+	// matches over a single stream, so a backend serving many game modes needs
+	// only one connection.  Each match's profile_id holds the ID of the profile
+	// it was made for.  Cancelling a profile with CancelMatch stops only that
+	// profile; the stream ends once every profile has stopped.
+	ListMatchesMulti(*ProfileList, Backend_ListMatchesMultiServer) error
+	// Admin call returning everything state storage holds about one player, for
+	// support and debugging without searching redis by hand.
+	// INPUT: PlayerId message with the 'id' field populated.
+	// OUTPUT: PlayerRecord message with these fields populated:
+	//  - fields, every field of the player's record, e.g. 'properties'.
+	//  - ttl_seconds, until the record expires.  -1 if it never expires, -2 if
+	//    the player has no record.
+	//  - indices, the player's score in each index that holds them.
+	//  - assignment, if the player has been assigned.
+	//  - ignorelists, when the player was added to each ignorelist that holds
+	//    them, in epoch seconds.
+	DescribePlayer(context.Context, *PlayerId) (*PlayerRecord, error)
+	// Delete many matchobjects from state storage in one round trip, e.g. for
+	// tooling that cleans up stale matches.
+	// INPUT: MatchIdList message with the IDs of the matchobjects to delete.
+	// OUTPUT: ResultList message with a result for each ID, in the same order.
+	//    'success' is false, and 'error' is 'match not found', for IDs that
+	//    weren't in state storage.
+	DeleteMatches(context.Context, *MatchIdList) (*ResultList, error)
+	// Admin call returning the number of players currently in matchmaking:
+	// queued, and not yet assigned, deleted, or expired.  Requires
+	// 'activePlayers.enabled'; the count is also recorded as the
+	// 'frontend/players/active' metric.
+	// INPUT: ActivePlayerCountRequest message, which has no fields.
+	// OUTPUT: ActivePlayerCount message with the 'count' field populated.
+	GetActivePlayerCount(context.Context, *ActivePlayerCountRequest) (*ActivePlayerCount, error)
+	// CreateMatchWithProgress is CreateMatch, but streams the progress markers
+	// the MMF reports through the MMLogic API ReportProgress call while it runs,
+	// e.g. "pools_queried" or "candidates_evaluated", before the results.
+	// INPUT: MatchObject message, as for CreateMatch.
+	// OUTPUT: a stream of MatchProgress messages.  The last one has the "match"
+	// field set to the MatchObject CreateMatch would return; if CreateMatch would
+	// fail, the stream ends with its error instead.
+	CreateMatchWithProgress(*MatchObject, Backend_CreateMatchWithProgressServer) error
 }
 
 func RegisterBackendServer(s *grpc.Server, srv BackendServer) {
@@ -319,6 +655,156 @@ func _Backend_DeleteAssignments_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Backend_CancelMatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MatchObject)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).CancelMatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Backend/CancelMatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).CancelMatch(ctx, req.(*MatchObject))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_ListAssignments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAssignmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).ListAssignments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Backend/ListAssignments",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).ListAssignments(ctx, req.(*ListAssignmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_GetHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).GetHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Backend/GetHealth",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).GetHealth(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_ListMatchesMulti_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ProfileList)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BackendServer).ListMatchesMulti(m, &backendListMatchesMultiServer{stream})
+}
+
+type Backend_ListMatchesMultiServer interface {
+	Send(*MatchObject) error
+	grpc.ServerStream
+}
+
+type backendListMatchesMultiServer struct {
+	grpc.ServerStream
+}
+
+func (x *backendListMatchesMultiServer) Send(m *MatchObject) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Backend_DescribePlayer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayerId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).DescribePlayer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Backend/DescribePlayer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).DescribePlayer(ctx, req.(*PlayerId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_DeleteMatches_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MatchIdList)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).DeleteMatches(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Backend/DeleteMatches",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).DeleteMatches(ctx, req.(*MatchIdList))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_GetActivePlayerCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActivePlayerCountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).GetActivePlayerCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Backend/GetActivePlayerCount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).GetActivePlayerCount(ctx, req.(*ActivePlayerCountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_CreateMatchWithProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(MatchObject)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BackendServer).CreateMatchWithProgress(m, &backendCreateMatchWithProgressServer{stream})
+}
+
+type Backend_CreateMatchWithProgressServer interface {
+	Send(*MatchProgress) error
+	grpc.ServerStream
+}
+
+type backendCreateMatchWithProgressServer struct {
+	grpc.ServerStream
+}
+
+func (x *backendCreateMatchWithProgressServer) Send(m *MatchProgress) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Backend_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "api.Backend",
 	HandlerType: (*BackendServer)(nil),
@@ -339,6 +825,30 @@ var _Backend_serviceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteAssignments",
 			Handler:    _Backend_DeleteAssignments_Handler,
 		},
+		{
+			MethodName: "CancelMatch",
+			Handler:    _Backend_CancelMatch_Handler,
+		},
+		{
+			MethodName: "ListAssignments",
+			Handler:    _Backend_ListAssignments_Handler,
+		},
+		{
+			MethodName: "GetHealth",
+			Handler:    _Backend_GetHealth_Handler,
+		},
+		{
+			MethodName: "DescribePlayer",
+			Handler:    _Backend_DescribePlayer_Handler,
+		},
+		{
+			MethodName: "DeleteMatches",
+			Handler:    _Backend_DeleteMatches_Handler,
+		},
+		{
+			MethodName: "GetActivePlayerCount",
+			Handler:    _Backend_GetActivePlayerCount_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -346,6 +856,16 @@ var _Backend_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Backend_ListMatches_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ListMatchesMulti",
+			Handler:       _Backend_ListMatchesMulti_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "CreateMatchWithProgress",
+			Handler:       _Backend_CreateMatchWithProgress_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "api/protobuf-spec/backend.proto",
 }
@@ -353,20 +873,32 @@ var _Backend_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("api/protobuf-spec/backend.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 240 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x91, 0x3f, 0x4b, 0x04, 0x31,
-	0x10, 0x47, 0xfd, 0x03, 0x0a, 0xd9, 0xc6, 0x0b, 0xd8, 0x5c, 0xa3, 0xd8, 0xdf, 0x46, 0x14, 0x51,
-	0x0b, 0x15, 0xef, 0x04, 0x1b, 0x45, 0xb1, 0xb4, 0x4b, 0x72, 0x73, 0x7b, 0xd1, 0x24, 0x13, 0x32,
-	0xb3, 0x5f, 0xcf, 0xcf, 0x26, 0xbb, 0x01, 0x5d, 0x71, 0x41, 0x6c, 0x1f, 0xef, 0xf1, 0x9b, 0x10,
-	0x71, 0xa0, 0x93, 0x53, 0x29, 0x23, 0xa3, 0x69, 0x57, 0x33, 0x4a, 0x60, 0x95, 0xd1, 0xf6, 0x1d,
-	0xe2, 0xb2, 0xee, 0xa9, 0xdc, 0xd6, 0xc9, 0x4d, 0x0f, 0x7f, 0x5b, 0x01, 0x88, 0x74, 0x03, 0x54,
-	0xb4, 0x93, 0x8f, 0x2d, 0xb1, 0x3b, 0x2f, 0xa1, 0xbc, 0x12, 0xd5, 0x22, 0x83, 0x66, 0x78, 0xd4,
-	0x6c, 0xd7, 0x72, 0xbf, 0xfe, 0x72, 0x7b, 0xf0, 0x64, 0xde, 0xc0, 0xf2, 0x74, 0x1c, 0x1f, 0x6d,
-	0xc8, 0x1b, 0x51, 0x3d, 0x38, 0xe2, 0x1e, 0x02, 0xfd, 0x37, 0x3f, 0xde, 0x94, 0x17, 0xa2, 0xba,
-	0x03, 0x0f, 0x7f, 0xec, 0xef, 0x7d, 0xe3, 0x17, 0xa0, 0xd6, 0x77, 0xd3, 0xd7, 0x62, 0x52, 0x2e,
-	0xbf, 0x25, 0x72, 0x4d, 0x0c, 0x10, 0xf9, 0xc7, 0x01, 0x03, 0x3c, 0xda, 0x5f, 0x8a, 0x49, 0x59,
-	0x1e, 0xf6, 0x43, 0x11, 0x89, 0x21, 0x8f, 0xa5, 0xf3, 0xf3, 0xd7, 0xb3, 0xc6, 0xf1, 0xba, 0x35,
-	0xb5, 0xc5, 0xa0, 0xee, 0x11, 0x1b, 0x0f, 0x0b, 0x8f, 0xed, 0xf2, 0xd9, 0x6b, 0x5e, 0x61, 0x0e,
-	0x0a, 0x13, 0xc4, 0x59, 0xe8, 0x5e, 0xa0, 0x5c, 0x64, 0xc8, 0x51, 0x7b, 0x95, 0x8c, 0xd9, 0xe9,
-	0x3f, 0xe0, 0xf4, 0x33, 0x00, 0x00, 0xff, 0xff, 0x33, 0x3a, 0x1e, 0x0d, 0xca, 0x01, 0x00, 0x00,
+	// 422 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x95, 0x53, 0x5d, 0x4b, 0xc3, 0x30,
+	0x14, 0x55, 0x84, 0x89, 0x19, 0x7e, 0x15, 0x75, 0x32, 0x1f, 0x1c, 0xbe, 0xdb, 0x8a, 0x22, 0xba,
+	0x07, 0x95, 0xb9, 0x81, 0x0a, 0x0e, 0xc7, 0x7c, 0x10, 0x7d, 0x4b, 0xd3, 0xbb, 0x2e, 0x9a, 0x36,
+	0x35, 0xb9, 0x15, 0xfc, 0xdf, 0xfe, 0x00, 0xd3, 0xd6, 0xb9, 0x58, 0xab, 0xe2, 0x63, 0x4e, 0xce,
+	0xb9, 0xe7, 0xe6, 0xe4, 0x5e, 0xb2, 0x4d, 0x13, 0xee, 0x25, 0x4a, 0xa2, 0xf4, 0xd3, 0xd1, 0xae,
+	0x4e, 0x80, 0x79, 0x3e, 0x65, 0x4f, 0x10, 0x07, 0x6e, 0x8e, 0x3a, 0x73, 0x86, 0xd0, 0x6c, 0x7d,
+	0x67, 0x45, 0xa0, 0x35, 0x0d, 0x41, 0x17, 0xb4, 0x2a, 0xc6, 0x48, 0xc9, 0x18, 0x3f, 0x0b, 0xed,
+	0xbf, 0xd5, 0xc8, 0xfc, 0x79, 0x51, 0xda, 0x39, 0x21, 0xf5, 0xae, 0x02, 0x8a, 0xd0, 0xa7, 0xc8,
+	0xc6, 0xce, 0xba, 0xfb, 0x59, 0x2d, 0x07, 0x6e, 0xfc, 0x47, 0x60, 0xd8, 0xac, 0x86, 0x77, 0x66,
+	0x9c, 0x33, 0x52, 0xbf, 0xe6, 0x1a, 0x73, 0x10, 0xf4, 0x7f, 0xe5, 0x7b, 0xb3, 0xce, 0x31, 0xa9,
+	0xf7, 0x40, 0xc0, 0x1f, 0xfe, 0x2b, 0x53, 0x78, 0x08, 0x3a, 0x15, 0x99, 0xf5, 0x29, 0x59, 0x2d,
+	0x3a, 0xef, 0x68, 0xcd, 0xc3, 0x38, 0x82, 0x18, 0xbf, 0x34, 0x60, 0xc1, 0x95, 0xfa, 0x36, 0x59,
+	0x2d, 0x9c, 0x6d, 0xbd, 0x4d, 0x94, 0x1a, 0x41, 0x55, 0x4a, 0x4d, 0xd3, 0x5d, 0x1a, 0x33, 0x10,
+	0xff, 0x6e, 0xfa, 0x86, 0x2c, 0x67, 0x79, 0xd9, 0x96, 0xad, 0x29, 0xad, 0x74, 0x35, 0x84, 0xe7,
+	0x14, 0x34, 0x36, 0x37, 0xab, 0x1e, 0x95, 0x71, 0xf3, 0x14, 0x16, 0x2e, 0x00, 0x2f, 0x81, 0x0a,
+	0x1c, 0x3b, 0x8d, 0x29, 0xb1, 0x40, 0x26, 0x15, 0x36, 0xca, 0x17, 0xb7, 0x48, 0x31, 0xd5, 0x46,
+	0xdf, 0x23, 0x2b, 0xd6, 0x07, 0xf6, 0x4d, 0x97, 0xdc, 0x7e, 0xcf, 0x40, 0xc9, 0x11, 0x17, 0x90,
+	0x51, 0x7e, 0xfb, 0xc5, 0x36, 0x59, 0xea, 0x81, 0x66, 0x8a, 0xfb, 0x30, 0x10, 0xf4, 0x15, 0x94,
+	0xb3, 0xe8, 0x9a, 0x31, 0x74, 0x8b, 0xc3, 0x55, 0x60, 0x37, 0x50, 0x60, 0x43, 0x60, 0x52, 0x05,
+	0xf9, 0x03, 0x16, 0xad, 0x01, 0xa8, 0x98, 0xa1, 0xab, 0x20, 0x77, 0x5f, 0x2b, 0xa7, 0xf9, 0x11,
+	0xc0, 0x3d, 0x59, 0x33, 0x01, 0x74, 0x18, 0xf2, 0x97, 0x0f, 0xef, 0xae, 0x4c, 0x63, 0x74, 0x76,
+	0xac, 0xd0, 0xca, 0x97, 0x93, 0x58, 0xb6, 0x7e, 0xe1, 0x98, 0xd2, 0x7d, 0xd2, 0xb0, 0x76, 0xe3,
+	0x8e, 0xe3, 0xd8, 0xc4, 0x11, 0x2a, 0x23, 0xf9, 0xe9, 0xcb, 0x1b, 0x25, 0x78, 0xc2, 0xcf, 0x42,
+	0x3a, 0x3f, 0x7a, 0x38, 0x0c, 0x4d, 0x8d, 0xd4, 0x77, 0x99, 0x8c, 0xbc, 0x0b, 0x29, 0x43, 0x01,
+	0x5d, 0x21, 0xd3, 0xc0, 0xb8, 0xe2, 0x48, 0xaa, 0xc8, 0x93, 0x09, 0xc4, 0xbb, 0x51, 0x26, 0xf4,
+	0xb8, 0x59, 0x58, 0x15, 0x53, 0xe1, 0x25, 0xbe, 0x5f, 0xcb, 0xd7, 0xf6, 0xe0, 0x1d, 0x03, 0x21,
+	0xbc, 0xaa, 0x22, 0x04, 0x00, 0x00,
 }
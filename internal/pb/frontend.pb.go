@@ -17,6 +17,34 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
+// A player's place in matchmaking.
+type PlayerState int32
+
+const (
+	PlayerState_NOT_FOUND PlayerState = 0
+	PlayerState_QUEUED    PlayerState = 1
+	PlayerState_DEINDEXED PlayerState = 2
+	PlayerState_ASSIGNED  PlayerState = 3
+)
+
+var PlayerState_name = map[int32]string{
+	0: "NOT_FOUND",
+	1: "QUEUED",
+	2: "DEINDEXED",
+	3: "ASSIGNED",
+}
+var PlayerState_value = map[string]int32{
+	"NOT_FOUND": 0,
+	"QUEUED":    1,
+	"DEINDEXED": 2,
+	"ASSIGNED":  3,
+}
+
+func (x PlayerState) String() string {
+	return proto.EnumName(PlayerState_name, int32(x))
+}
+func (PlayerState) EnumDescriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
+
 // Data structure for a group of players  to pass to the matchmaking function.
 // Obviously, the group can be a group of one!
 type Group struct {
@@ -59,9 +87,78 @@ func (m *PlayerId) GetId() string {
 	return ""
 }
 
+type AssignmentWebhook struct {
+	PlayerId *PlayerId `protobuf:"bytes,1,opt,name=player_id,json=playerId" json:"player_id,omitempty"`
+	Url      string    `protobuf:"bytes,2,opt,name=url" json:"url,omitempty"`
+}
+
+func (m *AssignmentWebhook) Reset()                    { *m = AssignmentWebhook{} }
+func (m *AssignmentWebhook) String() string            { return proto.CompactTextString(m) }
+func (*AssignmentWebhook) ProtoMessage()               {}
+func (*AssignmentWebhook) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
+
+func (m *AssignmentWebhook) GetPlayerId() *PlayerId {
+	if m != nil {
+		return m.PlayerId
+	}
+	return nil
+}
+
+func (m *AssignmentWebhook) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+// IDs of the players to report on, e.g. the members of a party.
+type PlayerIds struct {
+	Ids []string `protobuf:"bytes,1,rep,name=ids" json:"ids,omitempty"`
+}
+
+func (m *PlayerIds) Reset()                    { *m = PlayerIds{} }
+func (m *PlayerIds) String() string            { return proto.CompactTextString(m) }
+func (*PlayerIds) ProtoMessage()               {}
+func (*PlayerIds) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+
+func (m *PlayerIds) GetIds() []string {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+type PlayerStatus struct {
+	Id    string      `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	State PlayerState `protobuf:"varint,2,opt,name=state,enum=api.PlayerState" json:"state,omitempty"`
+}
+
+func (m *PlayerStatus) Reset()                    { *m = PlayerStatus{} }
+func (m *PlayerStatus) String() string            { return proto.CompactTextString(m) }
+func (*PlayerStatus) ProtoMessage()               {}
+func (*PlayerStatus) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{4} }
+
+func (m *PlayerStatus) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *PlayerStatus) GetState() PlayerState {
+	if m != nil {
+		return m.State
+	}
+	return PlayerState_NOT_FOUND
+}
+
 func init() {
 	proto.RegisterType((*Group)(nil), "api.Group")
 	proto.RegisterType((*PlayerId)(nil), "api.PlayerId")
+	proto.RegisterType((*AssignmentWebhook)(nil), "api.AssignmentWebhook")
+	proto.RegisterType((*PlayerIds)(nil), "api.PlayerIds")
+	proto.RegisterType((*PlayerStatus)(nil), "api.PlayerStatus")
+	proto.RegisterEnum("api.PlayerState", PlayerState_name, PlayerState_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -77,8 +174,39 @@ const _ = grpc.SupportPackageIsVersion4
 type FrontendClient interface {
 	CreateRequest(ctx context.Context, in *Group, opts ...grpc.CallOption) (*Result, error)
 	DeleteRequest(ctx context.Context, in *Group, opts ...grpc.CallOption) (*Result, error)
+	// Wait for the player's assignment and return its connection info.  If
+	// 'assignments.watchHeader' is set, a response header is sent as soon as
+	// the wait begins, before the assignment: 'x-openmatch-watch' is
+	// "started", and 'x-openmatch-player-found' says whether the player's
+	// record exists.  If 'assignments.matchPending' is set and no assignment
+	// arrives in time, a player claimed for a match but not yet assigned
+	// gets a match_pending response ('response') or an Unavailable error
+	// ('status'), and a player who isn't in matchmaking a NotFound error.
+	// Otherwise, unless 'assignments.timeoutMode' is 'retry', a player with no
+	// assignment in time gets 'assignments.fallbackConnstring', if set, with
+	// fallback set, e.g. to send them to a lobby server.
 	GetAssignment(ctx context.Context, in *PlayerId, opts ...grpc.CallOption) (*ConnectionInfo, error)
 	DeleteAssignment(ctx context.Context, in *PlayerId, opts ...grpc.CallOption) (*Result, error)
+	// Ask the Frontend API to POST the player's ConnectionInfo, as JSON, to
+	// the provided URL once an assignment is made, instead of the client
+	// polling GetAssignment. The URL's host must be listed in the
+	// 'webhooks.allowedHosts' config.
+	RegisterAssignmentWebhook(ctx context.Context, in *AssignmentWebhook, opts ...grpc.CallOption) (*Result, error)
+	// Reset the expiry of a queued player's record to the configured queue TTL,
+	// without changing their properties. Clients in long matchmaking sessions
+	// should call this periodically to stay in the queue.
+	RefreshRequest(ctx context.Context, in *PlayerId, opts ...grpc.CallOption) (*Result, error)
+	// Enqueue a stream of groups over a single long-lived call. Each group is
+	// acknowledged with a Result whose id is the group's id. Groups that
+	// arrive together are written to state storage in a single pipeline.
+	StreamRequests(ctx context.Context, opts ...grpc.CallOption) (Frontend_StreamRequestsClient, error)
+	// Merge the properties in the Group into a queued player's properties,
+	// re-indexing only the properties it contains. Other properties, the
+	// record's expiry and the player's place in the queue are unchanged.
+	UpdateRequest(ctx context.Context, in *Group, opts ...grpc.CallOption) (*Result, error)
+	// Report the matchmaking state of each player, e.g. every member of a
+	// party, in one call.  Statuses are streamed in the order of the IDs.
+	GetPlayerStatuses(ctx context.Context, in *PlayerIds, opts ...grpc.CallOption) (Frontend_GetPlayerStatusesClient, error)
 }
 
 type frontendClient struct {
@@ -125,13 +253,134 @@ func (c *frontendClient) DeleteAssignment(ctx context.Context, in *PlayerId, opt
 	return out, nil
 }
 
+func (c *frontendClient) RegisterAssignmentWebhook(ctx context.Context, in *AssignmentWebhook, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := grpc.Invoke(ctx, "/api.Frontend/RegisterAssignmentWebhook", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *frontendClient) RefreshRequest(ctx context.Context, in *PlayerId, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := grpc.Invoke(ctx, "/api.Frontend/RefreshRequest", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *frontendClient) StreamRequests(ctx context.Context, opts ...grpc.CallOption) (Frontend_StreamRequestsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Frontend_serviceDesc.Streams[0], c.cc, "/api.Frontend/StreamRequests", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &frontendStreamRequestsClient{stream}
+	return x, nil
+}
+
+type Frontend_StreamRequestsClient interface {
+	Send(*Group) error
+	Recv() (*Result, error)
+	grpc.ClientStream
+}
+
+type frontendStreamRequestsClient struct {
+	grpc.ClientStream
+}
+
+func (x *frontendStreamRequestsClient) Send(m *Group) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *frontendStreamRequestsClient) Recv() (*Result, error) {
+	m := new(Result)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *frontendClient) UpdateRequest(ctx context.Context, in *Group, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := grpc.Invoke(ctx, "/api.Frontend/UpdateRequest", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *frontendClient) GetPlayerStatuses(ctx context.Context, in *PlayerIds, opts ...grpc.CallOption) (Frontend_GetPlayerStatusesClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Frontend_serviceDesc.Streams[1], c.cc, "/api.Frontend/GetPlayerStatuses", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &frontendGetPlayerStatusesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Frontend_GetPlayerStatusesClient interface {
+	Recv() (*PlayerStatus, error)
+	grpc.ClientStream
+}
+
+type frontendGetPlayerStatusesClient struct {
+	grpc.ClientStream
+}
+
+func (x *frontendGetPlayerStatusesClient) Recv() (*PlayerStatus, error) {
+	m := new(PlayerStatus)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for Frontend service
 
 type FrontendServer interface {
 	CreateRequest(context.Context, *Group) (*Result, error)
 	DeleteRequest(context.Context, *Group) (*Result, error)
+	// Wait for the player's assignment and return its connection info.  If
+	// 'assignments.watchHeader' is set, a response header is sent as soon as
+	// the wait begins, before the assignment: 'x-openmatch-watch' is
+	// "started", and 'x-openmatch-player-found' says whether the player's
+	// record exists.  If 'assignments.matchPending' is set and no assignment
+	// arrives in time, a player claimed for a match but not yet assigned
+	// gets a match_pending response ('response') or an Unavailable error
+	// ('status'), and a player who isn't in matchmaking a NotFound error.
+	// Otherwise, unless 'assignments.timeoutMode' is 'retry', a player with no
+	// assignment in time gets 'assignments.fallbackConnstring', if set, with
+	// fallback set, e.g. to send them to a lobby server.
 	GetAssignment(context.Context, *PlayerId) (*ConnectionInfo, error)
 	DeleteAssignment(context.Context, *PlayerId) (*Result, error)
+	// Ask the Frontend API to POST the player's ConnectionInfo, as JSON, to
+	// the provided URL once an assignment is made, instead of the client
+	// polling GetAssignment. The URL's host must be listed in the
+	// 'webhooks.allowedHosts' config.
+	RegisterAssignmentWebhook(context.Context, *AssignmentWebhook) (*Result, error)
+	// Reset the expiry of a queued player's record to the configured queue TTL,
+	// without changing their properties. Clients in long matchmaking sessions
+	// should call this periodically to stay in the queue.
+	RefreshRequest(context.Context, *PlayerId) (*Result, error)
+	// Enqueue a stream of groups over a single long-lived call. Each group is
+	// acknowledged with a Result whose id is the group's id. Groups that
+	// arrive together are written to state storage in a single pipeline.
+	StreamRequests(Frontend_StreamRequestsServer) error
+	// Merge the properties in the Group into a queued player's properties,
+	// re-indexing only the properties it contains. Other properties, the
+	// record's expiry and the player's place in the queue are unchanged.
+	UpdateRequest(context.Context, *Group) (*Result, error)
+	// Report the matchmaking state of each player, e.g. every member of a
+	// party, in one call.  Statuses are streamed in the order of the IDs.
+	GetPlayerStatuses(*PlayerIds, Frontend_GetPlayerStatusesServer) error
 }
 
 func RegisterFrontendServer(s *grpc.Server, srv FrontendServer) {
@@ -210,6 +459,107 @@ func _Frontend_DeleteAssignment_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Frontend_RegisterAssignmentWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AssignmentWebhook)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FrontendServer).RegisterAssignmentWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Frontend/RegisterAssignmentWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FrontendServer).RegisterAssignmentWebhook(ctx, req.(*AssignmentWebhook))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Frontend_RefreshRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayerId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FrontendServer).RefreshRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Frontend/RefreshRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FrontendServer).RefreshRequest(ctx, req.(*PlayerId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Frontend_StreamRequests_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(FrontendServer).StreamRequests(&frontendStreamRequestsServer{stream})
+}
+
+type Frontend_StreamRequestsServer interface {
+	Send(*Result) error
+	Recv() (*Group, error)
+	grpc.ServerStream
+}
+
+type frontendStreamRequestsServer struct {
+	grpc.ServerStream
+}
+
+func (x *frontendStreamRequestsServer) Send(m *Result) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *frontendStreamRequestsServer) Recv() (*Group, error) {
+	m := new(Group)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Frontend_UpdateRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Group)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FrontendServer).UpdateRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Frontend/UpdateRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FrontendServer).UpdateRequest(ctx, req.(*Group))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Frontend_GetPlayerStatuses_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PlayerIds)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FrontendServer).GetPlayerStatuses(m, &frontendGetPlayerStatusesServer{stream})
+}
+
+type Frontend_GetPlayerStatusesServer interface {
+	Send(*PlayerStatus) error
+	grpc.ServerStream
+}
+
+type frontendGetPlayerStatusesServer struct {
+	grpc.ServerStream
+}
+
+func (x *frontendGetPlayerStatusesServer) Send(m *PlayerStatus) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Frontend_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "api.Frontend",
 	HandlerType: (*FrontendServer)(nil),
@@ -230,31 +580,68 @@ var _Frontend_serviceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteAssignment",
 			Handler:    _Frontend_DeleteAssignment_Handler,
 		},
+		{
+			MethodName: "RegisterAssignmentWebhook",
+			Handler:    _Frontend_RegisterAssignmentWebhook_Handler,
+		},
+		{
+			MethodName: "RefreshRequest",
+			Handler:    _Frontend_RefreshRequest_Handler,
+		},
+		{
+			MethodName: "UpdateRequest",
+			Handler:    _Frontend_UpdateRequest_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamRequests",
+			Handler:       _Frontend_StreamRequests_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "GetPlayerStatuses",
+			Handler:       _Frontend_GetPlayerStatuses_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/protobuf-spec/frontend.proto",
 }
 
 func init() { proto.RegisterFile("api/protobuf-spec/frontend.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 278 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0xd0, 0x4f, 0x4b, 0xc3, 0x40,
-	0x10, 0x05, 0xf0, 0x26, 0xa2, 0xd4, 0x85, 0x48, 0xd9, 0x53, 0xc8, 0x41, 0x4a, 0x4e, 0x5e, 0x9a,
-	0x05, 0xa5, 0x14, 0xbc, 0x69, 0xc4, 0xd0, 0x5b, 0xc9, 0xd1, 0xdb, 0x26, 0x99, 0xa4, 0x0b, 0x9b,
-	0x9d, 0x75, 0x77, 0xf6, 0xe0, 0xa7, 0xf5, 0xab, 0x88, 0xa9, 0xff, 0x90, 0x0a, 0x5e, 0x1f, 0xf3,
-	0xe3, 0x3d, 0x86, 0x2d, 0xa5, 0x55, 0xc2, 0x3a, 0x24, 0x6c, 0x42, 0xbf, 0xf2, 0x16, 0x5a, 0xd1,
-	0x3b, 0x34, 0x04, 0xa6, 0x2b, 0xa6, 0x98, 0x9f, 0x48, 0xab, 0xb2, 0x23, 0x67, 0x23, 0x78, 0x2f,
-	0x07, 0xf0, 0x87, 0xb3, 0x7c, 0xc3, 0x4e, 0x2b, 0x87, 0xc1, 0xf2, 0x0b, 0x16, 0xab, 0x2e, 0x8d,
-	0x96, 0xd1, 0xd5, 0x79, 0x1d, 0xab, 0x8e, 0x5f, 0x32, 0x66, 0x1d, 0x5a, 0x70, 0xa4, 0xc0, 0xa7,
-	0xf1, 0x94, 0xff, 0x48, 0xf2, 0x8c, 0xcd, 0x77, 0x5a, 0xbe, 0x80, 0xdb, 0x76, 0xbf, 0xed, 0xf5,
-	0x6b, 0xc4, 0xe6, 0x8f, 0x1f, 0x73, 0xb8, 0x60, 0x49, 0xe9, 0x40, 0x12, 0xd4, 0xf0, 0x1c, 0xc0,
-	0x13, 0x67, 0x85, 0xb4, 0xaa, 0x98, 0x5a, 0xb3, 0x45, 0xf1, 0xb5, 0xa7, 0x06, 0x1f, 0x34, 0xe5,
-	0xb3, 0x77, 0xf0, 0x00, 0x1a, 0xfe, 0x0f, 0x6e, 0x59, 0x52, 0x01, 0xdd, 0x79, 0xaf, 0x06, 0x33,
-	0x82, 0x21, 0x9e, 0x4c, 0xe0, 0x73, 0x5e, 0x96, 0x7e, 0x9b, 0x12, 0x8d, 0x81, 0x96, 0x14, 0x9a,
-	0xad, 0xe9, 0x31, 0x9f, 0xf1, 0x35, 0x5b, 0x1c, 0xca, 0xfe, 0xe6, 0x47, 0x2a, 0xef, 0x37, 0x4f,
-	0xeb, 0x41, 0xd1, 0x3e, 0x34, 0x45, 0x8b, 0xa3, 0xa8, 0x10, 0x07, 0x0d, 0xa5, 0xc6, 0xd0, 0xed,
-	0xb4, 0xa4, 0x1e, 0xdd, 0x28, 0xd0, 0x82, 0x59, 0x8d, 0x92, 0xda, 0xbd, 0x50, 0x86, 0xc0, 0x19,
-	0xa9, 0x85, 0x6d, 0x9a, 0xb3, 0xe9, 0xed, 0x37, 0x6f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x97, 0x2e,
-	0x6a, 0x58, 0xc1, 0x01, 0x00, 0x00,
+	// 490 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x95, 0x53, 0xc1, 0x6e, 0xda, 0x40,
+	0x10, 0xc5, 0xd0, 0x44, 0x30, 0x09, 0xc8, 0xec, 0xa1, 0xa2, 0x96, 0x5a, 0x45, 0x3e, 0x54, 0x51,
+	0xa4, 0xd8, 0x11, 0x69, 0x14, 0xa9, 0xb7, 0x14, 0x1b, 0x8b, 0x0b, 0x69, 0x4c, 0x51, 0xab, 0x5e,
+	0x22, 0x1b, 0x8f, 0x8d, 0x15, 0x7b, 0xd7, 0xdd, 0x5d, 0x1f, 0x7a, 0xeb, 0xa7, 0x77, 0x6d, 0xa0,
+	0x21, 0x81, 0xaa, 0xe9, 0x6d, 0x76, 0xe6, 0xbd, 0x79, 0xbb, 0x6f, 0x66, 0xe1, 0x24, 0x28, 0x52,
+	0xbb, 0xe0, 0x4c, 0xb2, 0xb0, 0x8c, 0xcf, 0x45, 0x81, 0x0b, 0x3b, 0xe6, 0x8c, 0x4a, 0xa4, 0x91,
+	0x55, 0xa7, 0x49, 0x4b, 0x21, 0x8c, 0x3d, 0xb0, 0x1c, 0x85, 0x08, 0x12, 0x14, 0x2b, 0x98, 0x79,
+	0x0d, 0x07, 0x1e, 0x67, 0x65, 0x41, 0x7a, 0xd0, 0x4c, 0xa3, 0x81, 0x76, 0xa2, 0x9d, 0x76, 0x7c,
+	0x15, 0x91, 0x77, 0x00, 0x0a, 0x51, 0x20, 0x97, 0x29, 0x8a, 0x41, 0xb3, 0xce, 0x6f, 0x65, 0x4c,
+	0x03, 0xda, 0x9f, 0xb3, 0xe0, 0x27, 0xf2, 0x49, 0xf4, 0x9c, 0x6b, 0xde, 0x41, 0xff, 0x46, 0x88,
+	0x34, 0xa1, 0x39, 0x52, 0xf9, 0x15, 0xc3, 0x25, 0x63, 0x0f, 0xe4, 0x0c, 0x3a, 0x45, 0x4d, 0xb8,
+	0x5f, 0x63, 0x8f, 0x86, 0x5d, 0x4b, 0xdd, 0xcf, 0xda, 0xb4, 0xf1, 0xdb, 0xc5, 0xa6, 0xa1, 0x0e,
+	0xad, 0x92, 0x67, 0x6b, 0xd5, 0x2a, 0x34, 0xdf, 0x42, 0x67, 0x83, 0x13, 0x55, 0x39, 0x8d, 0x84,
+	0x6a, 0xd2, 0xaa, 0xca, 0x2a, 0x34, 0xc7, 0x70, 0xbc, 0x2a, 0xcf, 0x64, 0x20, 0x4b, 0xb1, 0xf3,
+	0x9a, 0xf7, 0x70, 0x20, 0x54, 0x05, 0xeb, 0x96, 0xbd, 0xa1, 0xbe, 0x25, 0x5c, 0x31, 0xd0, 0x5f,
+	0x95, 0xcf, 0x5c, 0x38, 0xda, 0xca, 0x92, 0x2e, 0x74, 0xa6, 0xb7, 0x5f, 0xee, 0xc7, 0xb7, 0xf3,
+	0xa9, 0xa3, 0x37, 0x08, 0xc0, 0xe1, 0xdd, 0xdc, 0x9d, 0xbb, 0x8e, 0xae, 0x55, 0x25, 0xc7, 0x9d,
+	0x4c, 0x1d, 0xf7, 0x9b, 0x3a, 0x36, 0xc9, 0x31, 0xb4, 0x6f, 0x66, 0xb3, 0x89, 0x37, 0x55, 0xa7,
+	0xd6, 0xf0, 0xd7, 0x2b, 0x68, 0x8f, 0xd7, 0xf3, 0x20, 0x36, 0x74, 0x47, 0x1c, 0x2b, 0x11, 0xfc,
+	0x51, 0xa2, 0x90, 0x04, 0x6a, 0xf5, 0xda, 0x76, 0x43, 0xb7, 0xfe, 0x0c, 0xc4, 0x47, 0x51, 0x66,
+	0xd2, 0x6c, 0x54, 0x04, 0x07, 0x33, 0x7c, 0x39, 0xe1, 0x23, 0x74, 0x3d, 0x94, 0x8f, 0x96, 0x93,
+	0xa7, 0xc6, 0x1a, 0x83, 0x47, 0xce, 0x88, 0x51, 0x8a, 0x0b, 0x99, 0x32, 0x3a, 0xa1, 0x31, 0x53,
+	0xdc, 0x2b, 0xd0, 0x57, 0x62, 0x7f, 0xa7, 0xef, 0x93, 0xf4, 0xe0, 0x8d, 0x8f, 0x49, 0x2a, 0x24,
+	0xf2, 0xdd, 0x51, 0xbf, 0xae, 0xf9, 0x3b, 0xf9, 0xbd, 0x8d, 0x2e, 0xa1, 0xe7, 0x63, 0xcc, 0x51,
+	0x2c, 0x37, 0xaf, 0x7d, 0x81, 0xfa, 0x07, 0xe8, 0xcd, 0xa4, 0xf2, 0x34, 0x5f, 0x73, 0xc4, 0xbf,
+	0x2c, 0x3a, 0xd5, 0x2e, 0xb4, 0xca, 0xd7, 0x79, 0x11, 0x05, 0xff, 0xe3, 0x6b, 0x5f, 0xf9, 0xba,
+	0xbd, 0x58, 0xa8, 0x56, 0xeb, 0xc9, 0xf5, 0x84, 0xd1, 0x7f, 0xb6, 0x4b, 0xa5, 0x30, 0x1b, 0x17,
+	0xda, 0xa7, 0xeb, 0xef, 0x57, 0x49, 0x2a, 0x97, 0x65, 0x68, 0x2d, 0x58, 0x6e, 0x7b, 0x8c, 0x25,
+	0x19, 0x8e, 0x32, 0x56, 0x46, 0x0a, 0x29, 0x63, 0xc6, 0x73, 0x5b, 0x7d, 0x25, 0x7a, 0x9e, 0x07,
+	0x72, 0xb1, 0xb4, 0x53, 0xb5, 0x29, 0x9c, 0x06, 0x99, 0x5d, 0x84, 0xe1, 0x61, 0xfd, 0x31, 0x2f,
+	0x7f, 0x03, 0x3e, 0xb0, 0x73, 0xbe, 0xe3, 0x03, 0x00, 0x00,
 }
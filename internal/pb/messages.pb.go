@@ -6,12 +6,42 @@ package pb
 import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
+import google_protobuf "github.com/golang/protobuf/ptypes/any"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
+// Why an MMF found no match, so the backend can decide whether to relax the
+// profile's constraints or keep waiting for more players.
+type NoMatchReason int32
+
+const (
+	NoMatchReason_NO_MATCH_REASON_UNSPECIFIED NoMatchReason = 0
+	NoMatchReason_NOT_ENOUGH_PLAYERS          NoMatchReason = 1
+	NoMatchReason_NO_PLAYERS_MATCH_FILTERS    NoMatchReason = 2
+	NoMatchReason_CONSTRAINTS_TOO_STRICT      NoMatchReason = 3
+)
+
+var NoMatchReason_name = map[int32]string{
+	0: "NO_MATCH_REASON_UNSPECIFIED",
+	1: "NOT_ENOUGH_PLAYERS",
+	2: "NO_PLAYERS_MATCH_FILTERS",
+	3: "CONSTRAINTS_TOO_STRICT",
+}
+var NoMatchReason_value = map[string]int32{
+	"NO_MATCH_REASON_UNSPECIFIED": 0,
+	"NOT_ENOUGH_PLAYERS":          1,
+	"NO_PLAYERS_MATCH_FILTERS":    2,
+	"CONSTRAINTS_TOO_STRICT":      3,
+}
+
+func (x NoMatchReason) String() string {
+	return proto.EnumName(NoMatchReason_name, int32(x))
+}
+func (NoMatchReason) EnumDescriptor() ([]byte, []int) { return fileDescriptor3, []int{0} }
+
 // Open Match's internal representation and wire protocol format for "MatchObjects".
 // In order to request a match using the Backend API, your backend code should generate
 // a new MatchObject with an ID and properties filled in (for more details about valid
@@ -24,11 +54,19 @@ var _ = math.Inf
 // MatchObject as input only require a few of them to be filled in.  Check the
 // gRPC function in question for more details.
 type MatchObject struct {
-	Id         string        `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
-	Properties string        `protobuf:"bytes,2,opt,name=properties" json:"properties,omitempty"`
-	Error      string        `protobuf:"bytes,3,opt,name=error" json:"error,omitempty"`
-	Rosters    []*Roster     `protobuf:"bytes,4,rep,name=rosters" json:"rosters,omitempty"`
-	Pools      []*PlayerPool `protobuf:"bytes,5,rep,name=pools" json:"pools,omitempty"`
+	Id               string               `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Properties       string               `protobuf:"bytes,2,opt,name=properties" json:"properties,omitempty"`
+	Error            string               `protobuf:"bytes,3,opt,name=error" json:"error,omitempty"`
+	Rosters          []*Roster            `protobuf:"bytes,4,rep,name=rosters" json:"rosters,omitempty"`
+	Pools            []*PlayerPool        `protobuf:"bytes,5,rep,name=pools" json:"pools,omitempty"`
+	Mmf              string               `protobuf:"bytes,6,opt,name=mmf" json:"mmf,omitempty"`
+	QualityScore     float64              `protobuf:"fixed64,7,opt,name=quality_score,json=qualityScore" json:"quality_score,omitempty"`
+	RequestedPlayers int64                `protobuf:"varint,8,opt,name=requested_players,json=requestedPlayers" json:"requested_players,omitempty"`
+	NoMatchReason    NoMatchReason        `protobuf:"varint,9,opt,name=no_match_reason,json=noMatchReason,enum=messages.NoMatchReason" json:"no_match_reason,omitempty"`
+	ProfileId        string               `protobuf:"bytes,10,opt,name=profile_id,json=profileId" json:"profile_id,omitempty"`
+	DryRun           bool                 `protobuf:"varint,11,opt,name=dry_run,json=dryRun" json:"dry_run,omitempty"`
+	Priority         int64                `protobuf:"varint,12,opt,name=priority" json:"priority,omitempty"`
+	TypedProperties  *google_protobuf.Any `protobuf:"bytes,13,opt,name=typed_properties,json=typedProperties" json:"typed_properties,omitempty"`
 }
 
 func (m *MatchObject) Reset()                    { *m = MatchObject{} }
@@ -71,10 +109,67 @@ func (m *MatchObject) GetPools() []*PlayerPool {
 	return nil
 }
 
+func (m *MatchObject) GetMmf() string {
+	if m != nil {
+		return m.Mmf
+	}
+	return ""
+}
+
+func (m *MatchObject) GetQualityScore() float64 {
+	if m != nil {
+		return m.QualityScore
+	}
+	return 0
+}
+
+func (m *MatchObject) GetRequestedPlayers() int64 {
+	if m != nil {
+		return m.RequestedPlayers
+	}
+	return 0
+}
+
+func (m *MatchObject) GetNoMatchReason() NoMatchReason {
+	if m != nil {
+		return m.NoMatchReason
+	}
+	return NoMatchReason_NO_MATCH_REASON_UNSPECIFIED
+}
+
+func (m *MatchObject) GetProfileId() string {
+	if m != nil {
+		return m.ProfileId
+	}
+	return ""
+}
+
+func (m *MatchObject) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+func (m *MatchObject) GetPriority() int64 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
+func (m *MatchObject) GetTypedProperties() *google_protobuf.Any {
+	if m != nil {
+		return m.TypedProperties
+	}
+	return nil
+}
+
 // Data structure to hold a list of players in a match.
 type Roster struct {
-	Name    string    `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
-	Players []*Player `protobuf:"bytes,2,rep,name=players" json:"players,omitempty"`
+	Name       string    `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Players    []*Player `protobuf:"bytes,2,rep,name=players" json:"players,omitempty"`
+	TargetSize int64     `protobuf:"varint,3,opt,name=target_size,json=targetSize" json:"target_size,omitempty"`
 }
 
 func (m *Roster) Reset()                    { *m = Roster{} }
@@ -96,6 +191,13 @@ func (m *Roster) GetPlayers() []*Player {
 	return nil
 }
 
+func (m *Roster) GetTargetSize() int64 {
+	if m != nil {
+		return m.TargetSize
+	}
+	return 0
+}
+
 // A 'hard' filter to apply to the player pool.
 type Filter struct {
 	Name      string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
@@ -147,8 +249,10 @@ func (m *Filter) GetStats() *Stats {
 
 // Holds statistics
 type Stats struct {
-	Count   int64   `protobuf:"varint,1,opt,name=count" json:"count,omitempty"`
-	Elapsed float64 `protobuf:"fixed64,2,opt,name=elapsed" json:"elapsed,omitempty"`
+	Count             int64            `protobuf:"varint,1,opt,name=count" json:"count,omitempty"`
+	Elapsed           float64          `protobuf:"fixed64,2,opt,name=elapsed" json:"elapsed,omitempty"`
+	FilterCounts      map[string]int64 `protobuf:"bytes,3,rep,name=filter_counts,json=filterCounts" json:"filter_counts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	IntersectionCount int64            `protobuf:"varint,4,opt,name=intersection_count,json=intersectionCount" json:"intersection_count,omitempty"`
 }
 
 func (m *Stats) Reset()                    { *m = Stats{} }
@@ -170,6 +274,20 @@ func (m *Stats) GetElapsed() float64 {
 	return 0
 }
 
+func (m *Stats) GetFilterCounts() map[string]int64 {
+	if m != nil {
+		return m.FilterCounts
+	}
+	return nil
+}
+
+func (m *Stats) GetIntersectionCount() int64 {
+	if m != nil {
+		return m.IntersectionCount
+	}
+	return 0
+}
+
 // PlayerPools are defined by a set of 'hard' filters, and can be filled in
 // with the players that match those filters.
 //
@@ -177,10 +295,11 @@ func (m *Stats) GetElapsed() float64 {
 // PlayerPool as input only require a few of them to be filled in.  Check the
 // gRPC function in question for more details.
 type PlayerPool struct {
-	Name    string    `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
-	Filters []*Filter `protobuf:"bytes,2,rep,name=filters" json:"filters,omitempty"`
-	Roster  *Roster   `protobuf:"bytes,3,opt,name=roster" json:"roster,omitempty"`
-	Stats   *Stats    `protobuf:"bytes,4,opt,name=stats" json:"stats,omitempty"`
+	Name          string    `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Filters       []*Filter `protobuf:"bytes,2,rep,name=filters" json:"filters,omitempty"`
+	Roster        *Roster   `protobuf:"bytes,3,opt,name=roster" json:"roster,omitempty"`
+	Stats         *Stats    `protobuf:"bytes,4,opt,name=stats" json:"stats,omitempty"`
+	AffinityHints bool      `protobuf:"varint,5,opt,name=affinity_hints,json=affinityHints" json:"affinity_hints,omitempty"`
 }
 
 func (m *PlayerPool) Reset()                    { *m = PlayerPool{} }
@@ -216,12 +335,21 @@ func (m *PlayerPool) GetStats() *Stats {
 	return nil
 }
 
+func (m *PlayerPool) GetAffinityHints() bool {
+	if m != nil {
+		return m.AffinityHints
+	}
+	return false
+}
+
 // Data structure to hold details about a player
 type Player struct {
-	Id         string              `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
-	Properties string              `protobuf:"bytes,2,opt,name=properties" json:"properties,omitempty"`
-	Pool       string              `protobuf:"bytes,3,opt,name=pool" json:"pool,omitempty"`
-	Attributes []*Player_Attribute `protobuf:"bytes,4,rep,name=attributes" json:"attributes,omitempty"`
+	Id           string              `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Properties   string              `protobuf:"bytes,2,opt,name=properties" json:"properties,omitempty"`
+	Pool         string              `protobuf:"bytes,3,opt,name=pool" json:"pool,omitempty"`
+	Attributes   []*Player_Attribute `protobuf:"bytes,4,rep,name=attributes" json:"attributes,omitempty"`
+	Affinity     []string            `protobuf:"bytes,5,rep,name=affinity" json:"affinity,omitempty"`
+	AntiAffinity []string            `protobuf:"bytes,6,rep,name=anti_affinity,json=antiAffinity" json:"anti_affinity,omitempty"`
 }
 
 func (m *Player) Reset()                    { *m = Player{} }
@@ -257,6 +385,20 @@ func (m *Player) GetAttributes() []*Player_Attribute {
 	return nil
 }
 
+func (m *Player) GetAffinity() []string {
+	if m != nil {
+		return m.Affinity
+	}
+	return nil
+}
+
+func (m *Player) GetAntiAffinity() []string {
+	if m != nil {
+		return m.AntiAffinity
+	}
+	return nil
+}
+
 type Player_Attribute struct {
 	Name  string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
 	Value int64  `protobuf:"varint,2,opt,name=value" json:"value,omitempty"`
@@ -283,8 +425,13 @@ func (m *Player_Attribute) GetValue() int64 {
 
 // Simple message to return success/failure and error status.
 type Result struct {
-	Success bool   `protobuf:"varint,1,opt,name=success" json:"success,omitempty"`
-	Error   string `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+	Success      bool     `protobuf:"varint,1,opt,name=success" json:"success,omitempty"`
+	Error        string   `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+	TtlSeconds   int64    `protobuf:"varint,3,opt,name=ttl_seconds,json=ttlSeconds" json:"ttl_seconds,omitempty"`
+	ExpiresAt    int64    `protobuf:"varint,4,opt,name=expires_at,json=expiresAt" json:"expires_at,omitempty"`
+	Id           string   `protobuf:"bytes,5,opt,name=id" json:"id,omitempty"`
+	RemovedCount int64    `protobuf:"varint,6,opt,name=removed_count,json=removedCount" json:"removed_count,omitempty"`
+	Conflicts    []string `protobuf:"bytes,7,rep,name=conflicts" json:"conflicts,omitempty"`
 }
 
 func (m *Result) Reset()                    { *m = Result{} }
@@ -306,6 +453,41 @@ func (m *Result) GetError() string {
 	return ""
 }
 
+func (m *Result) GetTtlSeconds() int64 {
+	if m != nil {
+		return m.TtlSeconds
+	}
+	return 0
+}
+
+func (m *Result) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+func (m *Result) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Result) GetRemovedCount() int64 {
+	if m != nil {
+		return m.RemovedCount
+	}
+	return 0
+}
+
+func (m *Result) GetConflicts() []string {
+	if m != nil {
+		return m.Conflicts
+	}
+	return nil
+}
+
 // IlInput is an empty message reserved for future use.
 type IlInput struct {
 }
@@ -318,7 +500,11 @@ func (*IlInput) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{7}
 // Simple message used to pass the connection string for the DGS to the player.
 // DEPRECATED: Likely to be integrated into another protobuf message in a future version.
 type ConnectionInfo struct {
-	ConnectionString string `protobuf:"bytes,1,opt,name=connection_string,json=connectionString" json:"connection_string,omitempty"`
+	ConnectionString  string            `protobuf:"bytes,1,opt,name=connection_string,json=connectionString" json:"connection_string,omitempty"`
+	RetryAfterSeconds int64             `protobuf:"varint,2,opt,name=retry_after_seconds,json=retryAfterSeconds" json:"retry_after_seconds,omitempty"`
+	Metadata          map[string]string `protobuf:"bytes,3,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	MatchPending      bool              `protobuf:"varint,4,opt,name=match_pending,json=matchPending" json:"match_pending,omitempty"`
+	Fallback          bool              `protobuf:"varint,5,opt,name=fallback" json:"fallback,omitempty"`
 }
 
 func (m *ConnectionInfo) Reset()                    { *m = ConnectionInfo{} }
@@ -333,9 +519,41 @@ func (m *ConnectionInfo) GetConnectionString() string {
 	return ""
 }
 
+func (m *ConnectionInfo) GetRetryAfterSeconds() int64 {
+	if m != nil {
+		return m.RetryAfterSeconds
+	}
+	return 0
+}
+
+func (m *ConnectionInfo) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *ConnectionInfo) GetMatchPending() bool {
+	if m != nil {
+		return m.MatchPending
+	}
+	return false
+}
+
+func (m *ConnectionInfo) GetFallback() bool {
+	if m != nil {
+		return m.Fallback
+	}
+	return false
+}
+
 type Assignments struct {
 	Rosters        []*Roster       `protobuf:"bytes,1,rep,name=rosters" json:"rosters,omitempty"`
 	ConnectionInfo *ConnectionInfo `protobuf:"bytes,2,opt,name=connection_info,json=connectionInfo" json:"connection_info,omitempty"`
+	Nonce          string          `protobuf:"bytes,3,opt,name=nonce" json:"nonce,omitempty"`
+	Timestamp      int64           `protobuf:"varint,4,opt,name=timestamp" json:"timestamp,omitempty"`
+	OnlyUnassigned bool            `protobuf:"varint,5,opt,name=only_unassigned,json=onlyUnassigned" json:"only_unassigned,omitempty"`
+	SkipDeindex    bool            `protobuf:"varint,6,opt,name=skip_deindex,json=skipDeindex" json:"skip_deindex,omitempty"`
 }
 
 func (m *Assignments) Reset()                    { *m = Assignments{} }
@@ -357,6 +575,408 @@ func (m *Assignments) GetConnectionInfo() *ConnectionInfo {
 	return nil
 }
 
+func (m *Assignments) GetNonce() string {
+	if m != nil {
+		return m.Nonce
+	}
+	return ""
+}
+
+func (m *Assignments) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *Assignments) GetOnlyUnassigned() bool {
+	if m != nil {
+		return m.OnlyUnassigned
+	}
+	return false
+}
+
+func (m *Assignments) GetSkipDeindex() bool {
+	if m != nil {
+		return m.SkipDeindex
+	}
+	return false
+}
+
+// Request for a page of current player assignments.
+type ListAssignmentsRequest struct {
+	Limit  int64  `protobuf:"varint,1,opt,name=limit" json:"limit,omitempty"`
+	Cursor string `protobuf:"bytes,2,opt,name=cursor" json:"cursor,omitempty"`
+}
+
+func (m *ListAssignmentsRequest) Reset()                    { *m = ListAssignmentsRequest{} }
+func (m *ListAssignmentsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListAssignmentsRequest) ProtoMessage()               {}
+func (*ListAssignmentsRequest) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{10} }
+
+func (m *ListAssignmentsRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *ListAssignmentsRequest) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+// A player ID and the connection info it has been assigned.
+type PlayerAssignment struct {
+	PlayerId       string          `protobuf:"bytes,1,opt,name=player_id,json=playerId" json:"player_id,omitempty"`
+	ConnectionInfo *ConnectionInfo `protobuf:"bytes,2,opt,name=connection_info,json=connectionInfo" json:"connection_info,omitempty"`
+}
+
+func (m *PlayerAssignment) Reset()                    { *m = PlayerAssignment{} }
+func (m *PlayerAssignment) String() string            { return proto.CompactTextString(m) }
+func (*PlayerAssignment) ProtoMessage()               {}
+func (*PlayerAssignment) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{11} }
+
+func (m *PlayerAssignment) GetPlayerId() string {
+	if m != nil {
+		return m.PlayerId
+	}
+	return ""
+}
+
+func (m *PlayerAssignment) GetConnectionInfo() *ConnectionInfo {
+	if m != nil {
+		return m.ConnectionInfo
+	}
+	return nil
+}
+
+// A page of player assignments.
+type AssignmentList struct {
+	Assignments []*PlayerAssignment `protobuf:"bytes,1,rep,name=assignments" json:"assignments,omitempty"`
+	NextCursor  string              `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor" json:"next_cursor,omitempty"`
+}
+
+func (m *AssignmentList) Reset()                    { *m = AssignmentList{} }
+func (m *AssignmentList) String() string            { return proto.CompactTextString(m) }
+func (*AssignmentList) ProtoMessage()               {}
+func (*AssignmentList) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{12} }
+
+func (m *AssignmentList) GetAssignments() []*PlayerAssignment {
+	if m != nil {
+		return m.Assignments
+	}
+	return nil
+}
+
+func (m *AssignmentList) GetNextCursor() string {
+	if m != nil {
+		return m.NextCursor
+	}
+	return ""
+}
+
+// Request for the health of a service and its dependencies.
+type HealthRequest struct {
+}
+
+func (m *HealthRequest) Reset()                    { *m = HealthRequest{} }
+func (m *HealthRequest) String() string            { return proto.CompactTextString(m) }
+func (*HealthRequest) ProtoMessage()               {}
+func (*HealthRequest) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{13} }
+
+// The result of checking one dependency.
+type HealthCheck struct {
+	Name      string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Healthy   bool   `protobuf:"varint,2,opt,name=healthy" json:"healthy,omitempty"`
+	LatencyMs int64  `protobuf:"varint,3,opt,name=latency_ms,json=latencyMs" json:"latency_ms,omitempty"`
+	Error     string `protobuf:"bytes,4,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *HealthCheck) Reset()                    { *m = HealthCheck{} }
+func (m *HealthCheck) String() string            { return proto.CompactTextString(m) }
+func (*HealthCheck) ProtoMessage()               {}
+func (*HealthCheck) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{14} }
+
+func (m *HealthCheck) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *HealthCheck) GetHealthy() bool {
+	if m != nil {
+		return m.Healthy
+	}
+	return false
+}
+
+func (m *HealthCheck) GetLatencyMs() int64 {
+	if m != nil {
+		return m.LatencyMs
+	}
+	return 0
+}
+
+func (m *HealthCheck) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+// The health of a service, composed from the checks of its dependencies.
+type HealthStatus struct {
+	Healthy       bool           `protobuf:"varint,1,opt,name=healthy" json:"healthy,omitempty"`
+	Checks        []*HealthCheck `protobuf:"bytes,2,rep,name=checks" json:"checks,omitempty"`
+	PoolActive    int64          `protobuf:"varint,3,opt,name=pool_active,json=poolActive" json:"pool_active,omitempty"`
+	PoolIdle      int64          `protobuf:"varint,4,opt,name=pool_idle,json=poolIdle" json:"pool_idle,omitempty"`
+	PoolMaxActive int64          `protobuf:"varint,5,opt,name=pool_max_active,json=poolMaxActive" json:"pool_max_active,omitempty"`
+	Draining      bool           `protobuf:"varint,6,opt,name=draining" json:"draining,omitempty"`
+}
+
+func (m *HealthStatus) Reset()                    { *m = HealthStatus{} }
+func (m *HealthStatus) String() string            { return proto.CompactTextString(m) }
+func (*HealthStatus) ProtoMessage()               {}
+func (*HealthStatus) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{15} }
+
+func (m *HealthStatus) GetHealthy() bool {
+	if m != nil {
+		return m.Healthy
+	}
+	return false
+}
+
+func (m *HealthStatus) GetChecks() []*HealthCheck {
+	if m != nil {
+		return m.Checks
+	}
+	return nil
+}
+
+func (m *HealthStatus) GetPoolActive() int64 {
+	if m != nil {
+		return m.PoolActive
+	}
+	return 0
+}
+
+func (m *HealthStatus) GetPoolIdle() int64 {
+	if m != nil {
+		return m.PoolIdle
+	}
+	return 0
+}
+
+func (m *HealthStatus) GetPoolMaxActive() int64 {
+	if m != nil {
+		return m.PoolMaxActive
+	}
+	return 0
+}
+
+func (m *HealthStatus) GetDraining() bool {
+	if m != nil {
+		return m.Draining
+	}
+	return false
+}
+
+// A set of match profiles to run together.
+type ProfileList struct {
+	Profiles []*MatchObject `protobuf:"bytes,1,rep,name=profiles" json:"profiles,omitempty"`
+}
+
+func (m *ProfileList) Reset()                    { *m = ProfileList{} }
+func (m *ProfileList) String() string            { return proto.CompactTextString(m) }
+func (*ProfileList) ProtoMessage()               {}
+func (*ProfileList) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{16} }
+
+func (m *ProfileList) GetProfiles() []*MatchObject {
+	if m != nil {
+		return m.Profiles
+	}
+	return nil
+}
+
+// Everything state storage holds about one player, for debugging.
+type PlayerRecord struct {
+	Id          string            `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Fields      map[string]string `protobuf:"bytes,2,rep,name=fields" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	TtlSeconds  int64             `protobuf:"varint,3,opt,name=ttl_seconds,json=ttlSeconds" json:"ttl_seconds,omitempty"`
+	Indices     map[string]int64  `protobuf:"bytes,4,rep,name=indices" json:"indices,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Assignment  *ConnectionInfo   `protobuf:"bytes,5,opt,name=assignment" json:"assignment,omitempty"`
+	Ignorelists map[string]int64  `protobuf:"bytes,6,rep,name=ignorelists" json:"ignorelists,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+}
+
+func (m *PlayerRecord) Reset()                    { *m = PlayerRecord{} }
+func (m *PlayerRecord) String() string            { return proto.CompactTextString(m) }
+func (*PlayerRecord) ProtoMessage()               {}
+func (*PlayerRecord) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{17} }
+
+func (m *PlayerRecord) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *PlayerRecord) GetFields() map[string]string {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+func (m *PlayerRecord) GetTtlSeconds() int64 {
+	if m != nil {
+		return m.TtlSeconds
+	}
+	return 0
+}
+
+func (m *PlayerRecord) GetIndices() map[string]int64 {
+	if m != nil {
+		return m.Indices
+	}
+	return nil
+}
+
+func (m *PlayerRecord) GetAssignment() *ConnectionInfo {
+	if m != nil {
+		return m.Assignment
+	}
+	return nil
+}
+
+func (m *PlayerRecord) GetIgnorelists() map[string]int64 {
+	if m != nil {
+		return m.Ignorelists
+	}
+	return nil
+}
+
+// A set of match IDs to act on together.
+type MatchIdList struct {
+	Ids []string `protobuf:"bytes,1,rep,name=ids" json:"ids,omitempty"`
+}
+
+func (m *MatchIdList) Reset()                    { *m = MatchIdList{} }
+func (m *MatchIdList) String() string            { return proto.CompactTextString(m) }
+func (*MatchIdList) ProtoMessage()               {}
+func (*MatchIdList) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{18} }
+
+func (m *MatchIdList) GetIds() []string {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+// The results of a batch call, one per item in the same order, each with its
+// 'id' field populated.
+type ResultList struct {
+	Results []*Result `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
+}
+
+func (m *ResultList) Reset()                    { *m = ResultList{} }
+func (m *ResultList) String() string            { return proto.CompactTextString(m) }
+func (*ResultList) ProtoMessage()               {}
+func (*ResultList) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{19} }
+
+func (m *ResultList) GetResults() []*Result {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+// Request for the number of players in matchmaking.
+type ActivePlayerCountRequest struct {
+}
+
+func (m *ActivePlayerCountRequest) Reset()                    { *m = ActivePlayerCountRequest{} }
+func (m *ActivePlayerCountRequest) String() string            { return proto.CompactTextString(m) }
+func (*ActivePlayerCountRequest) ProtoMessage()               {}
+func (*ActivePlayerCountRequest) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{20} }
+
+// The number of players currently in matchmaking.
+type ActivePlayerCount struct {
+	Count int64 `protobuf:"varint,1,opt,name=count" json:"count,omitempty"`
+}
+
+func (m *ActivePlayerCount) Reset()                    { *m = ActivePlayerCount{} }
+func (m *ActivePlayerCount) String() string            { return proto.CompactTextString(m) }
+func (*ActivePlayerCount) ProtoMessage()               {}
+func (*ActivePlayerCount) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{21} }
+
+func (m *ActivePlayerCount) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+// A progress marker an MMF reports while forming a match.
+type MatchProgress struct {
+	Id        string       `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Stage     string       `protobuf:"bytes,2,opt,name=stage" json:"stage,omitempty"`
+	Message   string       `protobuf:"bytes,3,opt,name=message" json:"message,omitempty"`
+	Count     int64        `protobuf:"varint,4,opt,name=count" json:"count,omitempty"`
+	Timestamp int64        `protobuf:"varint,5,opt,name=timestamp" json:"timestamp,omitempty"`
+	Match     *MatchObject `protobuf:"bytes,6,opt,name=match" json:"match,omitempty"`
+}
+
+func (m *MatchProgress) Reset()                    { *m = MatchProgress{} }
+func (m *MatchProgress) String() string            { return proto.CompactTextString(m) }
+func (*MatchProgress) ProtoMessage()               {}
+func (*MatchProgress) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{22} }
+
+func (m *MatchProgress) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *MatchProgress) GetStage() string {
+	if m != nil {
+		return m.Stage
+	}
+	return ""
+}
+
+func (m *MatchProgress) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *MatchProgress) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *MatchProgress) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *MatchProgress) GetMatch() *MatchObject {
+	if m != nil {
+		return m.Match
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*MatchObject)(nil), "messages.MatchObject")
 	proto.RegisterType((*Roster)(nil), "messages.Roster")
@@ -369,45 +989,129 @@ func init() {
 	proto.RegisterType((*IlInput)(nil), "messages.IlInput")
 	proto.RegisterType((*ConnectionInfo)(nil), "messages.ConnectionInfo")
 	proto.RegisterType((*Assignments)(nil), "messages.Assignments")
+	proto.RegisterType((*ListAssignmentsRequest)(nil), "messages.ListAssignmentsRequest")
+	proto.RegisterType((*PlayerAssignment)(nil), "messages.PlayerAssignment")
+	proto.RegisterType((*AssignmentList)(nil), "messages.AssignmentList")
+	proto.RegisterType((*HealthRequest)(nil), "messages.HealthRequest")
+	proto.RegisterType((*HealthCheck)(nil), "messages.HealthCheck")
+	proto.RegisterType((*HealthStatus)(nil), "messages.HealthStatus")
+	proto.RegisterType((*ProfileList)(nil), "messages.ProfileList")
+	proto.RegisterType((*PlayerRecord)(nil), "messages.PlayerRecord")
+	proto.RegisterType((*MatchIdList)(nil), "messages.MatchIdList")
+	proto.RegisterType((*ResultList)(nil), "messages.ResultList")
+	proto.RegisterType((*ActivePlayerCountRequest)(nil), "messages.ActivePlayerCountRequest")
+	proto.RegisterType((*ActivePlayerCount)(nil), "messages.ActivePlayerCount")
+	proto.RegisterType((*MatchProgress)(nil), "messages.MatchProgress")
+	proto.RegisterEnum("messages.NoMatchReason", NoMatchReason_name, NoMatchReason_value)
 }
 
 func init() { proto.RegisterFile("api/protobuf-spec/messages.proto", fileDescriptor3) }
 
 var fileDescriptor3 = []byte{
-	// 556 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xd1, 0x6a, 0xdb, 0x30,
-	0x14, 0xc5, 0x49, 0xec, 0x36, 0x37, 0xd0, 0x76, 0x22, 0x0f, 0xa6, 0x8c, 0x11, 0x0c, 0x83, 0xd0,
-	0xd1, 0x18, 0x32, 0x4a, 0xc7, 0x60, 0x0f, 0x59, 0x61, 0x5b, 0x1e, 0xc6, 0x82, 0xfa, 0xb6, 0x97,
-	0x21, 0x3b, 0x4a, 0xaa, 0x21, 0x4b, 0x42, 0x92, 0xc3, 0x06, 0xfb, 0x81, 0x7d, 0xc4, 0xbe, 0x60,
-	0x1f, 0xb1, 0x5f, 0x1b, 0x96, 0xec, 0xd8, 0xed, 0xda, 0x8d, 0xbd, 0xe9, 0x9e, 0x7b, 0xaf, 0x75,
-	0xce, 0x3d, 0xba, 0x86, 0x09, 0x51, 0x2c, 0x55, 0x5a, 0x5a, 0x99, 0x95, 0x9b, 0x73, 0xa3, 0x68,
-	0x9e, 0x16, 0xd4, 0x18, 0xb2, 0xa5, 0x66, 0xe6, 0x60, 0x74, 0xd8, 0xc4, 0xc9, 0xcf, 0x00, 0x46,
-	0xef, 0x89, 0xcd, 0x6f, 0x3e, 0x64, 0x9f, 0x69, 0x6e, 0xd1, 0x11, 0xf4, 0xd8, 0x3a, 0x0e, 0x26,
-	0xc1, 0x74, 0x88, 0x7b, 0x6c, 0x8d, 0x9e, 0x00, 0x28, 0x2d, 0x15, 0xd5, 0x96, 0x51, 0x13, 0xf7,
-	0x1c, 0xde, 0x41, 0xd0, 0x18, 0x42, 0xaa, 0xb5, 0xd4, 0x71, 0xdf, 0xa5, 0x7c, 0x80, 0xce, 0xe0,
-	0x40, 0x4b, 0x63, 0xa9, 0x36, 0xf1, 0x60, 0xd2, 0x9f, 0x8e, 0xe6, 0x27, 0xb3, 0x3d, 0x03, 0xec,
-	0x12, 0xb8, 0x29, 0x40, 0x67, 0x10, 0x2a, 0x29, 0xb9, 0x89, 0x43, 0x57, 0x39, 0x6e, 0x2b, 0x57,
-	0x9c, 0x7c, 0xa5, 0x7a, 0x25, 0x25, 0xc7, 0xbe, 0x24, 0x79, 0x07, 0x91, 0x6f, 0x47, 0x08, 0x06,
-	0x82, 0x14, 0xb4, 0x66, 0xea, 0xce, 0xd5, 0xad, 0xca, 0xb5, 0x54, 0x44, 0xef, 0xdc, 0xea, 0xbf,
-	0x85, 0x9b, 0x82, 0xe4, 0x7b, 0x00, 0xd1, 0x1b, 0xc6, 0x1f, 0xfa, 0xd4, 0x63, 0x18, 0x12, 0x6b,
-	0x35, 0xcb, 0x4a, 0x4b, 0x6b, 0xd5, 0x2d, 0x50, 0x75, 0x14, 0xe4, 0xcb, 0xce, 0x69, 0xee, 0x63,
-	0x77, 0x76, 0x18, 0x13, 0xbb, 0x78, 0x50, 0x63, 0x4c, 0xec, 0xd0, 0x53, 0x08, 0x8d, 0x25, 0xb6,
-	0x92, 0x16, 0x4c, 0x47, 0xf3, 0xe3, 0x96, 0xce, 0x75, 0x05, 0x63, 0x9f, 0x4d, 0x2e, 0x21, 0x74,
-	0x71, 0x35, 0xcc, 0x5c, 0x96, 0xc2, 0x3a, 0x2a, 0x7d, 0xec, 0x03, 0x14, 0xc3, 0x01, 0xe5, 0x44,
-	0x19, 0xba, 0x76, 0x4c, 0x02, 0xdc, 0x84, 0xc9, 0x8f, 0x00, 0xa0, 0x1d, 0xd2, 0x43, 0x33, 0xd9,
-	0x38, 0x99, 0xf7, 0xcc, 0xc4, 0xeb, 0xc7, 0x4d, 0x01, 0x9a, 0x42, 0xe4, 0x4d, 0x71, 0xc2, 0xee,
-	0x33, 0xad, 0xce, 0xb7, 0xc2, 0x06, 0x7f, 0x15, 0xf6, 0x2b, 0x80, 0xc8, 0xf3, 0xfb, 0xef, 0x77,
-	0x85, 0x60, 0x50, 0x59, 0x5e, 0x3f, 0x2b, 0x77, 0x46, 0x2f, 0x01, 0xf6, 0x1e, 0x34, 0x0f, 0xeb,
-	0xf4, 0xae, 0xc5, 0xb3, 0x45, 0x53, 0x82, 0x3b, 0xd5, 0xa7, 0x17, 0x30, 0x5c, 0x74, 0xfd, 0xfb,
-	0x63, 0x50, 0x63, 0x08, 0x77, 0x84, 0x97, 0xde, 0xed, 0x3e, 0xf6, 0x41, 0xf2, 0x02, 0x22, 0x4c,
-	0x4d, 0xc9, 0x9d, 0x0b, 0xa6, 0xcc, 0x73, 0x6a, 0x8c, 0x6b, 0x3b, 0xc4, 0x4d, 0xd8, 0xae, 0x40,
-	0xaf, 0xb3, 0x02, 0xc9, 0x10, 0x0e, 0x96, 0x7c, 0x29, 0x54, 0x69, 0x93, 0x57, 0x70, 0x74, 0x25,
-	0x85, 0xa0, 0xb9, 0x65, 0x52, 0x2c, 0xc5, 0x46, 0xa2, 0x67, 0xf0, 0x28, 0xdf, 0x23, 0x9f, 0x8c,
-	0xd5, 0x4c, 0x6c, 0x6b, 0x36, 0x27, 0x6d, 0xe2, 0xda, 0xe1, 0xc9, 0x37, 0x18, 0x2d, 0x8c, 0x61,
-	0x5b, 0x51, 0x50, 0x61, 0x4d, 0x77, 0xb7, 0x82, 0x7f, 0xed, 0xd6, 0x02, 0x8e, 0x3b, 0xf7, 0x30,
-	0xb1, 0x91, 0x8e, 0xe4, 0x68, 0x1e, 0xb7, 0x3d, 0xb7, 0xa9, 0xe1, 0xa3, 0xfc, 0x56, 0xfc, 0xfa,
-	0xf2, 0xe3, 0xc5, 0x96, 0xd9, 0x9b, 0x32, 0x9b, 0xe5, 0xb2, 0x48, 0xdf, 0x4a, 0xb9, 0xe5, 0xf4,
-	0x8a, 0xcb, 0x72, 0xbd, 0xe2, 0xc4, 0x6e, 0xa4, 0x2e, 0x52, 0xa9, 0xa8, 0x38, 0x2f, 0xaa, 0x7f,
-	0x48, 0xca, 0x84, 0xa5, 0x5a, 0x10, 0x9e, 0xaa, 0x2c, 0x8b, 0xdc, 0xaf, 0xe6, 0xf9, 0xef, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0x29, 0x1e, 0x07, 0x0d, 0x8e, 0x04, 0x00, 0x00,
+	// 1670 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa5, 0x57, 0xcb, 0x72, 0x1b, 0x45,
+	0x14, 0x45, 0x92, 0x25, 0x4b, 0x2d, 0xc9, 0x96, 0x9b, 0x90, 0x08, 0x05, 0x48, 0x32, 0x29, 0x92,
+	0x90, 0x94, 0xe5, 0xc2, 0x54, 0x8a, 0x10, 0x1e, 0x41, 0x28, 0x76, 0xac, 0xaa, 0x58, 0x72, 0xb5,
+	0x9c, 0x05, 0x6c, 0xa6, 0xc6, 0x33, 0x2d, 0x79, 0xf0, 0xa8, 0x67, 0x32, 0x33, 0x72, 0x59, 0xac,
+	0x28, 0x76, 0x7c, 0x0d, 0x7c, 0x04, 0x3b, 0xbe, 0x80, 0x0f, 0x60, 0xcd, 0x8e, 0x15, 0x0b, 0x6e,
+	0xdf, 0xee, 0x79, 0xf8, 0x45, 0xca, 0xc5, 0x6e, 0xee, 0xe9, 0xd3, 0xdd, 0xf7, 0x7d, 0x7b, 0xc8,
+	0x6d, 0x2b, 0x70, 0x37, 0x82, 0xd0, 0x8f, 0xfd, 0x83, 0xf9, 0x64, 0x3d, 0x0a, 0xb8, 0xbd, 0x31,
+	0xe3, 0x51, 0x64, 0x4d, 0x79, 0xd4, 0x45, 0x98, 0x56, 0x13, 0xb9, 0xf3, 0xee, 0xd4, 0xf7, 0xa7,
+	0x1e, 0x4f, 0xe9, 0x1b, 0x96, 0x58, 0x28, 0x92, 0xf1, 0x67, 0x89, 0xd4, 0x77, 0xad, 0xd8, 0x3e,
+	0x1c, 0x1d, 0x7c, 0xcf, 0xed, 0x98, 0xae, 0x90, 0xa2, 0xeb, 0xb4, 0x0b, 0xb7, 0x0b, 0x0f, 0x6a,
+	0x0c, 0xbe, 0xe8, 0x07, 0x84, 0x00, 0x31, 0xe0, 0x61, 0xec, 0xf2, 0xa8, 0x5d, 0x44, 0x3c, 0x87,
+	0xd0, 0x6b, 0xa4, 0xcc, 0xc3, 0xd0, 0x0f, 0xdb, 0x25, 0x5c, 0x52, 0x02, 0x7d, 0x48, 0x96, 0x43,
+	0x3f, 0x8a, 0x79, 0x18, 0xb5, 0x97, 0x6e, 0x97, 0x1e, 0xd4, 0x37, 0x5b, 0xdd, 0x54, 0x39, 0x86,
+	0x0b, 0x2c, 0x21, 0x00, 0xb7, 0x1c, 0xf8, 0xbe, 0x17, 0xb5, 0xcb, 0xc8, 0xbc, 0x96, 0x31, 0xf7,
+	0x3c, 0x6b, 0xc1, 0xc3, 0x3d, 0x58, 0x64, 0x8a, 0x42, 0x5b, 0xa4, 0x34, 0x9b, 0x4d, 0xda, 0x15,
+	0xbc, 0x4b, 0x7e, 0xd2, 0xbb, 0xa4, 0xf9, 0x7a, 0x6e, 0x79, 0x6e, 0xbc, 0x30, 0x23, 0xdb, 0x0f,
+	0x79, 0x7b, 0x19, 0xd6, 0x0a, 0xac, 0xa1, 0xc1, 0xb1, 0xc4, 0xe8, 0x23, 0xb2, 0x16, 0xf2, 0xd7,
+	0x73, 0x0e, 0x17, 0x3a, 0x66, 0x80, 0xa7, 0x46, 0xed, 0x2a, 0x10, 0x4b, 0xac, 0x95, 0x2e, 0xa8,
+	0xdb, 0x22, 0xfa, 0x8c, 0xac, 0x0a, 0xdf, 0x9c, 0x49, 0x9f, 0x98, 0x21, 0xb7, 0x22, 0x5f, 0xb4,
+	0x6b, 0x40, 0x5d, 0xd9, 0xbc, 0x91, 0x69, 0x36, 0xf4, 0xd1, 0x67, 0x0c, 0x97, 0x59, 0x53, 0xe4,
+	0x45, 0xfa, 0x3e, 0xba, 0x6c, 0xe2, 0x7a, 0xdc, 0x04, 0x57, 0x12, 0xd4, 0xb5, 0xa6, 0x91, 0x81,
+	0x43, 0x6f, 0x90, 0x65, 0x27, 0x5c, 0x98, 0xe1, 0x5c, 0xb4, 0xeb, 0xb0, 0x56, 0x65, 0x15, 0x10,
+	0xd9, 0x5c, 0xd0, 0x0e, 0xa9, 0x06, 0xa1, 0xeb, 0x87, 0xa0, 0x76, 0xbb, 0x81, 0xca, 0xa5, 0x32,
+	0x28, 0xd5, 0x8a, 0x17, 0x81, 0xd4, 0x3e, 0x0b, 0x46, 0x13, 0x38, 0xd2, 0x5f, 0x2a, 0xb8, 0xdd,
+	0x24, 0xb8, 0xdd, 0x9e, 0x58, 0xb0, 0x55, 0x64, 0xef, 0xa5, 0x64, 0xc3, 0x25, 0x15, 0xe5, 0x78,
+	0x4a, 0xc9, 0x92, 0xb0, 0x66, 0x5c, 0xc7, 0x18, 0xbf, 0x65, 0xbc, 0x12, 0xb7, 0x14, 0xcf, 0xc6,
+	0x4b, 0xf9, 0x85, 0x25, 0x04, 0x7a, 0x8b, 0xd4, 0x63, 0x2b, 0x9c, 0xf2, 0xd8, 0x8c, 0xdc, 0x1f,
+	0x38, 0xc6, 0xbd, 0xc4, 0x88, 0x82, 0xc6, 0x80, 0x18, 0x3f, 0x17, 0x48, 0x65, 0xdb, 0xf5, 0x2e,
+	0xbb, 0xeb, 0x3d, 0x52, 0xb3, 0xe2, 0x38, 0x74, 0x0f, 0xe6, 0x31, 0xd7, 0x09, 0x95, 0x01, 0x72,
+	0xc7, 0xcc, 0x3a, 0x39, 0xd6, 0xc7, 0xe2, 0x37, 0x62, 0xae, 0x38, 0x86, 0x54, 0x52, 0x18, 0x7c,
+	0xd3, 0x0f, 0x49, 0x39, 0x8a, 0xad, 0x58, 0x66, 0x8d, 0xf4, 0xc2, 0x6a, 0xa6, 0xef, 0x58, 0xc2,
+	0x4c, 0xad, 0x1a, 0x7f, 0x15, 0x48, 0x19, 0x01, 0x99, 0xa8, 0xb6, 0x3f, 0x17, 0x31, 0xea, 0x52,
+	0x62, 0x4a, 0xa0, 0x6d, 0xb2, 0xcc, 0x3d, 0x2b, 0x88, 0xb8, 0x83, 0xaa, 0x14, 0x58, 0x22, 0xd2,
+	0x6d, 0xd2, 0x9c, 0xa0, 0x11, 0x26, 0x32, 0x23, 0xd0, 0x48, 0x3a, 0xe6, 0xce, 0x99, 0x8b, 0xba,
+	0xca, 0xd2, 0x3e, 0x72, 0xb6, 0x44, 0x0c, 0xa1, 0x6c, 0x4c, 0x72, 0x10, 0x5d, 0x27, 0xd4, 0x15,
+	0x32, 0xd1, 0xa1, 0xba, 0x5c, 0x5f, 0xa8, 0xd3, 0xb4, 0x29, 0x6b, 0xf9, 0x15, 0xe4, 0x77, 0x9e,
+	0x91, 0xb5, 0x73, 0x27, 0xca, 0xb4, 0x3f, 0xe2, 0x0b, 0xed, 0x45, 0xf9, 0x29, 0xad, 0x39, 0xb6,
+	0xbc, 0xb9, 0x72, 0x20, 0x58, 0x83, 0xc2, 0xd3, 0xe2, 0x93, 0x82, 0xf1, 0x5b, 0x81, 0x90, 0xac,
+	0x70, 0x2e, 0x8b, 0xb6, 0x52, 0xf1, 0x82, 0x68, 0xab, 0xcb, 0x59, 0x42, 0xa0, 0x0f, 0x48, 0x45,
+	0x15, 0x2a, 0x46, 0xe4, 0xa2, 0x42, 0xd6, 0xeb, 0x59, 0x44, 0x96, 0xfe, 0x2b, 0x22, 0x40, 0x5b,
+	0xb1, 0x26, 0x13, 0x57, 0xc8, 0x8a, 0x3d, 0x74, 0x85, 0x8e, 0x60, 0x95, 0x35, 0x13, 0x74, 0x47,
+	0x82, 0xc6, 0x8f, 0x45, 0x52, 0x51, 0x66, 0x5c, 0xb9, 0x25, 0x81, 0xc9, 0xb2, 0x5b, 0xe8, 0x8e,
+	0x84, 0xdf, 0xf4, 0x29, 0x21, 0x69, 0x8e, 0x25, 0x3d, 0xa9, 0x73, 0x36, 0xc7, 0xbb, 0xbd, 0x84,
+	0xc2, 0x72, 0x6c, 0x59, 0x97, 0x89, 0x6e, 0xd8, 0xa3, 0x6a, 0x2c, 0x95, 0x65, 0xfb, 0xb1, 0x44,
+	0xec, 0x9a, 0x29, 0xa1, 0x82, 0x84, 0x86, 0x04, 0x7b, 0x1a, 0xeb, 0x3c, 0x26, 0xb5, 0x5e, 0x3e,
+	0xc1, 0xcf, 0x05, 0xe4, 0xc2, 0x68, 0x1a, 0xbf, 0x43, 0x1d, 0x31, 0x1e, 0xcd, 0x3d, 0x4c, 0xd3,
+	0x68, 0x6e, 0xdb, 0xa0, 0x2e, 0xee, 0xab, 0xb2, 0x44, 0xcc, 0xfa, 0x6f, 0x31, 0xdf, 0x7f, 0x65,
+	0x8d, 0xc6, 0x9e, 0x09, 0x99, 0xe5, 0x0b, 0x27, 0x4a, 0x6b, 0x34, 0xf6, 0xc6, 0x0a, 0x91, 0x3d,
+	0x8a, 0x9f, 0x04, 0x6e, 0xc8, 0x23, 0xd3, 0x4a, 0xb2, 0xb1, 0xa6, 0x91, 0x5e, 0x32, 0x05, 0xca,
+	0xa9, 0xcb, 0xc1, 0xcc, 0x90, 0xcf, 0xfc, 0x63, 0x68, 0x40, 0x2a, 0x7f, 0x2b, 0xb8, 0xa3, 0xa1,
+	0x41, 0xcc, 0x55, 0x59, 0xd8, 0x70, 0xf8, 0xc4, 0x73, 0x6d, 0x08, 0xea, 0x32, 0xfa, 0x21, 0x03,
+	0x8c, 0x1a, 0x59, 0x1e, 0x78, 0x03, 0x11, 0xcc, 0x63, 0xe3, 0x97, 0x22, 0x59, 0xe9, 0xfb, 0x42,
+	0xa8, 0xbc, 0x1f, 0x88, 0x89, 0x2f, 0x3b, 0xb4, 0x9d, 0x22, 0x66, 0x04, 0xce, 0x12, 0x53, 0xed,
+	0xa2, 0x56, 0xb6, 0x30, 0x46, 0x9c, 0x76, 0xc9, 0xdb, 0x21, 0x87, 0xba, 0x00, 0xaf, 0xcb, 0xfa,
+	0x4c, 0xac, 0x54, 0xce, 0x5b, 0xc3, 0xa5, 0x9e, 0x5c, 0x49, 0x8c, 0xfd, 0x86, 0xc0, 0x28, 0x8c,
+	0x2d, 0xc7, 0x8a, 0x2d, 0x5d, 0xc5, 0xf7, 0xb2, 0xd0, 0x9f, 0x56, 0xa4, 0xbb, 0xab, 0x89, 0xaa,
+	0x94, 0xd3, 0x7d, 0xd2, 0x03, 0x6a, 0x24, 0x04, 0x5c, 0x38, 0x52, 0xb9, 0x25, 0x8c, 0x43, 0x03,
+	0xc1, 0x3d, 0x85, 0xc9, 0x4c, 0x99, 0x58, 0x9e, 0x77, 0x60, 0xd9, 0x47, 0x3a, 0xab, 0x53, 0xb9,
+	0xf3, 0x39, 0x69, 0x9e, 0x3a, 0xfb, 0x4d, 0x45, 0x5d, 0xcb, 0x17, 0xf5, 0x3f, 0x05, 0x52, 0xef,
+	0x45, 0x91, 0x3b, 0x15, 0x33, 0x2e, 0x9b, 0x4a, 0x6e, 0xbe, 0x16, 0xde, 0x34, 0x5f, 0x7b, 0x64,
+	0x35, 0xe7, 0x5a, 0x17, 0x8c, 0xc4, 0xf3, 0xeb, 0x9b, 0xed, 0xcb, 0x9c, 0xc0, 0x56, 0xec, 0xd3,
+	0xd1, 0x01, 0xc5, 0x84, 0x2f, 0x6c, 0x9e, 0x0c, 0x79, 0x14, 0x64, 0xbc, 0x63, 0x17, 0x8e, 0x88,
+	0xad, 0x59, 0x90, 0xa4, 0x50, 0x0a, 0xd0, 0xfb, 0x64, 0xd5, 0x17, 0xde, 0xc2, 0x9c, 0x0b, 0x0b,
+	0x15, 0xe7, 0x8e, 0x76, 0xc9, 0x8a, 0x84, 0x5f, 0xa5, 0x28, 0xbd, 0x43, 0x1a, 0xd1, 0x91, 0x1b,
+	0x98, 0x0e, 0x77, 0x85, 0xc3, 0x4f, 0x30, 0xb5, 0xaa, 0xac, 0x2e, 0xb1, 0xe7, 0x0a, 0x32, 0xb6,
+	0xc9, 0xf5, 0x97, 0x6e, 0x14, 0xe7, 0x3c, 0xc0, 0xd4, 0xd4, 0x96, 0x9a, 0x79, 0xee, 0xcc, 0x4d,
+	0xbb, 0x3a, 0x0a, 0xf4, 0x3a, 0xa9, 0xd8, 0xf3, 0x30, 0x4a, 0xab, 0x42, 0x4b, 0x46, 0x48, 0x5a,
+	0xaa, 0xd2, 0xb3, 0x93, 0xe8, 0x4d, 0x52, 0x53, 0x93, 0xcd, 0x4c, 0x9b, 0x4c, 0x55, 0x01, 0x30,
+	0xab, 0xff, 0xbf, 0xef, 0x0c, 0x9f, 0xac, 0x64, 0xb7, 0x49, 0x2b, 0xe8, 0x17, 0xa4, 0x6e, 0x65,
+	0x96, 0xe8, 0x00, 0x9e, 0x6b, 0x46, 0xd9, 0x26, 0x96, 0xa7, 0xcb, 0xd2, 0x16, 0xfc, 0x24, 0x36,
+	0x4f, 0x19, 0x48, 0x24, 0xd4, 0x57, 0x46, 0xae, 0x92, 0xe6, 0x0e, 0xb7, 0xbc, 0xf8, 0x50, 0xfb,
+	0xc8, 0x08, 0x48, 0x5d, 0x01, 0xfd, 0x43, 0x6e, 0x1f, 0x5d, 0xd8, 0x80, 0xa0, 0xbf, 0x1c, 0x22,
+	0x65, 0x81, 0x07, 0x42, 0x7f, 0xd1, 0xa2, 0x6c, 0x14, 0x9e, 0x15, 0x73, 0x61, 0x2f, 0xcc, 0x59,
+	0xd2, 0x48, 0x6a, 0x1a, 0xd9, 0xcd, 0xb5, 0x9f, 0xa5, 0x5c, 0xfb, 0x31, 0xfe, 0x28, 0x90, 0x86,
+	0xba, 0x52, 0xb6, 0xfe, 0x79, 0x94, 0x3f, 0xbf, 0x70, 0xfa, 0xfc, 0x75, 0x08, 0x95, 0x54, 0x2b,
+	0x19, 0x45, 0xef, 0x64, 0x7e, 0xc8, 0x29, 0xcd, 0x34, 0x49, 0x5a, 0x2f, 0xfb, 0xb9, 0x69, 0x81,
+	0x83, 0x8f, 0xd3, 0xc7, 0x87, 0x84, 0x7a, 0x88, 0x60, 0x38, 0x25, 0xc1, 0x75, 0x3c, 0xae, 0x93,
+	0xb2, 0x2a, 0x81, 0x01, 0xc8, 0xf4, 0x1e, 0x59, 0xc5, 0x45, 0x78, 0x55, 0x24, 0x27, 0x94, 0x91,
+	0xd2, 0x94, 0xf0, 0xae, 0x75, 0xa2, 0x0f, 0x81, 0x3a, 0x76, 0x42, 0x0b, 0x9a, 0x37, 0xd4, 0xb9,
+	0x4a, 0xc7, 0x54, 0x36, 0xbe, 0x26, 0xf5, 0x3d, 0xf5, 0x96, 0xc3, 0x60, 0x7e, 0x2c, 0x1f, 0x6d,
+	0x28, 0x26, 0x91, 0xcc, 0x59, 0x90, 0x7b, 0x58, 0xb3, 0x94, 0x66, 0xfc, 0x5d, 0x22, 0x0d, 0xfd,
+	0xa8, 0x82, 0x06, 0x15, 0x3a, 0xe7, 0x06, 0xdc, 0x53, 0x52, 0x99, 0xb8, 0xdc, 0x73, 0x12, 0x9f,
+	0x18, 0xe7, 0x1e, 0x63, 0xb8, 0x0f, 0x66, 0xb5, 0x24, 0xa9, 0x4e, 0xa5, 0x77, 0xbc, 0xb9, 0xf3,
+	0x7f, 0x49, 0x96, 0xa1, 0xa8, 0x5c, 0x3b, 0x1d, 0x83, 0x77, 0x2f, 0x39, 0x7d, 0xa0, 0x58, 0xea,
+	0xf8, 0x64, 0x0f, 0x7d, 0x02, 0x83, 0x34, 0xcd, 0x46, 0xfd, 0xf8, 0xba, 0xbc, 0x18, 0x72, 0x5c,
+	0x3a, 0x20, 0x75, 0xf8, 0x84, 0xe7, 0xb8, 0x07, 0x7e, 0x8b, 0x70, 0x50, 0xd6, 0x37, 0xef, 0x5f,
+	0x76, 0x79, 0xc6, 0x54, 0x0a, 0xe4, 0xf7, 0x76, 0x3e, 0x23, 0xf5, 0x9c, 0xed, 0x57, 0xe9, 0xa4,
+	0x9d, 0xa7, 0xa4, 0x91, 0x37, 0xec, 0x2a, 0x4f, 0xab, 0xce, 0x57, 0xa4, 0x75, 0x56, 0xaf, 0x2b,
+	0x3d, 0xcd, 0x6e, 0xe9, 0x5f, 0xad, 0x81, 0x83, 0xa9, 0x03, 0x5b, 0x5d, 0x47, 0x65, 0x0d, 0x6c,
+	0x85, 0x4f, 0x03, 0x9c, 0xab, 0x06, 0x3e, 0xae, 0xcb, 0x26, 0x8f, 0xd2, 0x45, 0x4d, 0x1e, 0x17,
+	0x58, 0x42, 0x30, 0x3a, 0xa4, 0xad, 0x72, 0x57, 0x79, 0x11, 0x07, 0x72, 0x52, 0xff, 0x1f, 0x91,
+	0xb5, 0x73, 0x6b, 0x17, 0x3f, 0x87, 0x8d, 0x5f, 0x0b, 0x30, 0xa5, 0x70, 0xa2, 0x85, 0xfe, 0x34,
+	0x94, 0xef, 0x8b, 0xb3, 0xb9, 0x79, 0x0d, 0x5f, 0x79, 0xd3, 0xd4, 0xb3, 0x28, 0xc8, 0xfa, 0xd6,
+	0xaa, 0xe9, 0x11, 0x91, 0x88, 0xd9, 0x3d, 0x4b, 0xf9, 0x67, 0xf7, 0xa9, 0xd1, 0x51, 0x3e, 0x3b,
+	0x3a, 0x1e, 0x91, 0x32, 0x8e, 0x55, 0xac, 0xbd, 0x4b, 0x0b, 0x4a, 0x71, 0x1e, 0xfe, 0x04, 0x2a,
+	0x9f, 0xfa, 0x1d, 0x83, 0x12, 0xb8, 0x39, 0x1c, 0x99, 0xbb, 0xbd, 0xfd, 0xfe, 0x8e, 0xc9, 0xb6,
+	0x7a, 0xe3, 0xd1, 0xd0, 0x7c, 0x35, 0x1c, 0xef, 0x6d, 0xf5, 0x07, 0xdb, 0x83, 0xad, 0xe7, 0xad,
+	0xb7, 0x60, 0x3c, 0xd0, 0xe1, 0x68, 0xdf, 0xdc, 0x1a, 0x8e, 0x5e, 0xbd, 0xd8, 0x31, 0xf7, 0x5e,
+	0xf6, 0xbe, 0xdd, 0x62, 0xe3, 0x56, 0x01, 0xb4, 0x6a, 0xc3, 0x46, 0x2d, 0xeb, 0x03, 0xb6, 0x07,
+	0x2f, 0xf7, 0xe5, 0x6a, 0x11, 0x9a, 0xc2, 0xf5, 0xfe, 0x68, 0x38, 0xde, 0x67, 0xbd, 0xc1, 0x70,
+	0x7f, 0x6c, 0xee, 0x8f, 0x46, 0x26, 0x08, 0x83, 0xfe, 0x7e, 0xab, 0xf4, 0xcd, 0xa7, 0xdf, 0x3d,
+	0x9e, 0xba, 0xf1, 0xe1, 0xfc, 0xa0, 0x6b, 0xfb, 0xb3, 0x8d, 0x17, 0xf8, 0x43, 0xd6, 0xf7, 0xfc,
+	0xb9, 0xfc, 0xa9, 0x8c, 0x27, 0x7e, 0x38, 0xdb, 0x80, 0xd7, 0xa9, 0x58, 0x47, 0x8d, 0x37, 0xf0,
+	0xd1, 0x2f, 0x2c, 0x6f, 0x23, 0x38, 0x38, 0xa8, 0xe0, 0x5f, 0xdb, 0x27, 0xff, 0x02, 0x71, 0x0a,
+	0x1c, 0x44, 0xce, 0x0f, 0x00, 0x00,
 }
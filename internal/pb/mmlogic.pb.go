@@ -71,6 +71,9 @@ type MmLogicClient interface {
 	//    these fields populated:
 	//      - id, set to the value of the MMF_ERROR_ID env var.
 	//      - error, set to a string value describing the error your MMF encountered.
+	//      - [optional] no_match_reason, if your MMF found no match, why.  It's
+	//        returned to the backend so it can decide whether to relax the
+	//        profile's constraints or keep waiting for more players.
 	//      - [optional] properties, anything you put here is returned to the
 	//        backend along with your error.
 	//      - [optional] rosters, anything you put here is returned to the
@@ -87,6 +90,9 @@ type MmLogicClient interface {
 	// RetrievePlayerPool gets the list of players that match every Filter in the
 	// PlayerPool, and then removes all players it finds in the ignore list.  It
 	// combines the results, and returns the resulting player pool.
+	// If the PlayerPool sets affinity_hints, each player's affinity and
+	// anti_affinity lists are read from their properties at the
+	// 'jsonkeys.affinity' and 'jsonkeys.antiAffinity' config keys.
 	GetPlayerPool(ctx context.Context, in *PlayerPool, opts ...grpc.CallOption) (MmLogic_GetPlayerPoolClient, error)
 	// Ignore List functions
 	//
@@ -95,6 +101,23 @@ type MmLogicClient interface {
 	// RetrieveIgnoreList retrieves players from the ignore list specified in the
 	// config file under 'ignoreLists.proposedPlayers.key'.
 	ListIgnoredPlayers(ctx context.Context, in *IlInput, opts ...grpc.CallOption) (*Roster, error)
+	// ReportProgress is called by MMFs to report progress markers while forming
+	// a match, which are forwarded to backends waiting on CreateMatchWithProgress.
+	// INPUT: MatchProgress message with the "id" field set to the value of the
+	// MMF_ERROR_ID env var, and "stage", and optionally "message" and "count",
+	// describing the progress.
+	// OUTPUT: a Result message with a boolean success value and an error string
+	// if an error was encountered
+	ReportProgress(ctx context.Context, in *MatchProgress, opts ...grpc.CallOption) (*Result, error)
+	// DeindexPlayers adds the players in the Roster to the proposed ignorelist,
+	// so no other MMF's GetPlayerPool returns them, without writing a proposal,
+	// e.g. for players an MMF holds back for a match it's still forming.
+	// Like proposed players, they're returned to the pool once the ignorelist's
+	// duration passes.
+	// INPUT: Roster message with the players' ids.
+	// OUTPUT: a Result message with a boolean success value and an error string
+	// if an error was encountered
+	DeindexPlayers(ctx context.Context, in *Roster, opts ...grpc.CallOption) (*Result, error)
 }
 
 type mmLogicClient struct {
@@ -173,6 +196,24 @@ func (c *mmLogicClient) ListIgnoredPlayers(ctx context.Context, in *IlInput, opt
 	return out, nil
 }
 
+func (c *mmLogicClient) ReportProgress(ctx context.Context, in *MatchProgress, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := grpc.Invoke(ctx, "/api.MmLogic/ReportProgress", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mmLogicClient) DeindexPlayers(ctx context.Context, in *Roster, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := grpc.Invoke(ctx, "/api.MmLogic/DeindexPlayers", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for MmLogic service
 
 type MmLogicServer interface {
@@ -204,6 +245,9 @@ type MmLogicServer interface {
 	//    these fields populated:
 	//      - id, set to the value of the MMF_ERROR_ID env var.
 	//      - error, set to a string value describing the error your MMF encountered.
+	//      - [optional] no_match_reason, if your MMF found no match, why.  It's
+	//        returned to the backend so it can decide whether to relax the
+	//        profile's constraints or keep waiting for more players.
 	//      - [optional] properties, anything you put here is returned to the
 	//        backend along with your error.
 	//      - [optional] rosters, anything you put here is returned to the
@@ -220,6 +264,9 @@ type MmLogicServer interface {
 	// RetrievePlayerPool gets the list of players that match every Filter in the
 	// PlayerPool, and then removes all players it finds in the ignore list.  It
 	// combines the results, and returns the resulting player pool.
+	// If the PlayerPool sets affinity_hints, each player's affinity and
+	// anti_affinity lists are read from their properties at the
+	// 'jsonkeys.affinity' and 'jsonkeys.antiAffinity' config keys.
 	GetPlayerPool(*PlayerPool, MmLogic_GetPlayerPoolServer) error
 	// Ignore List functions
 	//
@@ -228,6 +275,23 @@ type MmLogicServer interface {
 	// RetrieveIgnoreList retrieves players from the ignore list specified in the
 	// config file under 'ignoreLists.proposedPlayers.key'.
 	ListIgnoredPlayers(context.Context, *IlInput) (*Roster, error)
+	// ReportProgress is called by MMFs to report progress markers while forming
+	// a match, which are forwarded to backends waiting on CreateMatchWithProgress.
+	// INPUT: MatchProgress message with the "id" field set to the value of the
+	// MMF_ERROR_ID env var, and "stage", and optionally "message" and "count",
+	// describing the progress.
+	// OUTPUT: a Result message with a boolean success value and an error string
+	// if an error was encountered
+	ReportProgress(context.Context, *MatchProgress) (*Result, error)
+	// DeindexPlayers adds the players in the Roster to the proposed ignorelist,
+	// so no other MMF's GetPlayerPool returns them, without writing a proposal,
+	// e.g. for players an MMF holds back for a match it's still forming.
+	// Like proposed players, they're returned to the pool once the ignorelist's
+	// duration passes.
+	// INPUT: Roster message with the players' ids.
+	// OUTPUT: a Result message with a boolean success value and an error string
+	// if an error was encountered
+	DeindexPlayers(context.Context, *Roster) (*Result, error)
 }
 
 func RegisterMmLogicServer(s *grpc.Server, srv MmLogicServer) {
@@ -327,6 +391,42 @@ func _MmLogic_ListIgnoredPlayers_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MmLogic_ReportProgress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MatchProgress)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MmLogicServer).ReportProgress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.MmLogic/ReportProgress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MmLogicServer).ReportProgress(ctx, req.(*MatchProgress))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MmLogic_DeindexPlayers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Roster)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MmLogicServer).DeindexPlayers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.MmLogic/DeindexPlayers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MmLogicServer).DeindexPlayers(ctx, req.(*Roster))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _MmLogic_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "api.MmLogic",
 	HandlerType: (*MmLogicServer)(nil),
@@ -347,6 +447,14 @@ var _MmLogic_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ListIgnoredPlayers",
 			Handler:    _MmLogic_ListIgnoredPlayers_Handler,
 		},
+		{
+			MethodName: "ReportProgress",
+			Handler:    _MmLogic_ReportProgress_Handler,
+		},
+		{
+			MethodName: "DeindexPlayers",
+			Handler:    _MmLogic_DeindexPlayers_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -361,22 +469,24 @@ var _MmLogic_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("api/protobuf-spec/mmlogic.proto", fileDescriptor2) }
 
 var fileDescriptor2 = []byte{
-	// 263 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x91, 0x41, 0x4b, 0xf4, 0x30,
-	0x10, 0x40, 0xf7, 0xfb, 0x04, 0x85, 0x80, 0xa2, 0x61, 0xbd, 0xf4, 0xa2, 0xec, 0x7d, 0x1b, 0x51,
-	0xc4, 0x83, 0x8a, 0xe8, 0x1e, 0x4a, 0x61, 0x17, 0x8b, 0x47, 0x6f, 0x69, 0x9d, 0x76, 0x23, 0x93,
-	0x4e, 0x48, 0x26, 0x07, 0x7f, 0x9e, 0xff, 0x4c, 0xd2, 0x05, 0x8b, 0x50, 0x2f, 0x5e, 0x5f, 0xde,
-	0x9b, 0x09, 0x89, 0x38, 0xd3, 0xce, 0x28, 0xe7, 0x89, 0xa9, 0x8e, 0xed, 0x32, 0x38, 0x68, 0x94,
-	0xb5, 0x48, 0x9d, 0x69, 0xf2, 0x81, 0xca, 0x3d, 0xed, 0x4c, 0x76, 0x3e, 0x61, 0x41, 0x08, 0xba,
-	0x83, 0xb0, 0xd3, 0x2e, 0x3f, 0xff, 0x8b, 0x83, 0x8d, 0x5d, 0xa7, 0x50, 0xde, 0x09, 0x51, 0x00,
-	0x57, 0x9e, 0x5a, 0x83, 0x20, 0x4f, 0xf3, 0x6f, 0x75, 0xa3, 0xb9, 0xd9, 0x3e, 0xd7, 0xef, 0xd0,
-	0x70, 0x36, 0x8d, 0x17, 0x33, 0x79, 0x2b, 0x8e, 0x56, 0x1e, 0x34, 0x43, 0xe5, 0xc9, 0x51, 0xd0,
-	0xf8, 0xdb, 0x84, 0xe3, 0x11, 0xbf, 0x40, 0x88, 0x98, 0xe2, 0x07, 0x71, 0x98, 0x56, 0xa3, 0xfe,
-	0x00, 0x5f, 0x11, 0xa1, 0x9c, 0x8f, 0xd2, 0x48, 0xb3, 0x49, 0xba, 0x98, 0x5d, 0xfc, 0x93, 0xf7,
-	0x62, 0x5e, 0x00, 0x3f, 0x22, 0x96, 0x5d, 0x4f, 0x1e, 0xde, 0x76, 0xc7, 0x41, 0x9e, 0x8c, 0x45,
-	0x89, 0x65, 0xef, 0xe2, 0xcf, 0xfd, 0x14, 0x18, 0xfc, 0x70, 0x79, 0xb9, 0x36, 0x81, 0xff, 0x14,
-	0x3f, 0xdd, 0xbc, 0x5e, 0x77, 0x86, 0xb7, 0xb1, 0xce, 0x1b, 0xb2, 0xaa, 0x20, 0xea, 0x10, 0x56,
-	0x48, 0x31, 0xcd, 0xe1, 0x96, 0xbc, 0x55, 0xe4, 0xa0, 0x5f, 0xda, 0xf4, 0x06, 0xca, 0xf4, 0x0c,
-	0xbe, 0xd7, 0xa8, 0x5c, 0x5d, 0xef, 0x0f, 0x7f, 0x70, 0xf5, 0x15, 0x00, 0x00, 0xff, 0xff, 0xf5,
-	0xe5, 0x29, 0xa6, 0xcd, 0x01, 0x00, 0x00,
+	// 289 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9d, 0x51, 0x4f, 0x4b, 0xc3, 0x30,
+	0x14, 0x9f, 0x0c, 0x14, 0x02, 0x16, 0x0d, 0x13, 0xa1, 0x17, 0x65, 0xf7, 0x35, 0xa2, 0xa8, 0x07,
+	0x1d, 0xa2, 0x13, 0x46, 0x61, 0xc3, 0xb1, 0xa3, 0xb7, 0xb4, 0x7b, 0xcd, 0x22, 0x49, 0x5f, 0x48,
+	0x52, 0x70, 0xdf, 0xcc, 0x8f, 0x67, 0xd3, 0x31, 0x8b, 0x5a, 0x2f, 0x5e, 0x7f, 0xff, 0x5f, 0x42,
+	0xce, 0xb8, 0x91, 0xcc, 0x58, 0xf4, 0x98, 0x55, 0xc5, 0xc8, 0x19, 0xc8, 0x99, 0xd6, 0x0a, 0x85,
+	0xcc, 0x93, 0x06, 0xa5, 0xfd, 0x5a, 0x10, 0x9f, 0x77, 0xa8, 0xc0, 0x39, 0x2e, 0xc0, 0x6d, 0x65,
+	0x97, 0x1f, 0x7d, 0x72, 0x30, 0xd7, 0xb3, 0x60, 0xa4, 0xf7, 0x84, 0x4c, 0xc1, 0x2f, 0x2c, 0x16,
+	0x52, 0x01, 0x3d, 0x49, 0xbe, 0xa4, 0x73, 0xee, 0xf3, 0xf5, 0x4b, 0xf6, 0x06, 0xb9, 0x8f, 0xbb,
+	0xe1, 0x61, 0x8f, 0xde, 0x91, 0x68, 0x62, 0x81, 0x7b, 0xa8, 0x03, 0x0c, 0x3a, 0xae, 0xfe, 0x4a,
+	0x38, 0x6a, 0xe1, 0x25, 0xb8, 0x4a, 0x05, 0xf3, 0x03, 0x39, 0x0c, 0xd5, 0x8a, 0x6f, 0xc0, 0x2e,
+	0x10, 0x15, 0x1d, 0xb4, 0xa2, 0x16, 0x8d, 0x3b, 0xd1, 0x61, 0xef, 0x62, 0x8f, 0x8e, 0xc9, 0xa0,
+	0x0e, 0x78, 0x54, 0x2a, 0x15, 0x25, 0x5a, 0x58, 0x6d, 0x69, 0x47, 0x8f, 0x5b, 0x47, 0xaa, 0xd2,
+	0xd2, 0x54, 0xdf, 0xfb, 0xd1, 0x79, 0xb0, 0xcd, 0x78, 0x3a, 0x93, 0xce, 0xff, 0xcf, 0x3c, 0x26,
+	0xd1, 0x12, 0x0c, 0xda, 0xf0, 0x74, 0xc2, 0xd6, 0x34, 0x3d, 0xfd, 0x71, 0xf9, 0x8e, 0xe8, 0xbc,
+	0xfd, 0x86, 0x44, 0xcf, 0x20, 0xcb, 0x15, 0xbc, 0xef, 0x7a, 0x7f, 0x95, 0x74, 0xf9, 0x9e, 0x6e,
+	0x5f, 0xaf, 0x85, 0xf4, 0xeb, 0x2a, 0x4b, 0x72, 0xd4, 0x6c, 0x8a, 0x28, 0x14, 0x4c, 0x14, 0x56,
+	0x61, 0xbe, 0x2f, 0xd0, 0x6a, 0x86, 0x06, 0xca, 0x91, 0x0e, 0x03, 0x98, 0x2c, 0xeb, 0x98, 0x92,
+	0x2b, 0x66, 0xb2, 0x6c, 0xbf, 0xf9, 0xfa, 0xab, 0x4f, 0x83, 0x7d, 0x78, 0x6c, 0x44, 0x02, 0x00,
+	0x00,
 }
@@ -0,0 +1,40 @@
+/*
+package playerid normalizes player IDs, so that the same logical player maps
+to the same state storage key whichever RPC their ID arrives through.
+
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package playerid
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Normalize returns the player ID as it is stored, using the 'playerIds'
+// config section: 'trim' strips leading and trailing whitespace, and
+// 'lowercase' lowercases the ID.  With neither set the ID is unchanged.
+// Every RPC that takes a player ID should normalize it before using it.
+func Normalize(cfg *viper.Viper, id string) string {
+	if cfg.GetBool("playerIds.trim") {
+		id = strings.TrimSpace(id)
+	}
+	if cfg.GetBool("playerIds.lowercase") {
+		id = strings.ToLower(id)
+	}
+	return id
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package playerid
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		trim, lowercase bool
+		want            string
+	}{
+		{false, false, " Player-1 "},
+		{true, false, "Player-1"},
+		{false, true, " player-1 "},
+		{true, true, "player-1"},
+	}
+	for _, tt := range tests {
+		cfg := viper.New()
+		cfg.Set("playerIds.trim", tt.trim)
+		cfg.Set("playerIds.lowercase", tt.lowercase)
+		if got := Normalize(cfg, " Player-1 "); got != tt.want {
+			t.Errorf("Normalize() with trim=%v lowercase=%v = %q, want %q", tt.trim, tt.lowercase, got, tt.want)
+		}
+	}
+}
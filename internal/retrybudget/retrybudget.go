@@ -0,0 +1,86 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package retrybudget limits how much a single client request may retry, in
+// total, across every layer that retries state storage operations, so layered
+// retries can't compound into an unbounded cascade of redis commands during an
+// outage.  The budget travels in the request's context; layers that retry call
+// Spend before each retry, and give up once it returns ErrExhausted.
+package retrybudget
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// ErrExhausted is returned by Spend once the request has no retries left.
+var ErrExhausted = errors.New("retry budget exhausted")
+
+// budget is what's left of a request's retries.  Layers may retry
+// concurrently, so retries is updated atomically.
+type budget struct {
+	retries  int64 // Retries left, if limited.
+	limited  bool
+	deadline time.Time
+}
+
+type budgetKey struct{}
+
+// WithBudget returns a copy of ctx whose retries are limited to retries in
+// total, and to within d from now.  Either limit is ignored if it's 0.
+func WithBudget(ctx context.Context, retries int, d time.Duration) context.Context {
+	b := &budget{retries: int64(retries), limited: retries > 0}
+	if d > 0 {
+		b.deadline = time.Now().Add(d)
+	}
+	return context.WithValue(ctx, budgetKey{}, b)
+}
+
+// Enabled reports whether 'retryBudget.retries' or 'retryBudget.seconds' is
+// set.
+func Enabled(cfg *viper.Viper) bool {
+	return cfg.GetInt("retryBudget.retries") > 0 || cfg.GetInt("retryBudget.seconds") > 0
+}
+
+// FromConfig returns a copy of ctx with the budget set by 'retryBudget.retries'
+// and 'retryBudget.seconds', or ctx itself if neither is set.
+func FromConfig(ctx context.Context, cfg *viper.Viper) context.Context {
+	if !Enabled(cfg) {
+		return ctx
+	}
+	return WithBudget(ctx, cfg.GetInt("retryBudget.retries"), time.Duration(cfg.GetInt("retryBudget.seconds"))*time.Second)
+}
+
+// Spend takes one retry from the budget in ctx.  It returns ErrExhausted if
+// none are left, or if the budget's time is up, in which case the caller must
+// not retry.  Contexts without a budget may always retry.
+func Spend(ctx context.Context) error {
+	b, ok := ctx.Value(budgetKey{}).(*budget)
+	if !ok {
+		return nil
+	}
+	if !b.deadline.IsZero() && time.Now().After(b.deadline) {
+		return ErrExhausted
+	}
+	if b.limited && atomic.AddInt64(&b.retries, -1) < 0 {
+		return ErrExhausted
+	}
+	return nil
+}
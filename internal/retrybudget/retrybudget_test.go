@@ -0,0 +1,48 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retrybudget
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSpend(t *testing.T) {
+	if err := Spend(context.Background()); err != nil {
+		t.Errorf("Spend without a budget = %v, want nil", err)
+	}
+
+	ctx := WithBudget(context.Background(), 2, 0)
+	for i := 0; i < 2; i++ {
+		if err := Spend(ctx); err != nil {
+			t.Fatalf("Spend %d = %v, want nil", i, err)
+		}
+	}
+	// Layers sharing the request's context share its budget.
+	for i := 0; i < 2; i++ {
+		if err := Spend(ctx); err != ErrExhausted {
+			t.Errorf("Spend past the budget = %v, want %v", err, ErrExhausted)
+		}
+	}
+
+	ctx = WithBudget(context.Background(), 0, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	if err := Spend(ctx); err != ErrExhausted {
+		t.Errorf("Spend past the budget's time = %v, want %v", err, ErrExhausted)
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package assignment handles how player assignments (the connection string
+// sent to game clients by the Frontend API) are stored in redis.
+package assignment
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/spf13/viper"
+)
+
+// Supported values for the 'assignments.connstringEncoding' config key.
+const (
+	// EncodingPlain stores the connection string as-is. This is the default.
+	EncodingPlain = "plain"
+	// EncodingBase64 stores the connection string base64-encoded, so binary
+	// connection tokens survive the round trip through state storage.
+	EncodingBase64 = "base64"
+)
+
+// encoding returns the configured connection string encoding.
+func encoding(cfg *viper.Viper) string {
+	if cfg.IsSet("assignments.connstringEncoding") && cfg.GetString("assignments.connstringEncoding") != "" {
+		return cfg.GetString("assignments.connstringEncoding")
+	}
+	return EncodingPlain
+}
+
+// EncodeConnstring encodes a connection string for storage using the
+// encoding configured at 'assignments.connstringEncoding'.
+func EncodeConnstring(cfg *viper.Viper, connstring string) (string, error) {
+	switch enc := encoding(cfg); enc {
+	case EncodingPlain:
+		return connstring, nil
+	case EncodingBase64:
+		return base64.StdEncoding.EncodeToString([]byte(connstring)), nil
+	default:
+		return "", fmt.Errorf("unknown connection string encoding '%v'", enc)
+	}
+}
+
+// DecodeConnstring reverses EncodeConnstring on a connection string read
+// from state storage.
+func DecodeConnstring(cfg *viper.Viper, stored string) (string, error) {
+	switch enc := encoding(cfg); enc {
+	case EncodingPlain:
+		return stored, nil
+	case EncodingBase64:
+		connstring, err := base64.StdEncoding.DecodeString(stored)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode base64 connection string: %v", err)
+		}
+		return string(connstring), nil
+	default:
+		return "", fmt.Errorf("unknown connection string encoding '%v'", enc)
+	}
+}
+
+// Versions of the stored assignment payload, selected for writing with the
+// 'assignments.storageVersion' config key, which ships as
+// StorageVersionLegacy.  Readers understand every version, but components
+// that predate a version don't, so moving to a new version takes two
+// rollouts: first upgrade every component while still writing the old
+// version, then set 'assignments.storageVersion' to the new one.
+const (
+	// StorageVersionLegacy stores just the encoded connection string, so it
+	// can't hold assignment metadata.
+	StorageVersionLegacy = 0
+	// StorageVersion1 stores a versioned JSON object, so fields can be
+	// added to the stored assignment without breaking older records.
+	StorageVersion1 = 1
+)
+
+// versionMarker starts every versioned payload, and is followed by a single
+// version byte. Legacy payloads never start with it, as connection strings
+// are plain text or base64.
+const versionMarker = "\x00"
+
+// storedConnectionInfo is the StorageVersion1 payload.
+type storedConnectionInfo struct {
+	ConnectionString string            `json:"connection_string"` // Encoded as configured.
+	Metadata         map[string]string `json:"metadata,omitempty"`
+}
+
+// MarshalConnectionInfo returns the payload to store for an assignment, in
+// the version configured at 'assignments.storageVersion'.
+func MarshalConnectionInfo(cfg *viper.Viper, ci *pb.ConnectionInfo) (string, error) {
+	connstring, err := EncodeConnstring(cfg, ci.GetConnectionString())
+	if err != nil {
+		return "", err
+	}
+
+	switch version := cfg.GetInt("assignments.storageVersion"); version {
+	case StorageVersionLegacy:
+		if len(ci.GetMetadata()) > 0 {
+			return "", fmt.Errorf("assignment metadata can't be stored in storage version %v", version)
+		}
+		return connstring, nil
+	case StorageVersion1:
+		payload, err := json.Marshal(storedConnectionInfo{ConnectionString: connstring, Metadata: ci.GetMetadata()})
+		if err != nil {
+			return "", err
+		}
+		return versionMarker + string(byte(version)) + string(payload), nil
+	default:
+		return "", fmt.Errorf("unknown assignment storage version %v", version)
+	}
+}
+
+// UnmarshalConnectionInfo reverses MarshalConnectionInfo on a payload read
+// from state storage.  It reads every storage version, whichever version is
+// currently configured for writing.
+func UnmarshalConnectionInfo(cfg *viper.Viper, stored string) (*pb.ConnectionInfo, error) {
+	if !strings.HasPrefix(stored, versionMarker) {
+		connstring, err := DecodeConnstring(cfg, stored)
+		if err != nil {
+			return nil, err
+		}
+		return &pb.ConnectionInfo{ConnectionString: connstring}, nil
+	}
+
+	if len(stored) < len(versionMarker)+1 {
+		return nil, fmt.Errorf("truncated assignment payload")
+	}
+	switch version := int(stored[len(versionMarker)]); version {
+	case StorageVersion1:
+		payload := storedConnectionInfo{}
+		err := json.Unmarshal([]byte(stored[len(versionMarker)+1:]), &payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse assignment payload: %v", err)
+		}
+		connstring, err := DecodeConnstring(cfg, payload.ConnectionString)
+		if err != nil {
+			return nil, err
+		}
+		return &pb.ConnectionInfo{ConnectionString: connstring, Metadata: payload.Metadata}, nil
+	default:
+		return nil, fmt.Errorf("unsupported assignment storage version %v; is this component out of date?", version)
+	}
+}
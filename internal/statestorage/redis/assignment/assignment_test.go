@@ -0,0 +1,203 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assignment
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/alicebob/miniredis"
+	"github.com/gomodule/redigo/redis"
+	"github.com/spf13/viper"
+)
+
+func TestConnectionInfoStorageVersions(t *testing.T) {
+	for _, encoding := range []string{EncodingPlain, EncodingBase64} {
+		for _, version := range []int{StorageVersionLegacy, StorageVersion1} {
+			writer := viper.New()
+			writer.Set("assignments.connstringEncoding", encoding)
+			writer.Set("assignments.storageVersion", version)
+
+			stored, err := MarshalConnectionInfo(writer, &pb.ConnectionInfo{ConnectionString: "127.0.0.1:7777"})
+			if err != nil {
+				t.Fatalf("%v/%v: MarshalConnectionInfo failed: %v", encoding, version, err)
+			}
+
+			// Readers must understand every version, whichever one they
+			// are configured to write.
+			for _, readerVersion := range []int{StorageVersionLegacy, StorageVersion1} {
+				reader := viper.New()
+				reader.Set("assignments.connstringEncoding", encoding)
+				reader.Set("assignments.storageVersion", readerVersion)
+
+				ci, err := UnmarshalConnectionInfo(reader, stored)
+				if err != nil {
+					t.Fatalf("%v/%v: UnmarshalConnectionInfo failed: %v", encoding, version, err)
+				}
+				if ci.ConnectionString != "127.0.0.1:7777" {
+					t.Errorf("%v/%v: ConnectionString = %q, want %q", encoding, version, ci.ConnectionString, "127.0.0.1:7777")
+				}
+			}
+		}
+	}
+}
+
+func TestConnectionInfoMetadata(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("assignments.storageVersion", StorageVersion1)
+	ci := &pb.ConnectionInfo{
+		ConnectionString: "127.0.0.1:7777",
+		Metadata:         map[string]string{"match_id": "m1", "region": "us-east1"},
+	}
+
+	stored, err := MarshalConnectionInfo(cfg, ci)
+	if err != nil {
+		t.Fatalf("MarshalConnectionInfo failed: %v", err)
+	}
+	got, err := UnmarshalConnectionInfo(cfg, stored)
+	if err != nil {
+		t.Fatalf("UnmarshalConnectionInfo failed: %v", err)
+	}
+	if len(got.Metadata) != 2 || got.Metadata["match_id"] != "m1" || got.Metadata["region"] != "us-east1" {
+		t.Errorf("Metadata = %v, want %v", got.Metadata, ci.Metadata)
+	}
+
+	// The legacy payload has nowhere to put it.
+	cfg.Set("assignments.storageVersion", StorageVersionLegacy)
+	if _, err := MarshalConnectionInfo(cfg, ci); err == nil {
+		t.Error("MarshalConnectionInfo dropped metadata in the legacy storage version")
+	}
+}
+
+func TestUnmarshalConnectionInfoUnknownVersion(t *testing.T) {
+	if _, err := UnmarshalConnectionInfo(viper.New(), versionMarker+"\x7f{}"); err == nil {
+		t.Error("UnmarshalConnectionInfo accepted an unknown storage version")
+	}
+}
+
+func TestRoute(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("assignments.routing.rules", []map[string]interface{}{
+		{"attribute": "region.europe-west1", "connstring": "10.0.0.1:7777"},
+		{"attribute": "region.us-east1", "connstring": "10.0.1.1:7777"},
+	})
+	rules, err := RoutingRules(cfg)
+	if err != nil {
+		t.Fatalf("RoutingRules failed: %v", err)
+	}
+
+	tests := []struct {
+		properties string
+		want       string
+		ok         bool
+	}{
+		{`{"region.us-east1": 1, "mmr.rating": 1200}`, "10.0.1.1:7777", true},
+		// The first matching rule wins.
+		{`{"region.us-east1": 1, "region.europe-west1": 1}`, "10.0.0.1:7777", true},
+		{`{"region.asia-east1": 1}`, "", false},
+		{``, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Route(rules, tt.properties)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Route(%q) = %q, %v, want %q, %v", tt.properties, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// fieldCodec stores the connection string in a field of its own, as a
+// deployment with its own storage format might.
+type fieldCodec struct{}
+
+func (fieldCodec) Encode(ci *pb.ConnectionInfo) (map[string]string, error) {
+	return map[string]string{"dgs.address": ci.ConnectionString}, nil
+}
+
+func (fieldCodec) Decode(fields map[string]string) (*pb.ConnectionInfo, error) {
+	if fields["dgs.address"] == "" {
+		return nil, ErrNoAssignment
+	}
+	return &pb.ConnectionInfo{ConnectionString: fields["dgs.address"]}, nil
+}
+
+func TestNewCodec(t *testing.T) {
+	RegisterCodec("field", func(*viper.Viper) (AssignmentCodec, error) { return fieldCodec{}, nil })
+
+	cfg := viper.New()
+	cfg.Set("jsonkeys.connstring", "connstring")
+	for _, name := range []string{"", CodecDefault, "field"} {
+		cfg.Set("assignments.codec", name)
+		codec, err := NewCodec(cfg)
+		if err != nil {
+			t.Fatalf("NewCodec(%q) failed: %v", name, err)
+		}
+		if _, err := codec.Decode(map[string]string{"properties": "{}"}); err != ErrNoAssignment {
+			t.Errorf("%q: Decode of an unassigned player = %v, want ErrNoAssignment", name, err)
+		}
+		fields, err := codec.Encode(&pb.ConnectionInfo{ConnectionString: "127.0.0.1:7777"})
+		if err != nil {
+			t.Fatalf("%q: Encode failed: %v", name, err)
+		}
+		ci, err := codec.Decode(fields)
+		if err != nil || ci.ConnectionString != "127.0.0.1:7777" {
+			t.Errorf("%q: Decode(Encode(x)) = %v, %v, want 127.0.0.1:7777", name, ci, err)
+		}
+	}
+
+	cfg.Set("assignments.codec", "unknown")
+	if _, err := NewCodec(cfg); err == nil {
+		t.Error("NewCodec accepted an unknown codec")
+	}
+}
+
+func TestSetIfUnassigned(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+	redisConn, err := redis.Dial("tcp", mr.Addr())
+	if err != nil {
+		t.Fatalf("failed to connect to miniredis: %v", err)
+	}
+	defer redisConn.Close()
+
+	mr.HSet("assigned", "connstring", "10.0.0.1:7777")
+	mr.HSet("assigned", AssignedAtField, "1")
+	mr.HSet("queued", "properties", "{}")
+
+	fields := func(string) map[string]string { return map[string]string{"connstring": "127.0.0.1:7777"} }
+	assigned, conflicts, err := SetIfUnassigned(redisConn, []string{"assigned", "queued"}, fields, 2)
+	if err != nil {
+		t.Fatalf("SetIfUnassigned failed: %v", err)
+	}
+	if len(assigned) != 1 || assigned[0] != "queued" {
+		t.Errorf("assigned = %v, want [queued]", assigned)
+	}
+	if len(conflicts) != 1 || conflicts[0] != "assigned" {
+		t.Errorf("conflicts = %v, want [assigned]", conflicts)
+	}
+	if got := mr.HGet("assigned", "connstring"); got != "10.0.0.1:7777" {
+		t.Errorf("already assigned player's connstring = %q, want it unchanged", got)
+	}
+	if got := mr.HGet("queued", "connstring"); got != "127.0.0.1:7777" {
+		t.Errorf("queued player's connstring = %q, want 127.0.0.1:7777", got)
+	}
+	if got := mr.HGet("queued", AssignedAtField); got != "2" {
+		t.Errorf("queued player's %v = %q, want 2", AssignedAtField, got)
+	}
+}
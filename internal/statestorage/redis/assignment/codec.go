@@ -0,0 +1,122 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assignment
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/grpcutil"
+	"github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc/codes"
+)
+
+// AssignmentCodec converts connection info to and from the fields it is
+// stored as in a player's redis hash.  Deployments that store connection
+// info in their own format register a codec with RegisterCodec and select it
+// with the 'assignments.codec' config key; the Frontend and Backend APIs use
+// the selected codec for every assignment they read or write.
+type AssignmentCodec interface {
+	// Encode returns the hash fields to set on the player's record.  They
+	// must not include 'properties', which holds the player's queued data.
+	Encode(ci *pb.ConnectionInfo) (map[string]string, error)
+	// Decode reads connection info back from all the fields of a player's
+	// record.  It returns ErrNoAssignment if the player hasn't been assigned.
+	Decode(fields map[string]string) (*pb.ConnectionInfo, error)
+}
+
+// CodecFactory creates a codec from the config.
+type CodecFactory func(cfg *viper.Viper) (AssignmentCodec, error)
+
+// AssignedAtField is the hash field where the Backend API records when it
+// wrote a player's assignment, in Unix milliseconds, so the Frontend API can
+// measure how long the assignment took to reach the player.  It is written
+// alongside the codec's fields, whichever codec is selected.
+const AssignedAtField = "assignedat"
+
+// CodecDefault is the codec used when 'assignments.codec' is not set.
+const CodecDefault = "default"
+
+// ErrNoAssignment is returned by AssignmentCodec.Decode when the player's
+// record holds no assignment.
+var ErrNoAssignment = errors.New("player has no assignment")
+
+func init() {
+	grpcutil.RegisterErrorCode("noAssignment", ErrNoAssignment, codes.NotFound)
+}
+
+var (
+	codecs   = map[string]CodecFactory{CodecDefault: newDefaultCodec}
+	codecsMu sync.RWMutex
+)
+
+// RegisterCodec makes a codec available under name for selection with the
+// 'assignments.codec' config key.  Registering an existing name replaces it.
+func RegisterCodec(name string, factory CodecFactory) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[name] = factory
+}
+
+// NewCodec returns the codec selected by 'assignments.codec'.
+func NewCodec(cfg *viper.Viper) (AssignmentCodec, error) {
+	name := CodecDefault
+	if cfg.IsSet("assignments.codec") && cfg.GetString("assignments.codec") != "" {
+		name = cfg.GetString("assignments.codec")
+	}
+
+	codecsMu.RLock()
+	factory, ok := codecs[name]
+	codecsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown assignment codec '%v'", name)
+	}
+	return factory(cfg)
+}
+
+// defaultCodec stores connection info in the single hash field named by
+// 'jsonkeys.connstring', as a payload written by MarshalConnectionInfo.
+type defaultCodec struct {
+	cfg   *viper.Viper
+	field string
+}
+
+func newDefaultCodec(cfg *viper.Viper) (AssignmentCodec, error) {
+	field := cfg.GetString("jsonkeys.connstring")
+	if field == "" {
+		return nil, errors.New("'jsonkeys.connstring' must be set to use the default assignment codec")
+	}
+	return &defaultCodec{cfg: cfg, field: field}, nil
+}
+
+func (c *defaultCodec) Encode(ci *pb.ConnectionInfo) (map[string]string, error) {
+	payload, err := MarshalConnectionInfo(c.cfg, ci)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{c.field: payload}, nil
+}
+
+func (c *defaultCodec) Decode(fields map[string]string) (*pb.ConnectionInfo, error) {
+	payload := fields[c.field]
+	if payload == "" {
+		return nil, ErrNoAssignment
+	}
+	return UnmarshalConnectionInfo(c.cfg, payload)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assignment
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// RoutingRule sends players that have a property to a specific connection
+// string, e.g. players with 'region.europe-west1' to a DGS in that region.
+type RoutingRule struct {
+	Attribute        string `mapstructure:"attribute"`
+	ConnectionString string `mapstructure:"connstring"`
+}
+
+// RoutingRules returns the rules configured at 'assignments.routing.rules',
+// in priority order.  No rules means attribute-based routing is disabled.
+func RoutingRules(cfg *viper.Viper) ([]RoutingRule, error) {
+	rules := []RoutingRule{}
+	if !cfg.IsSet("assignments.routing.rules") {
+		return rules, nil
+	}
+	err := cfg.UnmarshalKey("assignments.routing.rules", &rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse assignment routing rules: %v", err)
+	}
+	for i, rule := range rules {
+		if rule.Attribute == "" || rule.ConnectionString == "" {
+			return nil, fmt.Errorf("assignment routing rule %v needs both an attribute and a connstring", i)
+		}
+	}
+	return rules, nil
+}
+
+// Route returns the connection string of the first rule whose attribute is
+// in the player's properties JSON, or ok=false if none match (including if
+// the properties can't be parsed), in which case the player should get the
+// connection string from the CreateAssignments call.
+func Route(rules []RoutingRule, properties string) (connstring string, ok bool) {
+	if len(rules) == 0 || properties == "" {
+		return "", false
+	}
+	props := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(properties), &props); err != nil {
+		return "", false
+	}
+	for _, rule := range rules {
+		if _, found := props[rule.Attribute]; found {
+			return rule.ConnectionString, true
+		}
+	}
+	return "", false
+}
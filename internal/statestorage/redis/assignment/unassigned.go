@@ -0,0 +1,62 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assignment
+
+import (
+	"github.com/gomodule/redigo/redis"
+)
+
+// setIfUnassignedScript sets the field/value pairs in ARGV[2:] on KEYS[1]
+// unless it already has the ARGV[1] field, returning 1 if it wrote them and 0
+// if it didn't.
+var setIfUnassignedScript = redis.NewScript(1, `
+if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 1 then
+	return 0
+end
+redis.call("HMSET", KEYS[1], unpack(ARGV, 2))
+return 1
+`)
+
+// SetIfUnassigned writes an assignment's fields to each player's record, and
+// AssignedAtField set to assignedAt, unless the player already has an
+// assignment.  Each player is checked and written atomically, so concurrent
+// callers can't both assign the same player.  fields returns the fields to
+// write for a player.  It returns the players that were assigned and the
+// players that were left unchanged, in the order given.
+func SetIfUnassigned(redisConn redis.Conn, playerIDs []string, fields func(playerID string) map[string]string, assignedAt int64) (assigned, conflicts []string, err error) {
+	for _, playerID := range playerIDs {
+		args := redis.Args{}.Add(playerID, AssignedAtField).AddFlat(fields(playerID)).Add(AssignedAtField, assignedAt)
+		if err := setIfUnassignedScript.Send(redisConn, args...); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := redisConn.Flush(); err != nil {
+		return nil, nil, err
+	}
+	for _, playerID := range playerIDs {
+		written, err := redis.Bool(redisConn.Receive())
+		if err != nil {
+			return nil, nil, err
+		}
+		if written {
+			assigned = append(assigned, playerID)
+		} else {
+			conflicts = append(conflicts, playerID)
+		}
+	}
+	return assigned, conflicts, nil
+}
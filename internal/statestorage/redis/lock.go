@@ -0,0 +1,149 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redisHelpers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// lockRetryInterval is how long Lock waits between attempts to take a lock
+// that is already held.
+const lockRetryInterval = 100 * time.Millisecond
+
+// releaseScript deletes a lock only if it still holds the caller's token, so
+// a caller whose lock expired can't release a lock since taken by someone
+// else.
+var releaseScript = redis.NewScript(1, `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Lock takes a distributed lock on the input key, waiting until it is free or
+// the context is done.  The lock expires after ttl in case its holder dies
+// without releasing it.  The returned token must be passed to Unlock.
+func Lock(ctx context.Context, pool *redis.Pool, key string, ttl time.Duration) (string, error) {
+	token := strings.Replace(uuid.New().String(), "-", "", -1)
+	for {
+		redisConn, err := pool.GetContext(ctx)
+		if err != nil {
+			return "", err
+		}
+		reply, err := redisConn.Do("SET", key, token, "NX", "PX", int64(ttl/time.Millisecond))
+		redisConn.Close()
+		if err != nil {
+			rhLog.WithFields(log.Fields{
+				"error": err.Error(),
+				"key":   key,
+			}).Error("state storage error")
+			return "", err
+		}
+		if reply != nil {
+			return token, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// Unlock releases a lock taken by Lock, if the lock still holds the token.
+func Unlock(pool *redis.Pool, key string, token string) error {
+	redisConn := pool.Get()
+	defer redisConn.Close()
+
+	_, err := releaseScript.Do(redisConn, key, token)
+	if err != nil {
+		rhLog.WithFields(log.Fields{
+			"error": err.Error(),
+			"key":   key,
+		}).Error("state storage error")
+	}
+	return err
+}
+
+// acquireScript adds a token to a semaphore, a sorted set of tokens scored by
+// when they expire, if it holds fewer than the limit of unexpired tokens.
+// KEYS[1] is the semaphore; ARGV is the current time and the token's expiry
+// time in milliseconds, the limit, and the token.
+var acquireScript = redis.NewScript(1, `
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[1])
+if redis.call("ZCARD", KEYS[1]) < tonumber(ARGV[3]) then
+	redis.call("ZADD", KEYS[1], ARGV[2], ARGV[4])
+	redis.call("PEXPIREAT", KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// Acquire takes one of limit slots of a distributed semaphore on the input
+// key, waiting until a slot is free or the context is done.  Like a lock,
+// each slot expires after ttl in case its holder dies without releasing it.
+// The returned token must be passed to Release.
+func Acquire(ctx context.Context, pool *redis.Pool, key string, limit int, ttl time.Duration) (string, error) {
+	token := strings.Replace(uuid.New().String(), "-", "", -1)
+	for {
+		redisConn, err := pool.GetContext(ctx)
+		if err != nil {
+			return "", err
+		}
+		now := time.Now().UnixNano() / int64(time.Millisecond)
+		acquired, err := redis.Bool(acquireScript.Do(redisConn, key, now, now+int64(ttl/time.Millisecond), limit, token))
+		redisConn.Close()
+		if err != nil {
+			rhLog.WithFields(log.Fields{
+				"error": err.Error(),
+				"key":   key,
+			}).Error("state storage error")
+			return "", err
+		}
+		if acquired {
+			return token, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// Release frees a semaphore slot taken by Acquire.
+func Release(pool *redis.Pool, key string, token string) error {
+	redisConn := pool.Get()
+	defer redisConn.Close()
+
+	_, err := redisConn.Do("ZREM", key, token)
+	if err != nil {
+		rhLog.WithFields(log.Fields{
+			"error": err.Error(),
+			"key":   key,
+		}).Error("state storage error")
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redisHelpers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/gomodule/redigo/redis"
+)
+
+func TestLock(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) }}
+	defer pool.Close()
+
+	token, err := Lock(context.Background(), pool, "testlock", time.Minute)
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+
+	// A second caller waits until its context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if _, err = Lock(ctx, pool, "testlock", time.Minute); err != context.DeadlineExceeded {
+		t.Errorf("Lock on a held lock returned %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// Only the holder's token releases the lock.
+	if err = Unlock(pool, "testlock", "wrongtoken"); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if !mr.Exists("testlock") {
+		t.Error("Unlock with the wrong token released the lock")
+	}
+	if err = Unlock(pool, "testlock", token); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if mr.Exists("testlock") {
+		t.Error("Unlock with the holder's token didn't release the lock")
+	}
+}
+
+func TestAcquire(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) }}
+	defer pool.Close()
+
+	// Two slots can be held at once.
+	token, err := Acquire(context.Background(), pool, "testsem", 2, time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if _, err = Acquire(context.Background(), pool, "testsem", 2, time.Minute); err != nil {
+		t.Fatalf("Acquire of the second slot failed: %v", err)
+	}
+
+	// A third caller waits until its context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if _, err = Acquire(ctx, pool, "testsem", 2, time.Minute); err != context.DeadlineExceeded {
+		t.Errorf("Acquire on a full semaphore returned %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// Releasing a slot lets the next caller in.
+	if err = Release(pool, "testsem", token); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, err = Acquire(context.Background(), pool, "testsem", 2, time.Minute); err != nil {
+		t.Errorf("Acquire after Release failed: %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package redisHelpers
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	log "github.com/sirupsen/logrus"
+)
+
+// EnableKeyspaceEvents adds flags to redis' 'notify-keyspace-events' config,
+// keeping the flags already set, so several subscribers can each enable the
+// events they need.  Managed redis offerings often disallow CONFIG; in that
+// case the events must be enabled by the operator.
+func EnableKeyspaceEvents(redisConn redis.Conn, flags string) error {
+	reply, err := redis.Strings(redisConn.Do("CONFIG", "GET", "notify-keyspace-events"))
+	if err != nil {
+		return err
+	}
+	current := ""
+	if len(reply) == 2 {
+		current = reply[1]
+	}
+	for _, flag := range flags {
+		if !strings.ContainsRune(current, flag) {
+			current += string(flag)
+		}
+	}
+	_, err = redisConn.Do("CONFIG", "SET", "notify-keyspace-events", current)
+	return err
+}
+
+// KeyEventHub fans one kind of redis keyevent notification (e.g. "hset") out
+// to waiters on individual keys.  All waiters share a single pubsub
+// connection, so thousands of waiters cost one subscription rather than one
+// each.
+//
+// Usage:
+//
+//	hub := NewKeyEventHub(pool, "hset")
+//	go hub.Run(ctx)
+//	events, stop := hub.Wait(key)
+//	defer stop()
+//	select { case <-events: ... }
+type KeyEventHub struct {
+	pool  *redis.Pool
+	event string
+
+	mu      sync.Mutex
+	waiters map[string]map[chan struct{}]struct{}
+}
+
+// NewKeyEventHub returns a hub for the keyevent notifications of event.  It
+// doesn't deliver anything until Run is called.
+func NewKeyEventHub(pool *redis.Pool, event string) *KeyEventHub {
+	return &KeyEventHub{
+		pool:    pool,
+		event:   event,
+		waiters: make(map[string]map[chan struct{}]struct{}),
+	}
+}
+
+// Wait registers a waiter for events on key.  The returned channel receives a
+// value after each event, with events that arrive before the waiter reads the
+// last one coalesced into it, so the waiter should re-read the key each time.
+// stop deregisters the waiter, and must be called once it stops reading.
+func (h *KeyEventHub) Wait(key string) (events <-chan struct{}, stop func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	if _, ok := h.waiters[key]; !ok {
+		h.waiters[key] = make(map[chan struct{}]struct{})
+	}
+	h.waiters[key][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(h.waiters[key], ch)
+			if len(h.waiters[key]) == 0 {
+				delete(h.waiters, key)
+			}
+		})
+	}
+}
+
+// Run delivers events to the waiters until ctx is cancelled, re-subscribing
+// after connection errors.  Waiters don't receive the events missed while
+// re-subscribing, so they should also poll at a slow interval.
+func (h *KeyEventHub) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		err := h.subscribe(ctx)
+		if err != nil && ctx.Err() == nil {
+			rhLog.WithFields(log.Fields{
+				"error": err.Error(),
+				"event": h.event,
+			}).Error("Keyevent subscription error, resubscribing")
+			time.Sleep(5 * time.Second)
+		}
+	}
+}
+
+// subscribe delivers events received on a single pubsub connection until it
+// fails or ctx is cancelled.
+func (h *KeyEventHub) subscribe(ctx context.Context) error {
+	redisConn, err := h.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	psc := redis.PubSubConn{Conn: redisConn}
+	defer psc.Close()
+
+	channel := "__keyevent@*__:" + h.event
+	if err := psc.PSubscribe(channel); err != nil {
+		return err
+	}
+
+	// Unblock Receive() when the context is cancelled.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			psc.PUnsubscribe(channel)
+		case <-done:
+		}
+	}()
+
+	for {
+		// Events can be far apart, so wait without the read timeout.
+		switch v := psc.ReceiveWithTimeout(0).(type) {
+		case redis.Message:
+			h.notify(string(v.Data))
+		case redis.Subscription:
+			if v.Count == 0 {
+				return nil
+			}
+		case error:
+			return v
+		}
+	}
+}
+
+// notify wakes every waiter on key without blocking the subscriber.
+func (h *KeyEventHub) notify(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.waiters[key] {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// The waiter hasn't read the previous event yet.
+		}
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redisHelpers
+
+import "testing"
+
+func TestKeyEventHub(t *testing.T) {
+	h := NewKeyEventHub(nil, "hset")
+	a, stopA := h.Wait("p1")
+	b, stopB := h.Wait("p1")
+	other, stopOther := h.Wait("p2")
+	defer stopOther()
+
+	// Events are coalesced until the waiter reads them.
+	h.notify("p1")
+	h.notify("p1")
+	for name, ch := range map[string]<-chan struct{}{"a": a, "b": b} {
+		select {
+		case <-ch:
+		default:
+			t.Errorf("waiter %s wasn't notified", name)
+		}
+		select {
+		case <-ch:
+			t.Errorf("waiter %s was notified twice", name)
+		default:
+		}
+	}
+	select {
+	case <-other:
+		t.Error("waiter on another key was notified")
+	default:
+	}
+
+	// Stopped waiters are deregistered, and stopping twice is harmless.
+	stopA()
+	stopA()
+	h.notify("p1")
+	select {
+	case <-a:
+		t.Error("stopped waiter was notified")
+	default:
+	}
+	select {
+	case <-b:
+	default:
+		t.Error("remaining waiter wasn't notified")
+	}
+	stopB()
+	if _, ok := h.waiters["p1"]; ok {
+		t.Error("key without waiters is still registered")
+	}
+}
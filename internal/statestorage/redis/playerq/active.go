@@ -0,0 +1,69 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package playerq
+
+import (
+	"github.com/gomodule/redigo/redis"
+	"github.com/spf13/viper"
+)
+
+// ActivePlayersKey returns the key counting the players currently in
+// matchmaking, from 'activePlayers.key', or "" if 'activePlayers.enabled'
+// isn't set.  Players are added when they're queued, and removed when they're
+// deleted, assigned, or their record expires.  The count is kept as a set
+// rather than with INCR and DECR, so a player queued or removed more than
+// once is only counted once, and the count can't drift.
+func ActivePlayersKey(cfg *viper.Viper) string {
+	if !cfg.GetBool("activePlayers.enabled") {
+		return ""
+	}
+	return cfg.GetString("activePlayers.key")
+}
+
+// AddActive counts playerIDs as active in the set at key.  It does nothing
+// if key is "".
+func AddActive(redisConn redis.Conn, key string, playerIDs []string) error {
+	if key == "" || len(playerIDs) == 0 {
+		return nil
+	}
+	_, err := redisConn.Do("SADD", redis.Args{}.Add(key).AddFlat(playerIDs)...)
+	return err
+}
+
+// RemoveActive stops counting playerIDs as active in the set at key.  It
+// does nothing if key is "".
+func RemoveActive(redisConn redis.Conn, key string, playerIDs []string) error {
+	if key == "" || len(playerIDs) == 0 {
+		return nil
+	}
+	_, err := redisConn.Do("SREM", redis.Args{}.Add(key).AddFlat(playerIDs)...)
+	return err
+}
+
+// SendRemoveActive is identical to RemoveActive only does a redigo 'Send' as
+// part of a MULTI command.
+func SendRemoveActive(redisConn redis.Conn, key string, playerIDs []string) {
+	if key == "" || len(playerIDs) == 0 {
+		return
+	}
+	redisConn.Send("SREM", redis.Args{}.Add(key).AddFlat(playerIDs)...)
+}
+
+// CountActive returns the number of active players in the set at key.
+func CountActive(redisConn redis.Conn, key string) (int64, error) {
+	return redis.Int64(redisConn.Do("SCARD", key))
+}
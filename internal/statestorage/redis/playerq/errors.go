@@ -0,0 +1,69 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package playerq
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/grpcutil"
+	"github.com/gomodule/redigo/redis"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	// ErrPlayerNotFound means the player has no record in state storage.
+	ErrPlayerNotFound = errors.New("player not found in state storage")
+	// ErrStateStorageUnavailable means state storage couldn't be reached or
+	// failed to run a command.
+	ErrStateStorageUnavailable = errors.New("state storage unavailable")
+)
+
+func init() {
+	grpcutil.RegisterErrorCode("playerNotFound", ErrPlayerNotFound, codes.NotFound)
+	grpcutil.RegisterErrorCode("stateStorageUnavailable", ErrStateStorageUnavailable, codes.Unavailable)
+}
+
+// WrapError annotates an error from a redis operation on a player with the
+// operation and player ID.  Missing records match ErrPlayerNotFound with
+// errors.Is; any other error matches ErrStateStorageUnavailable, and the
+// original redigo error is still available to errors.As.
+func WrapError(op string, playerID string, err error) error {
+	switch err {
+	case nil:
+		return nil
+	case redis.ErrNil:
+		return fmt.Errorf("%s %s: %w", op, playerID, ErrPlayerNotFound)
+	default:
+		return &storageError{op: op, playerID: playerID, err: err}
+	}
+}
+
+// storageError wraps a redigo error as an ErrStateStorageUnavailable.
+type storageError struct {
+	op       string
+	playerID string
+	err      error
+}
+
+func (e *storageError) Error() string {
+	return fmt.Sprintf("%s %s: %v: %v", e.op, e.playerID, ErrStateStorageUnavailable, e.err)
+}
+
+func (e *storageError) Unwrap() error { return e.err }
+
+func (e *storageError) Is(target error) bool { return target == ErrStateStorageUnavailable }
@@ -0,0 +1,127 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package playerq
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/spf13/viper"
+)
+
+// Layout is how a player's properties are stored in their record.
+type Layout int
+
+const (
+	// LayoutJSON stores the properties as a single JSON string in the
+	// PropertiesField of the player's record.
+	LayoutJSON Layout = iota
+	// LayoutHash stores each top-level property in its own field of the
+	// player's record, named PropertyPrefix followed by the property's key
+	// and holding the property's JSON-encoded value.  Updates only write the
+	// properties they change, and a single property can be read with HGET.
+	LayoutHash
+)
+
+const (
+	// PropertiesField is the record field holding the properties in LayoutJSON.
+	PropertiesField = "properties"
+	// PropertyPrefix starts the name of every property field in LayoutHash.
+	PropertyPrefix = "properties."
+)
+
+// LayoutFromConfig returns the layout selected by 'players.propertiesStorage',
+// either "json" (the default) or "hash".  It only affects how players are
+// created; records are read and updated in whichever layout they were
+// created in, so the setting can be changed while players are queued.
+func LayoutFromConfig(cfg *viper.Viper) (Layout, error) {
+	switch name := cfg.GetString("players.propertiesStorage"); name {
+	case "", "json":
+		return LayoutJSON, nil
+	case "hash":
+		return LayoutHash, nil
+	default:
+		return LayoutJSON, fmt.Errorf("unknown players.propertiesStorage %q, want json or hash", name)
+	}
+}
+
+// replacePropertiesScript removes the properties of KEYS[1] in either layout,
+// where ARGV[1] is PropertiesField and ARGV[2] PropertyPrefix, and sets the
+// field/value pairs in the rest of ARGV, so properties from an earlier
+// record don't survive the player being re-created.
+var replacePropertiesScript = redis.NewScript(1, `
+for _, field in ipairs(redis.call("HKEYS", KEYS[1])) do
+	if field == ARGV[1] or string.sub(field, 1, #ARGV[2]) == ARGV[2] then
+		redis.call("HDEL", KEYS[1], field)
+	end
+end
+redis.call("HMSET", KEYS[1], unpack(ARGV, 3))
+return 1
+`)
+
+// propertyFields returns the LayoutHash fields holding properties.
+func propertyFields(properties map[string]interface{}) (map[string]string, error) {
+	fields := make(map[string]string, len(properties))
+	for key, value := range properties {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[PropertyPrefix+key] = string(encoded)
+	}
+	return fields, nil
+}
+
+// HasProperties reports whether a player record read with HGETALL holds
+// properties in either layout.
+func HasProperties(fields map[string]string) bool {
+	if _, ok := fields[PropertiesField]; ok {
+		return true
+	}
+	for field := range fields {
+		if strings.HasPrefix(field, PropertyPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// PropertiesJSON returns the properties of a player record read with HGETALL
+// as a JSON object, whichever layout they were stored in.  It returns false if
+// the record has no properties.
+func PropertiesJSON(fields map[string]string) (string, bool) {
+	if properties, ok := fields[PropertiesField]; ok {
+		return properties, true
+	}
+	properties := make(map[string]json.RawMessage)
+	for field, value := range fields {
+		if strings.HasPrefix(field, PropertyPrefix) {
+			properties[strings.TrimPrefix(field, PropertyPrefix)] = json.RawMessage(value)
+		}
+	}
+	if len(properties) == 0 {
+		return "", false
+	}
+	encoded, err := json.Marshal(properties)
+	if err != nil {
+		check(err, "")
+		return "", false
+	}
+	return string(encoded), true
+}
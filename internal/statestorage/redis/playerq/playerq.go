@@ -18,9 +18,14 @@ limitations under the License.
 package playerq
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 
+	"github.com/GoogleCloudPlatform/open-match/internal/retrybudget"
 	"github.com/gomodule/redigo/redis"
 	log "github.com/sirupsen/logrus"
 )
@@ -44,6 +49,62 @@ func indicesMap(results []string) interface{} {
 	return indices
 }
 
+// ExpiryIndex is the sorted set holding the epoch timestamp at which each
+// player created with a TTL expires.  Their record expires through redis, and
+// their index entries at the same time, because matchmaking ignores players
+// in ExpiryIndex whose time has passed until PurgeExpired removes them.  So an
+// index never references a player whose record has expired.
+const ExpiryIndex = "expiry"
+
+// TombstoneField is the record field SoftDelete marks a removed player with.
+const TombstoneField = "tombstone"
+
+// tombstoned reports whether a player's record fields are those of a player
+// SoftDelete removed.
+func tombstoned(fields map[string]string) bool {
+	_, ok := fields[TombstoneField]
+	return ok
+}
+
+// refreshScript resets the expiry of the player record KEYS[1] to ARGV[2]
+// seconds, and its score in the expiry index KEYS[2] to ARGV[3], unless the
+// record is missing or has the tombstone field ARGV[1].  A TTL of 0 or less
+// leaves the record's expiry alone.  Returns 1 if the player is queued.
+var refreshScript = redis.NewScript(2, `
+if redis.call("EXISTS", KEYS[1]) == 0 or redis.call("HEXISTS", KEYS[1], ARGV[1]) == 1 then
+	return 0
+end
+if tonumber(ARGV[2]) > 0 then
+	redis.call("EXPIRE", KEYS[1], ARGV[2])
+	-- XX: a player that has already expired mustn't be re-added.
+	redis.call("ZADD", KEYS[2], "XX", ARGV[3], KEYS[1])
+end
+return 1
+`)
+
+// expiryTime returns the ExpiryIndex score of a player whose record is about
+// to be given a ttl.  It's taken before the EXPIRE runs, so the index entries
+// never outlive the record.
+func expiryTime(ttl int) int64 {
+	return time.Now().Unix() + int64(ttl)
+}
+
+// purgeExpiredScript removes up to ARGV[2] players whose expiry time in
+// KEYS[1] is at or before ARGV[1] from every index listed in KEYS[2], and
+// from KEYS[1] itself, in one atomic step so a player re-created in the
+// meantime can't lose their new index entries.
+var purgeExpiredScript = redis.NewScript(2, `
+local ids = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[2])
+if #ids == 0 then
+	return 0
+end
+for _, index in ipairs(redis.call("SMEMBERS", KEYS[2])) do
+	redis.call("ZREM", index, unpack(ids))
+end
+redis.call("ZREM", KEYS[1], unpack(ids))
+return #ids
+`)
+
 // PlayerIndices retrieves available indices for player parameters.
 func playerIndices(redisConn redis.Conn) (results []string, err error) {
 	results, err = redis.Strings(redisConn.Do("SMEMBERS", "indices"))
@@ -63,34 +124,278 @@ func playerIndices(redisConn redis.Conn) (results []string, err error) {
 //   "mode.ctf" // TRUE flag key, epoch timestamp value
 // }
 func Create(redisConn redis.Conn, playerID string, playerData string) error {
+	return CreateWithTTL(redisConn, playerID, playerData, TTLPolicy{}, LayoutJSON, nil)
+}
+
+// CreateWithTTL is Create, but additionally expires the player's JSON object
+// representation and index entries after the TTL the policy gives their
+// properties, stores the properties in the given layout, and indexes the
+// attributes in scoring with the score their function computes.
+func CreateWithTTL(redisConn redis.Conn, playerID string, playerData string, ttl TTLPolicy, layout Layout, scoring Scoring) error {
+	n, err := SendCreate(redisConn, playerID, playerData, ttl, layout, scoring)
+	if err != nil {
+		return err
+	}
+	if err := redisConn.Flush(); err != nil {
+		check(err, "")
+		return WrapError("create", playerID, err)
+	}
+	return ReceiveCreate(redisConn, playerID, n)
+}
+
+// SendCreate writes the commands for CreateWithTTL to the connection's output
+// buffer without flushing it, so several players can be written in one round
+// trip.  It returns the number of replies to read back with ReceiveCreate,
+// after the connection has been flushed.  If a scoring function fails,
+// nothing is written and the error is returned.
+func SendCreate(redisConn redis.Conn, playerID string, playerData string, ttl TTLPolicy, layout Layout, scoring Scoring) (int, error) {
 	//pdJSON, err := json.Marshal(playerData)
 	pdMap := redisValuetoMap(playerData)
+	scores := make(map[string]interface{}, len(pdMap))
+	for key, value := range pdMap {
+		score, err := scoring.score(key, value, pdMap)
+		if err != nil {
+			return 0, fmt.Errorf("create %s: %v", playerID, err)
+		}
+		scores[key] = score
+	}
 
 	redisConn.Send("MULTI")
-	redisConn.Send("HSET", playerID, "properties", playerData)
-	for key, value := range pdMap {
+	if fields := createFields(playerData, layout); fields != nil {
+		replacePropertiesScript.Send(redisConn, redis.Args{}.Add(playerID, PropertiesField, PropertyPrefix).AddFlat(fields)...)
+	} else {
+		redisConn.Send("HSET", playerID, PropertiesField, playerData)
+	}
+	// A player re-queued while their soft-deleted record is still around
+	// mustn't keep its tombstone, or its expiry.
+	redisConn.Send("HDEL", playerID, TombstoneField)
+	n := 4 // MULTI, HSET or EVAL, HDEL and EXEC
+	if seconds := ttl.ttl(pdMap); seconds > 0 {
+		redisConn.Send("EXPIRE", playerID, seconds)
+		redisConn.Send("ZADD", ExpiryIndex, expiryTime(seconds), playerID)
+		n += 2
+	} else {
+		redisConn.Send("PERSIST", playerID)
+		n++
+	}
+	for key, value := range scores {
 		// TODO: walk the JSON and flatten it
 		// Index this property
 		redisConn.Send("ZADD", key, value, playerID)
 		// Add this index to the list of indices
 		redisConn.Send("SADD", "indices", key)
+		n += 2
 	}
-	_, err := redisConn.Do("EXEC")
-	check(err, "")
-	return err
+	redisConn.Send("EXEC")
+	return n, nil
 }
 
-// Update is an alias for Create() in this implementation
-func Update(redisConn redis.Conn, playerID string, playerData string) (err error) {
-	Create(redisConn, playerID, playerData)
-	return
+// createFields returns the LayoutHash fields to create a player with, or nil
+// if the player is to be created in LayoutJSON.  Players without properties
+// are always created in LayoutJSON, since a hash without property fields
+// would look like a player that isn't queued.
+func createFields(playerData string, layout Layout) map[string]string {
+	if layout != LayoutHash {
+		return nil
+	}
+	properties, err := decodeProperties(playerData)
+	if err != nil || len(properties) == 0 {
+		return nil
+	}
+	fields, err := propertyFields(properties)
+	if err != nil {
+		check(err, "")
+		return nil
+	}
+	return fields
+}
+
+// ReceiveCreate reads the n replies to a SendCreate, returning the first
+// error among them.
+func ReceiveCreate(redisConn redis.Conn, playerID string, n int) error {
+	var first error
+	for i := 0; i < n; i++ {
+		_, err := redisConn.Receive()
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	check(first, "")
+	return WrapError("create", playerID, first)
+}
+
+// Refresh resets the expiry of a player's JSON object representation and
+// index entries to the TTL the policy gives their properties, without
+// altering them, and returns that TTL.  If it is 0 or less, it only checks
+// that the player exists, since their record never expires.  Soft-deleted
+// players aren't queued, so their tombstone's expiry is left alone.
+func Refresh(redisConn redis.Conn, playerID string, policy TTLPolicy) (int, error) {
+	ttl := policy.Default
+	if len(policy.Rules) > 0 {
+		// The player's TTL depends on their properties.
+		fields, err := redis.StringMap(redisConn.Do("HGETALL", playerID))
+		if err != nil {
+			return 0, WrapError("refresh", playerID, err)
+		}
+		properties, ok := PropertiesJSON(fields)
+		if !ok || tombstoned(fields) {
+			return 0, WrapError("refresh", playerID, redis.ErrNil)
+		}
+		ttl = policy.ttl(redisValuetoMap(properties))
+	}
+
+	found, err := redis.Bool(refreshScript.Do(redisConn, playerID, ExpiryIndex, TombstoneField, ttl, expiryTime(ttl)))
+	if err == nil && !found {
+		err = redis.ErrNil
+	}
+	return ttl, WrapError("refresh", playerID, err)
+}
+
+// maxUpdateAttempts is how many times Update retries when the player's record
+// changes between reading and writing it.
+const maxUpdateAttempts = 5
+
+// Update merges the properties in playerData into a queued player's JSON
+// object representation, and re-indexes only the properties it contains,
+// plus the attributes in scoring, whose scores are recomputed from the merged
+// properties.  Players created in LayoutHash only have the fields of those properties
+// written.  Properties it doesn't mention are kept, as are the record's TTL and the
+// player's queue timestamp, so the player doesn't lose their place in the
+// queue.  Returns an error matching ErrPlayerNotFound if the player isn't
+// queued.  Retries spend the retry budget in ctx, if any.
+func Update(ctx context.Context, redisConn redis.Conn, playerID string, playerData string, scoring Scoring) error {
+	updates, err := decodeProperties(playerData)
+	if err != nil {
+		return fmt.Errorf("update %s: invalid properties: %v", playerID, err)
+	}
+
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		if attempt > 0 {
+			if err := retrybudget.Spend(ctx); err != nil {
+				return fmt.Errorf("update %s: %w", playerID, err)
+			}
+		}
+		// Abort the write if another client changes the record after it's read.
+		if _, err := redisConn.Do("WATCH", playerID); err != nil {
+			return WrapError("update", playerID, err)
+		}
+		fields, err := redis.StringMap(redisConn.Do("HGETALL", playerID))
+		if err == nil && (!HasProperties(fields) || tombstoned(fields)) {
+			err = redis.ErrNil
+		}
+		if err != nil {
+			redisConn.Do("UNWATCH")
+			return WrapError("update", playerID, err)
+		}
+
+		// The properties are updated in the layout the player was created in.
+		var write redis.Args
+		if current, ok := fields[PropertiesField]; ok {
+			merged, err := decodeProperties(current)
+			if err != nil {
+				merged = make(map[string]interface{})
+			}
+			for key, value := range updates {
+				merged[key] = value
+			}
+			mergedJSON, err := json.Marshal(merged)
+			if err != nil {
+				redisConn.Do("UNWATCH")
+				return fmt.Errorf("update %s: %v", playerID, err)
+			}
+			write = redis.Args{}.Add(playerID, PropertiesField, string(mergedJSON))
+		} else if len(updates) > 0 {
+			// In LayoutHash only the properties being updated are written.
+			updated, err := propertyFields(updates)
+			if err != nil {
+				redisConn.Do("UNWATCH")
+				return fmt.Errorf("update %s: %v", playerID, err)
+			}
+			write = redis.Args{}.Add(playerID).AddFlat(updated)
+		}
+
+		scores, err := updateScores(fields, playerData, updates, scoring)
+		if err != nil {
+			redisConn.Do("UNWATCH")
+			return fmt.Errorf("update %s: %v", playerID, err)
+		}
+
+		redisConn.Send("MULTI")
+		if write != nil {
+			redisConn.Send("HMSET", write...)
+		}
+		for key, value := range scores {
+			redisConn.Send("ZADD", key, value, playerID)
+			redisConn.Send("SADD", "indices", key)
+		}
+		reply, err := redisConn.Do("EXEC")
+		if err != nil {
+			return WrapError("update", playerID, err)
+		}
+		if reply != nil {
+			return nil
+		}
+		pqLog.WithFields(log.Fields{"playerid": playerID}).Debug("Player changed during update, retrying")
+	}
+	return fmt.Errorf("update %s: too many concurrent changes to the player, try again", playerID)
+}
+
+// updateScores returns the index scores to write for an Update of a player
+// whose record is fields: the updated properties, and the attributes in
+// scoring, as a scoring function may depend on any of the properties.
+func updateScores(fields map[string]string, playerData string, updates map[string]interface{}, scoring Scoring) (map[string]interface{}, error) {
+	if len(scoring) == 0 {
+		return updates, nil
+	}
+	current, _ := PropertiesJSON(fields)
+	properties := redisValuetoMap(current)
+	for key, value := range redisValuetoMap(playerData) {
+		properties[key] = value
+	}
+
+	scores := make(map[string]interface{}, len(updates)+len(scoring))
+	for key, value := range updates {
+		scores[key] = value
+	}
+	for key := range scoring {
+		if _, ok := properties[key]; ok {
+			scores[key] = properties[key]
+		}
+	}
+	for key, value := range scores {
+		score, err := scoring.score(key, value, properties)
+		if err != nil {
+			return nil, err
+		}
+		scores[key] = score
+	}
+	return scores, nil
+}
+
+// decodeProperties parses a player's JSON properties, keeping numbers as
+// written so they aren't reformatted when the properties are written back.
+func decodeProperties(playerData string) (map[string]interface{}, error) {
+	properties := make(map[string]interface{})
+	d := json.NewDecoder(strings.NewReader(playerData))
+	d.UseNumber()
+	err := d.Decode(&properties)
+	return properties, err
 }
 
-// Retrieve a player's JSON object representation from state storage.
+// Retrieve a player's JSON object representation from state storage,
+// whichever layout it was stored in.
 func Retrieve(redisConn redis.Conn, playerID string) (results map[string]interface{}, err error) {
-	r, err := redis.String(redisConn.Do("HGET", playerID, "properties"))
+	fields, err := redis.StringMap(redisConn.Do("HGETALL", playerID))
+	r, ok := PropertiesJSON(fields)
+	if err == nil && !ok {
+		err = redis.ErrNil
+	}
 	if err != nil {
-		log.Println("Failed to get properties from playerID using HGET", err)
+		pqLog.WithFields(log.Fields{
+			"error":    err.Error(),
+			"playerid": playerID,
+		}).Error("Failed to get properties from playerID using HGETALL")
+		return redisValuetoMap(r), WrapError("retrieve", playerID, err)
 	}
 	results = redisValuetoMap(r)
 	return
@@ -106,9 +411,14 @@ func redisValuetoMap(result string) map[string]interface{} {
 }
 
 // Delete a player's JSON object representation from state storage,
-// and attempt to remove the player's presence in any indexes.
-func Delete(redisConn redis.Conn, playerID string) (err error) {
+// and attempt to remove the player's presence in any indexes.  Returns the
+// number of records and index entries that were removed.
+func Delete(redisConn redis.Conn, playerID string) (removed int64, err error) {
+	// Deleting a player that is already gone is not an error.
 	results, err := Retrieve(redisConn, playerID)
+	if err != nil && !errors.Is(err, ErrPlayerNotFound) {
+		return 0, err
+	}
 	redisConn.Send("MULTI")
 	redisConn.Send("DEL", playerID)
 
@@ -119,9 +429,64 @@ func Delete(redisConn redis.Conn, playerID string) (err error) {
 			"key":   playerID}).Debug("De-Indexing field")
 		redisConn.Send("ZREM", iName, playerID)
 	}
-	_, err = redisConn.Do("EXEC")
+	redisConn.Send("ZREM", ExpiryIndex, playerID)
+	replies, err := redis.Int64s(redisConn.Do("EXEC"))
 	check(err, "")
-	return
+	return sumReplies(replies), WrapError("delete", playerID, err)
+}
+
+// SoftDelete removes a player from all indices immediately, but rather than
+// deleting their JSON object representation from state storage, marks it with
+// a TombstoneField holding the epoch timestamp of the deletion and lets it
+// expire after ttl seconds.  This gives audit tooling a window to observe
+// recently-removed players.  Players without a record aren't given one.
+// Returns the number of index entries that were removed.
+func SoftDelete(redisConn redis.Conn, playerID string, ttl int) (removed int64, err error) {
+	results, err := Retrieve(redisConn, playerID)
+	found := err == nil
+	if err != nil && !errors.Is(err, ErrPlayerNotFound) {
+		return 0, err
+	}
+	redisConn.Send("MULTI")
+	tombstoned := 0
+	if found {
+		redisConn.Send("HSET", playerID, TombstoneField, time.Now().Unix())
+		redisConn.Send("EXPIRE", playerID, ttl)
+		tombstoned = 2
+	}
+
+	// Remove playerID from indices
+	for iName := range results {
+		log.WithFields(log.Fields{
+			"field": iName,
+			"key":   playerID}).Debug("De-Indexing field")
+		redisConn.Send("ZREM", iName, playerID)
+	}
+	redisConn.Send("ZREM", ExpiryIndex, playerID)
+	replies, err := redis.Int64s(redisConn.Do("EXEC"))
+	check(err, "")
+	if err != nil {
+		return 0, WrapError("soft delete", playerID, err)
+	}
+	// The HSET and EXPIRE replies don't count; the record is only tombstoned.
+	return sumReplies(replies[tombstoned:]), nil
+}
+
+// PurgeExpired removes up to limit players whose expiry time has passed from
+// every index, and returns how many were removed.  Their records have
+// already expired in redis.
+func PurgeExpired(redisConn redis.Conn, limit int) (int, error) {
+	return redis.Int(purgeExpiredScript.Do(redisConn, ExpiryIndex, "indices", time.Now().Unix(), limit))
+}
+
+// sumReplies totals the integer replies of the DEL and ZREM commands in a
+// delete transaction, which is the number of records and index entries that
+// were actually removed.
+func sumReplies(replies []int64) (n int64) {
+	for _, r := range replies {
+		n += r
+	}
+	return n
 }
 
 // Deindex a player without deleting there JSON object representation from
@@ -0,0 +1,370 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package playerq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/gomodule/redigo/redis"
+	"github.com/spf13/viper"
+)
+
+func TestCreateWithTTLExpiresIndices(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+	redisConn, err := redis.Dial("tcp", mr.Addr())
+	if err != nil {
+		t.Fatalf("failed to connect to miniredis: %v", err)
+	}
+	defer redisConn.Close()
+
+	before := time.Now().Unix()
+	if err = CreateWithTTL(redisConn, "p1", `{"mmr.rating": 1200}`, TTLPolicy{Default: 60}, LayoutJSON, nil); err != nil {
+		t.Fatalf("CreateWithTTL failed: %v", err)
+	}
+	if err = Create(redisConn, "p2", `{"mmr.rating": 1300}`); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// The index entries must not outlive the record.
+	score, err := mr.ZScore(ExpiryIndex, "p1")
+	if err != nil {
+		t.Fatalf("player created with a TTL is not in the expiry index: %v", err)
+	}
+	if expireAt := int64(score); expireAt < before+60 || expireAt > time.Now().Unix()+60 {
+		t.Errorf("expiry index time = %v, want %v seconds after creation", expireAt, 60)
+	}
+	if got := mr.TTL("p1"); got != 60*time.Second {
+		t.Errorf("record TTL = %v, want %v", got, 60*time.Second)
+	}
+	if _, err := mr.ZScore(ExpiryIndex, "p2"); err == nil {
+		t.Error("player created without a TTL is in the expiry index")
+	}
+
+	// Once the expiry time passes the player is purged from every index, and
+	// players without a TTL are left alone.
+	mr.ZAdd(ExpiryIndex, float64(before-1), "p1")
+	mr.Del("p1")
+	n, err := PurgeExpired(redisConn, 100)
+	if err != nil || n != 1 {
+		t.Fatalf("PurgeExpired = %v, %v, want 1 player purged", n, err)
+	}
+	members, _ := mr.ZMembers("mmr.rating")
+	if len(members) != 1 || members[0] != "p2" {
+		t.Errorf("mmr.rating index = %v, want only p2", members)
+	}
+	if mr.Exists(ExpiryIndex) {
+		t.Error("purged player is still in the expiry index")
+	}
+
+	// Deleting a player removes them from the expiry index too.
+	if err = CreateWithTTL(redisConn, "p3", `{"mmr.rating": 1400}`, TTLPolicy{Default: 60}, LayoutJSON, nil); err != nil {
+		t.Fatalf("CreateWithTTL failed: %v", err)
+	}
+	removed, err := Delete(redisConn, "p3")
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	// The record, its rating index entry, and its expiry index entry.
+	if removed != 3 {
+		t.Errorf("Delete removed %d, want 3", removed)
+	}
+	if mr.Exists(ExpiryIndex) {
+		t.Error("deleted player is still in the expiry index")
+	}
+}
+
+func TestLayoutHash(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+	redisConn, err := redis.Dial("tcp", mr.Addr())
+	if err != nil {
+		t.Fatalf("failed to connect to miniredis: %v", err)
+	}
+	defer redisConn.Close()
+
+	// Re-creating a JSON player in LayoutHash replaces their old properties.
+	if err = Create(redisConn, "p1", `{"mmr.rating": 1200, "region": "us"}`); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err = CreateWithTTL(redisConn, "p1", `{"mmr.rating": 1300, "mode.ctf": 1}`, TTLPolicy{}, LayoutHash, nil); err != nil {
+		t.Fatalf("CreateWithTTL failed: %v", err)
+	}
+	if mr.HGet("p1", PropertiesField) != "" {
+		t.Error("player re-created in LayoutHash still has JSON properties")
+	}
+	if got := mr.HGet("p1", PropertyPrefix+"mmr.rating"); got != "1300" {
+		t.Errorf("mmr.rating field = %q, want 1300", got)
+	}
+
+	// Updates only write the properties they mention.
+	if err = Update(context.Background(), redisConn, "p1", `{"mmr.rating": 1350}`, nil); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if got := mr.HGet("p1", PropertyPrefix+"mmr.rating"); got != "1350" {
+		t.Errorf("mmr.rating field after update = %q, want 1350", got)
+	}
+	if score, _ := mr.ZScore("mmr.rating", "p1"); score != 1350 {
+		t.Errorf("mmr.rating index = %v, want 1350", score)
+	}
+
+	properties, err := Retrieve(redisConn, "p1")
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if len(properties) != 2 || properties["mmr.rating"] != float64(1350) || properties["mode.ctf"] != float64(1) {
+		t.Errorf("Retrieve = %v, want mmr.rating 1350 and mode.ctf 1", properties)
+	}
+
+	if err = Update(context.Background(), redisConn, "p2", `{"mmr.rating": 1350}`, nil); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Update of a missing player = %v, want ErrPlayerNotFound", err)
+	}
+}
+
+func TestScoring(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+	redisConn, err := redis.Dial("tcp", mr.Addr())
+	if err != nil {
+		t.Fatalf("failed to connect to miniredis: %v", err)
+	}
+	defer redisConn.Close()
+
+	RegisterScoreFunc("blend", func(properties map[string]interface{}) (float64, error) {
+		rating, _ := properties["mmr.rating"].(float64)
+		recent, _ := properties["mmr.recent"].(float64)
+		return rating + recent, nil
+	})
+	cfg := viper.New()
+	cfg.Set("players.scoring", []map[string]interface{}{{"attribute": "mmr.rating", "function": "blend"}})
+	scoring, err := ScoringFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("ScoringFromConfig failed: %v", err)
+	}
+
+	if err = CreateWithTTL(redisConn, "p1", `{"mmr.rating": 1200, "mmr.recent": 50}`, TTLPolicy{}, LayoutJSON, scoring); err != nil {
+		t.Fatalf("CreateWithTTL failed: %v", err)
+	}
+	if score, _ := mr.ZScore("mmr.rating", "p1"); score != 1250 {
+		t.Errorf("mmr.rating score = %v, want 1250", score)
+	}
+	if score, _ := mr.ZScore("mmr.recent", "p1"); score != 50 {
+		t.Errorf("mmr.recent score = %v, want its raw value 50", score)
+	}
+
+	// Updating an input re-scores the attribute, though it isn't updated.
+	if err = Update(context.Background(), redisConn, "p1", `{"mmr.recent": -100}`, scoring); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if score, _ := mr.ZScore("mmr.rating", "p1"); score != 1100 {
+		t.Errorf("mmr.rating score after update = %v, want 1100", score)
+	}
+
+	cfg.Set("players.scoring", []map[string]interface{}{{"attribute": "mmr.rating", "function": "unknown"}})
+	if _, err := ScoringFromConfig(cfg); err == nil {
+		t.Error("ScoringFromConfig accepted an unknown function")
+	}
+}
+
+func TestPruneIndex(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+	redisConn, err := redis.Dial("tcp", mr.Addr())
+	if err != nil {
+		t.Fatalf("failed to connect to miniredis: %v", err)
+	}
+	defer redisConn.Close()
+
+	if err = Create(redisConn, "live", `{"mmr": 1200}`); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	mr.ZAdd("mmr", 1300, "ghost")
+
+	removed, err := PruneIndex(redisConn, "mmr", []string{"live", "ghost"})
+	if err != nil {
+		t.Fatalf("PruneIndex failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "ghost" {
+		t.Errorf("PruneIndex removed %v, want [ghost]", removed)
+	}
+	members, _ := mr.ZMembers("mmr")
+	if len(members) != 1 || members[0] != "live" {
+		t.Errorf("index holds %v after pruning, want [live]", members)
+	}
+}
+
+func TestTTLRules(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+	redisConn, err := redis.Dial("tcp", mr.Addr())
+	if err != nil {
+		t.Fatalf("failed to connect to miniredis: %v", err)
+	}
+	defer redisConn.Close()
+
+	cfg := viper.New()
+	cfg.Set("players.ttl", 60)
+	cfg.Set("players.ttlRules", []map[string]interface{}{
+		{"attribute": "tier", "value": "premium", "ttl": 300},
+		{"attribute": "ranked", "value": "1", "ttl": 120},
+	})
+	policy, err := TTLFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("TTLFromConfig failed: %v", err)
+	}
+
+	players := map[string]string{
+		"premium": `{"tier": "premium", "ranked": 1}`,
+		"ranked":  `{"tier": "casual", "ranked": 1}`,
+		"casual":  `{"tier": "casual"}`,
+	}
+	for id, properties := range players {
+		if err = CreateWithTTL(redisConn, id, properties, policy, LayoutJSON, nil); err != nil {
+			t.Fatalf("CreateWithTTL failed: %v", err)
+		}
+	}
+	want := map[string]time.Duration{"premium": 300 * time.Second, "ranked": 120 * time.Second, "casual": 60 * time.Second}
+	for id, ttl := range want {
+		if got := mr.TTL(id); got != ttl {
+			t.Errorf("%v record TTL = %v, want %v", id, got, ttl)
+		}
+		if got := policy.TTL(players[id]); time.Duration(got)*time.Second != ttl {
+			t.Errorf("%v policy TTL = %vs, want %v", id, got, ttl)
+		}
+	}
+
+	// Refreshing keeps each player's own TTL.
+	if _, err = redisConn.Do("EXPIRE", "premium", 1); err != nil {
+		t.Fatalf("EXPIRE failed: %v", err)
+	}
+	ttl, err := Refresh(redisConn, "premium", policy)
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if ttl != 300 {
+		t.Errorf("Refresh TTL = %v, want 300", ttl)
+	}
+	if got := mr.TTL("premium"); got != 300*time.Second {
+		t.Errorf("refreshed record TTL = %v, want %v", got, 300*time.Second)
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+	redisConn, err := redis.Dial("tcp", mr.Addr())
+	if err != nil {
+		t.Fatalf("failed to connect to miniredis: %v", err)
+	}
+	defer redisConn.Close()
+
+	if err = Create(redisConn, "p1", `{"mmr.rating": 1200}`); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	removed, err := SoftDelete(redisConn, "p1", 300)
+	if err != nil || removed != 1 {
+		t.Fatalf("SoftDelete = %v, %v, want 1 index entry removed", removed, err)
+	}
+	if members, _ := mr.ZMembers("mmr.rating"); len(members) != 0 {
+		t.Errorf("mmr.rating index = %v after SoftDelete, want empty", members)
+	}
+	if mr.HGet("p1", TombstoneField) == "" {
+		t.Error("soft-deleted record has no tombstone")
+	}
+	if got := mr.TTL("p1"); got != 300*time.Second {
+		t.Errorf("soft-deleted record TTL = %v, want %v", got, 300*time.Second)
+	}
+
+	// A soft-deleted player isn't queued, so can't be updated or refreshed.
+	if err = Update(context.Background(), redisConn, "p1", `{"mmr.rating": 1250}`, nil); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Update of a soft-deleted player = %v, want %v", err, ErrPlayerNotFound)
+	}
+	if members, _ := mr.ZMembers("mmr.rating"); len(members) != 0 {
+		t.Errorf("mmr.rating index = %v after updating a soft-deleted player, want empty", members)
+	}
+	for _, policy := range []TTLPolicy{{Default: 600}, {Rules: []TTLRule{{Attribute: "mmr.rating", Value: "1200", TTL: 600}}}} {
+		if _, err = Refresh(redisConn, "p1", policy); !errors.Is(err, ErrPlayerNotFound) {
+			t.Errorf("Refresh(%v) of a soft-deleted player = %v, want %v", policy, err, ErrPlayerNotFound)
+		}
+	}
+	if got := mr.TTL("p1"); got != 300*time.Second {
+		t.Errorf("refreshed soft-deleted record TTL = %v, want %v", got, 300*time.Second)
+	}
+
+	// Re-queueing the player brings their record back for good.
+	if err = Create(redisConn, "p1", `{"mmr.rating": 1300}`); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if mr.HGet("p1", TombstoneField) != "" {
+		t.Error("re-created record kept its tombstone")
+	}
+	if got := mr.TTL("p1"); got != 0 {
+		t.Errorf("re-created record TTL = %v, want none", got)
+	}
+
+	// Players that aren't queued aren't given a record.
+	removed, err = SoftDelete(redisConn, "missing", 300)
+	if err != nil || removed != 0 {
+		t.Errorf("SoftDelete of a missing player = %v, %v, want 0, nil", removed, err)
+	}
+	if mr.Exists("missing") {
+		t.Error("SoftDelete created a record for a missing player")
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	if err := WrapError("retrieve", "p1", nil); err != nil {
+		t.Errorf("WrapError(nil) = %v, want nil", err)
+	}
+
+	err := WrapError("retrieve", "p1", redis.ErrNil)
+	if !errors.Is(err, ErrPlayerNotFound) || errors.Is(err, ErrStateStorageUnavailable) {
+		t.Errorf("WrapError(redis.ErrNil) = %v, want only ErrPlayerNotFound", err)
+	}
+
+	err = WrapError("retrieve", "p1", redis.Error("ERR wrong number of arguments"))
+	if !errors.Is(err, ErrStateStorageUnavailable) || errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("WrapError(redis.Error) = %v, want only ErrStateStorageUnavailable", err)
+	}
+	// The redigo error is still there for callers that need it.
+	var redisErr redis.Error
+	if !errors.As(err, &redisErr) || redisErr != "ERR wrong number of arguments" {
+		t.Errorf("errors.As(%v, redis.Error) = %q, want the original error", err, redisErr)
+	}
+}
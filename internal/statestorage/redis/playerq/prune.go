@@ -0,0 +1,45 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package playerq
+
+import (
+	"github.com/gomodule/redigo/redis"
+)
+
+// pruneIndexScript removes each player in ARGV whose record no longer exists
+// from the index KEYS[1], returning the players it removed.  Checking and
+// removing in one script means a player created in between keeps their entry.
+var pruneIndexScript = redis.NewScript(1, `
+local removed = {}
+for _, id in ipairs(ARGV) do
+	if redis.call("EXISTS", id) == 0 then
+		redis.call("ZREM", KEYS[1], id)
+		table.insert(removed, id)
+	end
+end
+return removed
+`)
+
+// PruneIndex removes the entries of players whose record no longer exists
+// from an index, e.g. ones left behind when a record expired or an update
+// didn't clean up after itself, and returns the players it removed.
+func PruneIndex(redisConn redis.Conn, index string, playerIDs []string) ([]string, error) {
+	if len(playerIDs) == 0 {
+		return nil, nil
+	}
+	return redis.Strings(pruneIndexScript.Do(redisConn, redis.Args{}.Add(index).AddFlat(playerIDs)...))
+}
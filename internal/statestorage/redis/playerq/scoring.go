@@ -0,0 +1,96 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package playerq
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// ScoreFunc computes the score a player is indexed with for an attribute,
+// from all of the player's properties as decoded by encoding/json, e.g. to
+// blend their skill rating with their recent performance.
+type ScoreFunc func(properties map[string]interface{}) (float64, error)
+
+var (
+	scoreFuncs   = map[string]ScoreFunc{}
+	scoreFuncsMu sync.RWMutex
+)
+
+// RegisterScoreFunc makes a scoring function available under name for
+// selection in the 'players.scoring' config.  Registering an existing name
+// replaces it.
+func RegisterScoreFunc(name string, fn ScoreFunc) {
+	scoreFuncsMu.Lock()
+	defer scoreFuncsMu.Unlock()
+	scoreFuncs[name] = fn
+}
+
+// ScoringRule indexes an attribute with the score computed by a registered
+// scoring function, instead of the attribute's raw value.
+type ScoringRule struct {
+	Attribute string `mapstructure:"attribute"`
+	Function  string `mapstructure:"function"`
+}
+
+// Scoring maps indexed attributes to the functions that compute their
+// scores.  Attributes without one are indexed with their raw value.
+type Scoring map[string]ScoreFunc
+
+// ScoringFromConfig returns the scoring functions selected by the rules at
+// 'players.scoring'.  No rules means every attribute is indexed with its raw
+// value.
+func ScoringFromConfig(cfg *viper.Viper) (Scoring, error) {
+	scoring := Scoring{}
+	if !cfg.IsSet("players.scoring") {
+		return scoring, nil
+	}
+	rules := []ScoringRule{}
+	if err := cfg.UnmarshalKey("players.scoring", &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse player scoring rules: %v", err)
+	}
+
+	scoreFuncsMu.RLock()
+	defer scoreFuncsMu.RUnlock()
+	for i, rule := range rules {
+		if rule.Attribute == "" || rule.Function == "" {
+			return nil, fmt.Errorf("player scoring rule %v needs both an attribute and a function", i)
+		}
+		fn, ok := scoreFuncs[rule.Function]
+		if !ok {
+			return nil, fmt.Errorf("unknown scoring function '%v' for attribute '%v'", rule.Function, rule.Attribute)
+		}
+		scoring[rule.Attribute] = fn
+	}
+	return scoring, nil
+}
+
+// score returns the score to index attribute with: the result of its scoring
+// function if it has one, and value otherwise.
+func (s Scoring) score(attribute string, value interface{}, properties map[string]interface{}) (interface{}, error) {
+	fn, ok := s[attribute]
+	if !ok {
+		return value, nil
+	}
+	score, err := fn(properties)
+	if err != nil {
+		return nil, fmt.Errorf("scoring %v: %v", attribute, err)
+	}
+	return score, nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package playerq
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// TTLRule gives players whose property Attribute has the value Value a queue
+// TTL of TTL seconds.  Numbers and booleans match their JSON form, e.g. "2"
+// or "true".
+type TTLRule struct {
+	Attribute string `mapstructure:"attribute"`
+	Value     string `mapstructure:"value"`
+	TTL       int    `mapstructure:"ttl"`
+}
+
+// TTLPolicy decides how long a player stays queued from their properties,
+// e.g. to give premium or ranked players more time to find a match than
+// casual players.  The first rule that matches wins; players no rule matches
+// get Default.  A TTL of 0 or less never expires.
+type TTLPolicy struct {
+	Default int
+	Rules   []TTLRule
+}
+
+// TTLFromConfig returns the policy set by 'players.ttl' and the rules at
+// 'players.ttlRules'.
+func TTLFromConfig(cfg *viper.Viper) (TTLPolicy, error) {
+	policy := TTLPolicy{Default: cfg.GetInt("players.ttl")}
+	if !cfg.IsSet("players.ttlRules") {
+		return policy, nil
+	}
+	if err := cfg.UnmarshalKey("players.ttlRules", &policy.Rules); err != nil {
+		return policy, fmt.Errorf("failed to parse player TTL rules: %v", err)
+	}
+	for i, rule := range policy.Rules {
+		if rule.Attribute == "" {
+			return policy, fmt.Errorf("player TTL rule %v needs an attribute", i)
+		}
+	}
+	return policy, nil
+}
+
+// Expires reports whether the policy gives any player a TTL.
+func (p TTLPolicy) Expires() bool {
+	if p.Default > 0 {
+		return true
+	}
+	for _, rule := range p.Rules {
+		if rule.TTL > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// TTL returns the TTL of a player with the JSON object properties in
+// playerData.
+func (p TTLPolicy) TTL(playerData string) int {
+	if len(p.Rules) == 0 {
+		return p.Default
+	}
+	return p.ttl(redisValuetoMap(playerData))
+}
+
+// ttl returns the TTL of a player with properties, as decoded by
+// encoding/json.
+func (p TTLPolicy) ttl(properties map[string]interface{}) int {
+	for _, rule := range p.Rules {
+		value, ok := properties[rule.Attribute]
+		if !ok {
+			continue
+		}
+		if fmt.Sprint(value) == rule.Value {
+			return rule.TTL
+		}
+	}
+	return p.Default
+}
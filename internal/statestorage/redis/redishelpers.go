@@ -40,6 +40,25 @@ var (
 	rhLog = log.WithFields(rhLogFields)
 )
 
+// Socket timeouts, in milliseconds, for connections to redis when
+// 'redis.timeouts.connect', 'redis.timeouts.read' and 'redis.timeouts.write'
+// are not set.  Without them a stalled socket would hang its caller forever,
+// whatever the caller's context deadline.
+const (
+	defaultConnectTimeout = 5000
+	defaultReadTimeout    = 10000
+	defaultWriteTimeout   = 10000
+)
+
+// configTimeout returns the duration in milliseconds at key, or def if unset.
+func configTimeout(cfg *viper.Viper, key string, def int) time.Duration {
+	ms := def
+	if cfg.IsSet(key) && cfg.GetInt(key) > 0 {
+		ms = cfg.GetInt(key)
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
 // ConnectionPool reads the configuration and attempts to instantiate a redis connection
 // pool based on the configured hostname and port.
 // TODO: needs to be reworked to use redis sentinel when we're ready to support it.
@@ -55,12 +74,27 @@ func ConnectionPool(cfg *viper.Viper) *redis.Pool {
 	}
 	redisURL += cfg.GetString("redis.hostname") + ":" + cfg.GetString("redis.port")
 
+	// Optionally log and count commands slower than a threshold.
+	slowThreshold := time.Duration(cfg.GetInt("redis.slowOperationThreshold")) * time.Millisecond
+	dialOpts := []redis.DialOption{
+		redis.DialConnectTimeout(configTimeout(cfg, "redis.timeouts.connect", defaultConnectTimeout)),
+		redis.DialReadTimeout(configTimeout(cfg, "redis.timeouts.read", defaultReadTimeout)),
+		redis.DialWriteTimeout(configTimeout(cfg, "redis.timeouts.write", defaultWriteTimeout)),
+	}
+	dial := func() (redis.Conn, error) {
+		redisConn, err := redis.DialURL(redisURL, dialOpts...)
+		if err != nil || slowThreshold <= 0 {
+			return redisConn, err
+		}
+		return withSlowLog(redisConn, slowThreshold), nil
+	}
+
 	rhLog.WithFields(log.Fields{"redisURL": redisURL}).Debug("Attempting to connect to Redis")
 	pool := redis.Pool{
 		MaxIdle:     cfg.GetInt("redis.pool.maxIdle"),
 		MaxActive:   cfg.GetInt("redis.pool.maxActive"),
 		IdleTimeout: cfg.GetDuration("redis.pool.idleTimeout") * time.Second,
-		Dial:        func() (redis.Conn, error) { return redis.DialURL(redisURL) },
+		Dial:        dial,
 	}
 
 	// Sanity check that connection works before passing it back.  Redigo
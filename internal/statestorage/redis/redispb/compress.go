@@ -0,0 +1,81 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redispb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// gzipMagic starts every gzip stream. JSON and plain strings written to a
+// MatchObject hash never start with it, so compressed fields can be told
+// apart from uncompressed ones on read.
+const gzipMagic = "\x1f\x8b"
+
+// defaultCompressionMinSize is the smallest field, in bytes, that is
+// compressed when 'redis.compression.minSize' is not set in the config.
+// Below this, the gzip header costs more than compression saves.
+const defaultCompressionMinSize = 1024
+
+// shouldCompress returns true if compression is enabled at
+// 'redis.compression.enabled' and the value is big enough to be worth it.
+func shouldCompress(cfg *viper.Viper, value string) bool {
+	if cfg == nil || !cfg.GetBool("redis.compression.enabled") {
+		return false
+	}
+	minSize := defaultCompressionMinSize
+	if cfg.IsSet("redis.compression.minSize") && cfg.GetInt("redis.compression.minSize") > 0 {
+		minSize = cfg.GetInt("redis.compression.minSize")
+	}
+	return len(value) >= minSize
+}
+
+// Compress gzips a MatchObject field value for storage.
+func Compress(value string) (string, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(value)); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Decompress reverses Compress on a MatchObject field value read from state
+// storage.  Values that weren't compressed are returned unchanged, so
+// records written before compression was enabled stay readable.
+func Decompress(value string) (string, error) {
+	if !strings.HasPrefix(value, gzipMagic) {
+		return value, nil
+	}
+	zr, err := gzip.NewReader(strings.NewReader(value))
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+	out, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
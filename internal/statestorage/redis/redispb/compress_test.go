@@ -0,0 +1,92 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redispb
+
+import (
+	"fmt"
+	"testing"
+
+	om_messages "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/tidwall/gjson"
+)
+
+// testRosters returns the stored 'rosters' field of a match with two teams
+// of n/2 players, each with a few attributes and a properties blob.
+func testRosters(tb testing.TB, n int) string {
+	mo := &om_messages.MatchObject{Id: "testmatch"}
+	for _, team := range []string{"red", "blue"} {
+		roster := &om_messages.Roster{Name: team}
+		for i := 0; i < n/2; i++ {
+			roster.Players = append(roster.Players, &om_messages.Player{
+				Id:         fmt.Sprintf("%s-player-%08d", team, i),
+				Properties: fmt.Sprintf(`{"mmr.rating": %d, "region.europe-west1": 1, "mode.ctf": 1}`, 1000+i),
+				Pool:       "defaultPool",
+				Attributes: []*om_messages.Player_Attribute{
+					{Name: "mmr.rating", Value: int64(1000 + i)},
+					{Name: "region.europe-west1", Value: int64(40 + i%20)},
+				},
+			})
+		}
+		mo.Rosters = append(mo.Rosters, roster)
+	}
+	jsonMsg, err := (&jsonpb.Marshaler{}).MarshalToString(mo)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return gjson.Get(jsonMsg, "rosters").String()
+}
+
+func TestCompressRoundTrip(t *testing.T) {
+	rosters := testRosters(t, 10)
+	compressed, err := Compress(rosters)
+	if err != nil {
+		t.Fatalf("Compress failed: %v", err)
+	}
+	got, err := Decompress(compressed)
+	if err != nil {
+		t.Fatalf("Decompress failed: %v", err)
+	}
+	if got != rosters {
+		t.Errorf("Decompress(Compress(x)) = %q, want %q", got, rosters)
+	}
+
+	// Uncompressed values pass through unchanged.
+	if got, _ = Decompress(rosters); got != rosters {
+		t.Errorf("Decompress changed an uncompressed value")
+	}
+}
+
+// BenchmarkCompress reports the compressed size of a match's rosters as a
+// percentage of the uncompressed size.
+func BenchmarkCompress(b *testing.B) {
+	for _, players := range []int{2, 10, 100, 1000} {
+		rosters := testRosters(b, players)
+		b.Run(fmt.Sprintf("%dplayers", players), func(b *testing.B) {
+			var compressed string
+			var err error
+			b.SetBytes(int64(len(rosters)))
+			for i := 0; i < b.N; i++ {
+				compressed, err = Compress(rosters)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(100*float64(len(compressed))/float64(len(rosters)), "%size")
+		})
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package redispb
+
+import (
+	"fmt"
+	"strings"
+
+	om_messages "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/spf13/viper"
+)
+
+// Supported values for the 'statestorage.matchFormat' config key.
+const (
+	// FormatFields writes each MatchObject field to its own hash field, as
+	// JSON for the pools and rosters.  This is the default, and the only
+	// format MMFs that read and write the hash directly understand.
+	FormatFields = "fields"
+	// FormatJSON writes the whole MatchObject as one JSON document to
+	// MatchObjectField, which is easy to inspect with redis-cli.
+	FormatJSON = "json"
+	// FormatProto writes the whole MatchObject in the protobuf binary
+	// encoding to MatchObjectField, which is the most compact.
+	FormatProto = "proto"
+)
+
+// MatchObjectField is the hash field holding the whole MatchObject in the
+// FormatJSON and FormatProto formats.
+const MatchObjectField = "matchobject"
+
+// TypedPropertiesField is the hash field holding a MatchObject's
+// typed_properties, in the protobuf binary encoding, in every format.  It's
+// kept apart from the rest of the MatchObject because JSON can only encode an
+// Any whose type is linked into this binary, and Open Match passes typed
+// properties through without knowing their type.
+const TypedPropertiesField = "typedproperties"
+
+// matchFormat returns the format selected by 'statestorage.matchFormat'.  It
+// only affects how MatchObjects are written; they are read in whichever
+// format they were written in.
+func matchFormat(cfg *viper.Viper) (string, error) {
+	if cfg == nil {
+		return FormatFields, nil
+	}
+	switch format := cfg.GetString("statestorage.matchFormat"); format {
+	case "", FormatFields:
+		return FormatFields, nil
+	case FormatJSON, FormatProto:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown statestorage.matchFormat %q, want %v, %v or %v", format, FormatFields, FormatJSON, FormatProto)
+	}
+}
+
+// encodeMatchObject returns pb in format, which must be FormatJSON or
+// FormatProto.
+func encodeMatchObject(pb proto.Message, format string) (string, error) {
+	if format == FormatJSON {
+		return (&jsonpb.Marshaler{}).MarshalToString(pb)
+	}
+	encoded, err := proto.Marshal(pb)
+	return string(encoded), err
+}
+
+// DecodeMatchObject reads a MatchObject from the value of its
+// MatchObjectField, whichever of FormatJSON or FormatProto it was written in,
+// and whether or not it was compressed.
+func DecodeMatchObject(stored string, pb *om_messages.MatchObject) error {
+	stored, err := Decompress(stored)
+	if err != nil {
+		return err
+	}
+	// A JSON object always starts with '{', which can't start a MatchObject's
+	// binary encoding as it has no field 15.
+	if strings.HasPrefix(stored, "{") {
+		return jsonpb.UnmarshalString(stored, pb)
+	}
+	return proto.Unmarshal([]byte(stored), pb)
+}
+
+// splitTypedProperties returns pb without its typed properties, and the typed
+// properties in the protobuf binary encoding, or "" if it has none.  pb itself
+// is left unchanged.
+func splitTypedProperties(pb proto.Message) (proto.Message, string, error) {
+	mo, ok := pb.(*om_messages.MatchObject)
+	if !ok || mo.TypedProperties == nil {
+		return pb, "", nil
+	}
+	encoded, err := proto.Marshal(mo.TypedProperties)
+	if err != nil {
+		return nil, "", err
+	}
+	untyped := *mo
+	untyped.TypedProperties = nil
+	return &untyped, string(encoded), nil
+}
+
+// decodeTypedProperties sets pb's typed properties from the value of its
+// TypedPropertiesField, if there is one.
+func decodeTypedProperties(stored string, pb *om_messages.MatchObject) error {
+	if stored == "" {
+		return nil
+	}
+	typed := &any.Any{}
+	if err := proto.Unmarshal([]byte(stored), typed); err != nil {
+		return err
+	}
+	pb.TypedProperties = typed
+	return nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redispb
+
+import (
+	"context"
+	"testing"
+
+	om_messages "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/alicebob/miniredis"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/gomodule/redigo/redis"
+	"github.com/spf13/viper"
+)
+
+func TestMatchObjectFormats(t *testing.T) {
+	mo := &om_messages.MatchObject{
+		Id:           "testmatch",
+		Properties:   `{"mode": "ctf"}`,
+		Mmf:          "default",
+		QualityScore: 0.5,
+		Rosters:      []*om_messages.Roster{{Name: "red", Players: []*om_messages.Player{{Id: "p1"}}}},
+	}
+	for _, format := range []string{FormatJSON, FormatProto} {
+		cfg := viper.New()
+		cfg.Set("statestorage.matchFormat", format)
+		if got, err := matchFormat(cfg); err != nil || got != format {
+			t.Fatalf("matchFormat = %q, %v, want %q", got, err, format)
+		}
+
+		stored, err := encodeMatchObject(mo, format)
+		if err != nil {
+			t.Fatalf("%v: encodeMatchObject failed: %v", format, err)
+		}
+		// Reading must not depend on the configured format.
+		for _, s := range []string{stored, mustCompress(t, stored)} {
+			got := &om_messages.MatchObject{}
+			if err := DecodeMatchObject(s, got); err != nil {
+				t.Fatalf("%v: DecodeMatchObject failed: %v", format, err)
+			}
+			if !proto.Equal(got, mo) {
+				t.Errorf("%v: DecodeMatchObject = %v, want %v", format, got, mo)
+			}
+		}
+	}
+
+	cfg := viper.New()
+	cfg.Set("statestorage.matchFormat", "xml")
+	if _, err := matchFormat(cfg); err == nil {
+		t.Error("matchFormat accepted an unknown format")
+	}
+}
+
+func TestTypedPropertiesPassThrough(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) }}
+
+	// The type isn't linked into this binary, so only its encoding can be kept.
+	typed := &any.Any{TypeUrl: "type.googleapis.com/game.Profile", Value: []byte{0x08, 0x96, 0x01}}
+	for _, format := range []string{FormatFields, FormatJSON, FormatProto} {
+		cfg := viper.New()
+		cfg.Set("statestorage.matchFormat", format)
+		mo := &om_messages.MatchObject{Id: "profile-" + format, Mmf: "default", TypedProperties: typed}
+		if err := MarshalToRedis(context.Background(), mo, pool, cfg); err != nil {
+			t.Fatalf("%v: MarshalToRedis failed: %v", format, err)
+		}
+		if mo.TypedProperties != typed {
+			t.Errorf("%v: MarshalToRedis modified the MatchObject", format)
+		}
+
+		got := &om_messages.MatchObject{Id: mo.Id}
+		if err := UnmarshalFromRedis(context.Background(), pool, got); err != nil {
+			t.Fatalf("%v: UnmarshalFromRedis failed: %v", format, err)
+		}
+		if !proto.Equal(got.TypedProperties, typed) || got.Mmf != "default" {
+			t.Errorf("%v: UnmarshalFromRedis = %v, want typed properties %v", format, got, typed)
+		}
+	}
+}
+
+func mustCompress(t *testing.T, value string) string {
+	compressed, err := Compress(value)
+	if err != nil {
+		t.Fatalf("Compress failed: %v", err)
+	}
+	return compressed
+}
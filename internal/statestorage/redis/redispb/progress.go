@@ -0,0 +1,126 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redispb
+
+import (
+	"context"
+
+	om_messages "github.com/GoogleCloudPlatform/open-match/internal/pb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gomodule/redigo/redis"
+	log "github.com/sirupsen/logrus"
+)
+
+// progressBuffer is how many progress markers a subscriber holds before
+// newer ones are dropped.
+const progressBuffer = 16
+
+// ProgressChannel is the redis pubsub channel the progress markers of the
+// MMF run for the request with the given ID are published on.
+func ProgressChannel(id string) string {
+	return "progress." + id
+}
+
+// PublishProgress publishes an MMF's progress marker on the channel of the
+// request it belongs to.  Markers are only delivered to backends subscribed
+// at the time; nothing is stored.
+func PublishProgress(ctx context.Context, pool *redis.Pool, p *om_messages.MatchProgress) error {
+	msg, err := proto.Marshal(p)
+	if err != nil {
+		return err
+	}
+	redisConn, err := pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer redisConn.Close()
+	_, err = redisConn.Do("PUBLISH", ProgressChannel(p.Id), msg)
+	return err
+}
+
+// SubscribeProgress returns the progress markers published for the request
+// with the given ID, once the subscription is in place, so markers published
+// after it returns aren't missed.  The channel is closed when ctx is
+// cancelled or the subscription fails.  Markers are dropped rather than
+// blocking the subscriber if the reader falls behind.
+func SubscribeProgress(ctx context.Context, pool *redis.Pool, id string) (<-chan *om_messages.MatchProgress, error) {
+	redisConn, err := pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	psc := redis.PubSubConn{Conn: redisConn}
+	channel := ProgressChannel(id)
+	if err := psc.Subscribe(channel); err != nil {
+		psc.Close()
+		return nil, err
+	}
+	// Wait for the confirmation, so the caller can start the MMF knowing no
+	// marker will be published before the subscription exists.
+	if err, ok := psc.Receive().(error); ok {
+		psc.Close()
+		return nil, err
+	}
+
+	progress := make(chan *om_messages.MatchProgress, progressBuffer)
+	done := make(chan struct{})
+	go func() {
+		// Unblock Receive() when the context is cancelled.
+		select {
+		case <-ctx.Done():
+			psc.Unsubscribe(channel)
+		case <-done:
+		}
+	}()
+	go func() {
+		defer close(progress)
+		defer close(done)
+		defer psc.Close()
+		for {
+			// MMFs can run for a long time between markers, so wait without
+			// the read timeout.
+			switch v := psc.ReceiveWithTimeout(0).(type) {
+			case redis.Message:
+				p := &om_messages.MatchProgress{}
+				if err := proto.Unmarshal(v.Data, p); err != nil {
+					rpLog.WithFields(log.Fields{
+						"error":   err.Error(),
+						"channel": channel,
+					}).Warn("Ignoring malformed progress marker")
+					continue
+				}
+				select {
+				case progress <- p:
+				default:
+					rpLog.WithFields(log.Fields{"channel": channel}).Debug("Dropping progress marker, reader is behind")
+				}
+			case redis.Subscription:
+				if v.Count == 0 {
+					return
+				}
+			case error:
+				if ctx.Err() == nil {
+					rpLog.WithFields(log.Fields{
+						"error":   v.Error(),
+						"channel": channel,
+					}).Error("Progress subscription error")
+				}
+				return
+			}
+		}
+	}()
+	return progress, nil
+}
@@ -25,6 +25,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,6 +34,7 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/gomodule/redigo/redis"
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 	"github.com/tidwall/gjson"
 )
 
@@ -47,8 +49,29 @@ var (
 )
 
 // MarshalToRedis marshals a protobuf message to a redis hash.
-// The protobuf message in question must have an 'id' field.
-func MarshalToRedis(ctx context.Context, pb proto.Message, pool *redis.Pool) (err error) {
+// The protobuf message in question must have an 'id' field.  If
+// 'redis.compression.enabled' is set, large fields are gzipped.  The hash
+// layout is selected by 'statestorage.matchFormat'; see FormatFields.
+func MarshalToRedis(ctx context.Context, pb proto.Message, pool *redis.Pool, cfg *viper.Viper) (err error) {
+
+	format, err := matchFormat(cfg)
+	if err != nil {
+		rpLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("invalid match format")
+		return
+	}
+
+	// Typed properties are written on their own; see TypedPropertiesField.
+	pb, typed, err := splitTypedProperties(pb)
+	if err != nil {
+		rpLog.WithFields(log.Fields{
+			"error":     err.Error(),
+			"component": "statestorage",
+		}).Error("failure marshaling typed properties")
+		return
+	}
 
 	// We want to serialize to redis as JSON, not the typical protobuf string
 	// serializer, so start by marshalling to json.
@@ -85,7 +108,7 @@ func MarshalToRedis(ctx context.Context, pb proto.Message, pool *redis.Pool) (er
 	})
 
 	// Get the Redis connection.
-	redisConn, err := pool.GetContext(context.Background())
+	redisConn, err := pool.GetContext(ctx)
 	defer redisConn.Close()
 	if err != nil {
 		rpLog.WithFields(log.Fields{
@@ -95,6 +118,40 @@ func MarshalToRedis(ctx context.Context, pb proto.Message, pool *redis.Pool) (er
 		return
 	}
 	redisConn.Send("MULTI")
+	if typed != "" {
+		redisConn.Send(cmd, key, TypedPropertiesField, typed)
+	} else {
+		redisConn.Send("HDEL", key, TypedPropertiesField)
+	}
+
+	// Write the whole message to a single field.
+	if format != FormatFields {
+		var stored string
+		stored, err = encodeMatchObject(pb, format)
+		if err != nil {
+			resultLog.WithFields(log.Fields{
+				"error":  err.Error(),
+				"format": format,
+			}).Error("failure encoding protobuf message")
+			return
+		}
+		if shouldCompress(cfg, stored) {
+			stored, err = Compress(stored)
+			if err != nil {
+				resultLog.WithFields(log.Fields{
+					"error": err.Error(),
+					"field": MatchObjectField,
+				}).Error("failure compressing field")
+				return
+			}
+		}
+		redisConn.Send(cmd, key, MatchObjectField, stored)
+		_, err = redisConn.Do("EXEC")
+		return
+	}
+
+	// A message written whole earlier would take precedence over the fields.
+	redisConn.Send("HDEL", key, MatchObjectField)
 
 	// Write all non-id fields from the protobuf message to state storage.
 	// Use reflection to get the field names from the protobuf message.
@@ -104,10 +161,21 @@ func MarshalToRedis(ctx context.Context, pb proto.Message, pool *redis.Pool) (er
 		//  something like parseTag() in src/encoding/json/tags.go
 		//field := strings.ToLower(pbInfo.Type().Field(i).Tag.Get("json"))
 		field := strings.ToLower(pbInfo.Type().Field(i).Name)
-		value := gjson.Get(jsonMsg, field)
-		if field != "id" {
+		value := gjson.Get(jsonMsg, jsonName(pbInfo.Type().Field(i)))
+		if field != "id" && field != TypedPropertiesField {
 			// This isn't the ID field, so write it to the redis hash.
-			redisConn.Send(cmd, key, field, value)
+			stored := value.String()
+			if shouldCompress(cfg, stored) {
+				stored, err = Compress(stored)
+				if err != nil {
+					resultLog.WithFields(log.Fields{
+						"error": err.Error(),
+						"field": field,
+					}).Error("failure compressing field")
+					return
+				}
+			}
+			redisConn.Send(cmd, key, field, stored)
 			if err != nil {
 				resultLog.WithFields(log.Fields{
 					"error":     err.Error(),
@@ -134,7 +202,7 @@ func MarshalToRedis(ctx context.Context, pb proto.Message, pool *redis.Pool) (er
 func UnmarshalFromRedis(ctx context.Context, pool *redis.Pool, pb *om_messages.MatchObject) error {
 
 	// Get the Redis connection.
-	redisConn, err := pool.GetContext(context.Background())
+	redisConn, err := pool.GetContext(ctx)
 	defer redisConn.Close()
 	if err != nil {
 		rpLog.WithFields(log.Fields{
@@ -153,28 +221,111 @@ func UnmarshalFromRedis(ctx context.Context, pool *redis.Pool, pb *om_messages.M
 		"key":       key,
 	})
 	pbMap, err := redis.StringMap(redisConn.Do(cmd, key))
+	if err != nil {
+		resultLog.WithFields(log.Fields{"error": err.Error()}).Error("State storage error")
+		return err
+	}
+	if len(pbMap) == 0 {
+		return errors.New("no matchobject in state storage at this key")
+	}
+	if stored, ok := pbMap[MatchObjectField]; ok {
+		// Written whole by MarshalToRedis, in FormatJSON or FormatProto.
+		err = DecodeMatchObject(stored, pb)
+		if err != nil {
+			resultLog.WithFields(log.Fields{"error": err.Error()}).Error("failure decoding matchobject")
+			return err
+		}
+		pb.Id = key
+		return decodeTypedProperties(pbMap[TypedPropertiesField], pb)
+	}
+	for field, value := range pbMap {
+		pbMap[field], err = Decompress(value)
+		if err != nil {
+			resultLog.WithFields(log.Fields{
+				"error": err.Error(),
+				"field": field,
+			}).Error("failure decompressing field")
+			return err
+		}
+	}
 	pb.Error = pbMap["error"]
 	pb.Properties = pbMap["properties"]
-	poolsJSON := fmt.Sprintf("{\"pools\": %v}", pbMap["pools"])
-	err = jsonpb.UnmarshalString(poolsJSON, pb)
-	if err != nil {
-		resultLog.Error("failure on pool")
-		resultLog.Error(pbMap["pools"])
-		resultLog.Error(err)
+	pb.Mmf = pbMap["mmf"]
+	if pbMap["qualityscore"] != "" {
+		pb.QualityScore, err = strconv.ParseFloat(pbMap["qualityscore"], 64)
+		if err != nil {
+			resultLog.WithFields(log.Fields{"error": err.Error()}).Error("failure on quality score")
+		}
+	}
+	if pbMap["requestedplayers"] != "" {
+		pb.RequestedPlayers, err = strconv.ParseInt(pbMap["requestedplayers"], 10, 64)
+		if err != nil {
+			resultLog.WithFields(log.Fields{"error": err.Error()}).Error("failure on requested players")
+		}
+	}
+	if pbMap["priority"] != "" {
+		pb.Priority, err = strconv.ParseInt(pbMap["priority"], 10, 64)
+		if err != nil {
+			resultLog.WithFields(log.Fields{"error": err.Error()}).Error("failure on priority")
+		}
+	}
+	if pbMap["nomatchreason"] != "" {
+		// Written by name by MarshalToRedis, but MMFs writing to state
+		// storage directly may use the number.
+		if reason, ok := om_messages.NoMatchReason_value[pbMap["nomatchreason"]]; ok {
+			pb.NoMatchReason = om_messages.NoMatchReason(reason)
+		} else if reason, perr := strconv.ParseInt(pbMap["nomatchreason"], 10, 32); perr == nil {
+			pb.NoMatchReason = om_messages.NoMatchReason(reason)
+		} else {
+			resultLog.WithFields(log.Fields{"value": pbMap["nomatchreason"]}).Error("failure on no match reason")
+		}
 	}
 
-	rostersJSON := fmt.Sprintf("{\"rosters\": %v}", pbMap["rosters"])
-	err = jsonpb.UnmarshalString(rostersJSON, pb)
-	if err != nil {
-		resultLog.Error("failure on roster")
-		resultLog.Error(pbMap["rosters"])
-		log.Error(err)
+	if err = decodeTypedProperties(pbMap[TypedPropertiesField], pb); err != nil {
+		resultLog.WithFields(log.Fields{"error": err.Error()}).Error("failure on typed properties")
+	}
+
+	// Error results written by the mmforc have no pools or rosters.
+	if pbMap["pools"] != "" {
+		poolsJSON := fmt.Sprintf("{\"pools\": %v}", pbMap["pools"])
+		err = jsonpb.UnmarshalString(poolsJSON, pb)
+		if err != nil {
+			resultLog.Error("failure on pool")
+			resultLog.Error(pbMap["pools"])
+			resultLog.Error(err)
+		}
+	}
+
+	if pbMap["rosters"] != "" {
+		rostersJSON := fmt.Sprintf("{\"rosters\": %v}", pbMap["rosters"])
+		err = jsonpb.UnmarshalString(rostersJSON, pb)
+		if err != nil {
+			resultLog.Error("failure on roster")
+			resultLog.Error(pbMap["rosters"])
+			log.Error(err)
+		}
 	}
 	rpLog.Debug("Final pb:")
 	rpLog.Debug(pb)
 	return err
 }
 
+// jsonName returns the key jsonpb uses for a generated protobuf struct field,
+// which is the 'json=' value of the field's protobuf tag for multi-word field
+// names (e.g. 'qualityScore') and the plain field name otherwise.
+func jsonName(f reflect.StructField) string {
+	name := strings.ToLower(f.Name)
+	for _, part := range strings.Split(f.Tag.Get("protobuf"), ",") {
+		if strings.HasPrefix(part, "name=") {
+			name = strings.TrimPrefix(part, "name=")
+		}
+		if strings.HasPrefix(part, "json=") {
+			return strings.TrimPrefix(part, "json=")
+		}
+	}
+	return name
+}
+
 // Watcher makes a channel and returns it immediately.  It also launches an
 // asynchronous goroutine that watches a redis key and returns updates to
 // that key on the channel.
@@ -215,3 +366,17 @@ func Watcher(ctx context.Context, pool *redis.Pool, pb om_messages.MatchObject)
 
 	return watchChan
 }
+
+// TruncateError bounds the length of an error string before it is written to
+// state storage, so a chatty MMF can't fill redis with its error output.  A
+// maxLen of 0 or less disables truncation.
+func TruncateError(e string, maxLen int) string {
+	const marker = "... (truncated)"
+	if maxLen <= 0 || len(e) <= maxLen {
+		return e
+	}
+	if maxLen <= len(marker) {
+		return e[:maxLen]
+	}
+	return e[:maxLen-len(marker)] + marker
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redisHelpers
+
+import (
+	"context"
+
+	"github.com/gomodule/redigo/redis"
+	log "github.com/sirupsen/logrus"
+)
+
+// Scanner walks the redis keyspace in batches using SCAN, so enumerating keys
+// never blocks redis the way KEYS does.  A scan can be stopped and resumed
+// later, e.g. across paged gRPC calls, by saving Cursor() and passing it to
+// a new Scanner.
+//
+// Usage:
+//
+//	s := NewScanner(redisConn, "", "", 100)
+//	for s.Next(ctx) {
+//	    for _, key := range s.Keys() { ... }
+//	}
+//	if err := s.Err(); err != nil { ... }
+type Scanner struct {
+	conn   redis.Conn
+	cursor string
+	match  string
+	count  int
+	keys   []string
+	err    error
+	done   bool
+}
+
+// NewScanner returns a Scanner that starts from the provided cursor (empty to
+// start a new scan) and returns keys matching the provided glob pattern (empty
+// for all keys).  count is passed to SCAN as a hint of how many keys to
+// return in each batch.
+func NewScanner(redisConn redis.Conn, cursor string, match string, count int) *Scanner {
+	if cursor == "" {
+		cursor = "0"
+	}
+	return &Scanner{conn: redisConn, cursor: cursor, match: match, count: count}
+}
+
+// Next fetches the next batch of keys, which may be empty.  It returns false
+// once the scan is complete, the context is done, or redis returns an error.
+func (s *Scanner) Next(ctx context.Context) bool {
+	if s.done || s.err != nil {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		s.err = err
+		return false
+	}
+
+	args := redis.Args{s.cursor}
+	if s.match != "" {
+		args = args.Add("MATCH", s.match)
+	}
+	if s.count > 0 {
+		args = args.Add("COUNT", s.count)
+	}
+	reply, err := redis.Values(s.conn.Do("SCAN", args...))
+	if err == nil {
+		_, err = redis.Scan(reply, &s.cursor, &s.keys)
+	}
+	if err != nil {
+		rhLog.WithFields(log.Fields{
+			"error":  err.Error(),
+			"cursor": s.cursor,
+		}).Error("state storage error")
+		s.err = err
+		return false
+	}
+
+	// A cursor of '0' means this is the last batch.
+	if s.cursor == "0" {
+		s.done = true
+	}
+	return true
+}
+
+// Keys returns the batch of keys fetched by the last call to Next.
+func (s *Scanner) Keys() []string {
+	return s.keys
+}
+
+// Cursor returns the cursor to resume the scan from, or an empty string once
+// the scan is complete.
+func (s *Scanner) Cursor() string {
+	if s.done {
+		return ""
+	}
+	return s.cursor
+}
+
+// Err returns the error, if any, that stopped the scan.
+func (s *Scanner) Err() error {
+	return s.err
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redisHelpers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/alicebob/miniredis"
+	"github.com/gomodule/redigo/redis"
+)
+
+func newTestConn(t *testing.T) (*miniredis.Miniredis, redis.Conn) {
+	t.Helper()
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	conn, err := redis.Dial("tcp", mr.Addr())
+	if err != nil {
+		mr.Close()
+		t.Fatalf("failed to connect to miniredis: %v", err)
+	}
+	return mr, conn
+}
+
+func TestScannerCursorContinuation(t *testing.T) {
+	mr, conn := newTestConn(t)
+	defer mr.Close()
+	defer conn.Close()
+
+	want := []string{}
+	for i := 0; i < 25; i++ {
+		key := fmt.Sprintf("player%02d", i)
+		mr.Set(key, "x")
+		want = append(want, key)
+	}
+	mr.Set("other", "x")
+
+	// Read one batch, then resume from its cursor with a new Scanner the way
+	// a paged gRPC call would.
+	got := []string{}
+	s := NewScanner(conn, "", "player*", 10)
+	if !s.Next(context.Background()) {
+		t.Fatalf("first batch failed: %v", s.Err())
+	}
+	got = append(got, s.Keys()...)
+
+	if cursor := s.Cursor(); cursor != "" {
+		s = NewScanner(conn, cursor, "player*", 10)
+		for s.Next(context.Background()) {
+			got = append(got, s.Keys()...)
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	if s.Cursor() != "" {
+		t.Errorf("Cursor() = %q after the scan completed, want empty", s.Cursor())
+	}
+
+	sort.Strings(got)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("scanned keys = %v, want %v", got, want)
+	}
+}
+
+func TestScannerContextCancelled(t *testing.T) {
+	mr, conn := newTestConn(t)
+	defer mr.Close()
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := NewScanner(conn, "", "", 10)
+	if s.Next(ctx) {
+		t.Error("Next() returned a batch after the context was cancelled")
+	}
+	if s.Err() != context.Canceled {
+		t.Errorf("Err() = %v, want %v", s.Err(), context.Canceled)
+	}
+}
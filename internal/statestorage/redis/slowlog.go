@@ -0,0 +1,120 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redisHelpers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/open-match/internal/metrics"
+	"github.com/gomodule/redigo/redis"
+	log "github.com/sirupsen/logrus"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	// StateStorageSlowOperations counts redis commands that took longer than
+	// 'redis.slowOperationThreshold' milliseconds.
+	StateStorageSlowOperations = stats.Int64("statestorage/slow_operations_total", "Number of redis commands slower than the configured threshold", "1")
+
+	// KeyCommand is used to tag a measure with the redis command that was run.
+	KeyCommand, _ = tag.NewKey("command")
+
+	// SlowOperationCountView is the OpenCensus view for the
+	// StateStorageSlowOperations measure.
+	SlowOperationCountView = &view.View{
+		Name:        "statestorage/slow_operations",
+		Measure:     StateStorageSlowOperations,
+		Description: "The number of redis commands slower than the configured threshold",
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{KeyCommand},
+	}
+)
+
+func init() {
+	metrics.AddViews(SlowOperationCountView)
+}
+
+// slowConn times every command run with Do on the wrapped connection, and
+// logs and counts those that take longer than threshold.  Pipelined commands
+// are only timed as a whole, by the Do("EXEC") or Do("") that finishes them.
+type slowConn struct {
+	redis.Conn
+	threshold time.Duration
+}
+
+// withSlowLog wraps redisConn so slow commands are reported.
+func withSlowLog(redisConn redis.Conn, threshold time.Duration) redis.Conn {
+	return &slowConn{Conn: redisConn, threshold: threshold}
+}
+
+func (c *slowConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	start := time.Now()
+	reply, err := c.Conn.Do(cmd, args...)
+	c.observe(cmd, args, time.Since(start))
+	return reply, err
+}
+
+// DoWithTimeout is needed for redis.DoWithTimeout to work through the wrapper.
+func (c *slowConn) DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (interface{}, error) {
+	cwt, ok := c.Conn.(redis.ConnWithTimeout)
+	if !ok {
+		return nil, errors.New("redis: connection does not support ConnWithTimeout")
+	}
+	start := time.Now()
+	reply, err := cwt.DoWithTimeout(timeout, cmd, args...)
+	c.observe(cmd, args, time.Since(start))
+	return reply, err
+}
+
+// ReceiveWithTimeout is needed for redis.ReceiveWithTimeout (e.g. pubsub) to
+// work through the wrapper.
+func (c *slowConn) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	cwt, ok := c.Conn.(redis.ConnWithTimeout)
+	if !ok {
+		return nil, errors.New("redis: connection does not support ConnWithTimeout")
+	}
+	return cwt.ReceiveWithTimeout(timeout)
+}
+
+// observe reports a command that took longer than the threshold.
+func (c *slowConn) observe(cmd string, args []interface{}, elapsed time.Duration) {
+	if elapsed < c.threshold {
+		return
+	}
+	// Do("") only flushes the pipeline and reads pending replies.
+	if cmd == "" {
+		cmd = "PIPELINE"
+	}
+	cmd = strings.ToUpper(cmd)
+
+	fields := log.Fields{
+		"command":   cmd,
+		"elapsedMs": elapsed.Nanoseconds() / int64(time.Millisecond),
+	}
+	if len(args) > 0 {
+		fields["key"] = args[0]
+	}
+	rhLog.WithFields(fields).Warn("Slow state storage operation")
+
+	ctx, _ := tag.New(context.Background(), tag.Insert(KeyCommand, cmd))
+	metrics.Record(ctx, StateStorageSlowOperations.M(1))
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testutil provides helpers for exercising Open Match APIs in-process
+// from tests, without standing up a real Redis instance or network listener.
+package testutil
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/open-match/cmd/frontendapi/apisrv"
+	frontend "github.com/GoogleCloudPlatform/open-match/cmd/frontendapi/proto"
+	"github.com/alicebob/miniredis"
+	"github.com/gomodule/redigo/redis"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// bufSize is the size of the in-memory buffer backing the bufconn listener.
+const bufSize = 1024 * 1024
+
+// FrontendHarness holds everything started by NewFrontendAPI. The
+// Miniredis and Pool fields are exposed so tests can seed or inspect state
+// storage directly.
+type FrontendHarness struct {
+	Client    frontend.APIClient
+	Cfg       *viper.Viper
+	Pool      *redis.Pool
+	Miniredis *miniredis.Miniredis
+
+	srv  *apisrv.FrontendAPI
+	conn *grpc.ClientConn
+}
+
+// NewFrontendAPI starts a FrontendAPI backed by miniredis and served on a
+// bufconn listener, and returns a harness holding a connected client.  The
+// returned harness should be closed by the caller when the test is finished.
+// Pass a nil cfg to use a minimal default config.
+func NewFrontendAPI(t *testing.T, cfg *viper.Viper) *FrontendHarness {
+	t.Helper()
+
+	if cfg == nil {
+		cfg = viper.New()
+	}
+	if !cfg.IsSet("jsonkeys.connstring") {
+		cfg.Set("jsonkeys.connstring", "connstring")
+	}
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+
+	pool := &redis.Pool{
+		MaxIdle:     3,
+		IdleTimeout: 0,
+		Dial:        func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) },
+	}
+
+	srv := apisrv.New(cfg, pool)
+	ln := bufconn.Listen(bufSize)
+	srv.Serve(ln)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithDialer(func(string, time.Duration) (net.Conn, error) { return ln.Dial() }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		srv.Stop()
+		pool.Close()
+		mr.Close()
+		t.Fatalf("failed to dial bufconn: %v", err)
+	}
+
+	return &FrontendHarness{
+		Client:    frontend.NewAPIClient(conn),
+		Cfg:       cfg,
+		Pool:      pool,
+		Miniredis: mr,
+		srv:       srv,
+		conn:      conn,
+	}
+}
+
+// Close tears down the client connection, the gRPC server, the redis
+// connection pool and miniredis, in that order.
+func (h *FrontendHarness) Close() {
+	h.conn.Close()
+	h.srv.Stop()
+	h.Pool.Close()
+	h.Miniredis.Close()
+}